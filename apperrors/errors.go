@@ -0,0 +1,107 @@
+package apperrors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Categorias de erro de domínio. Use errors.Is(err, apperrors.ErrNotFound)
+// para classificar qualquer erro retornado pelos services
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrValidation       = errors.New("validation")
+	ErrConflict         = errors.New("conflict")
+	ErrForbidden        = errors.New("forbidden")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+)
+
+// FieldError detalha um campo inválido. Limit acompanha os códigos que
+// dependem de um limite, como CodeTooShort e CodeTooLong
+type FieldError struct {
+	Field string
+	Code  string
+	Limit int
+}
+
+// Message retorna a mensagem do campo no idioma pedido
+func (f FieldError) Message(lang string) string {
+	return fieldMessage(f, lang)
+}
+
+// Error é o erro de domínio retornado pelos services. Code é estável e pode
+// ser usado por clientes; a mensagem vem do catálogo em messages.go
+type Error struct {
+	kind   error
+	Code   string
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message(DefaultLanguage)
+}
+
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// Message retorna a mensagem no idioma pedido. Erros de validação com campos
+// listam a mensagem de cada campo
+func (e *Error) Message(lang string) string {
+	if len(e.Fields) == 0 {
+		return message(e.Code, lang)
+	}
+
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message(lang)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func NotFound(code string) *Error {
+	return &Error{kind: ErrNotFound, Code: code}
+}
+
+func Conflict(code string) *Error {
+	return &Error{kind: ErrConflict, Code: code}
+}
+
+func Forbidden(code string) *Error {
+	return &Error{kind: ErrForbidden, Code: code}
+}
+
+func MethodNotAllowed(code string) *Error {
+	return &Error{kind: ErrMethodNotAllowed, Code: code}
+}
+
+func Validation(code string, fields ...FieldError) *Error {
+	return &Error{kind: ErrValidation, Code: code, Fields: fields}
+}
+
+// Fields acumula erros de campo durante uma validação
+type Fields []FieldError
+
+func (f *Fields) Add(field, code string) {
+	*f = append(*f, FieldError{Field: field, Code: code})
+}
+
+func (f *Fields) AddLimit(field, code string, limit int) {
+	*f = append(*f, FieldError{Field: field, Code: code, Limit: limit})
+}
+
+// Err retorna um erro de validação com os campos acumulados, ou nil se não houver
+func (f Fields) Err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return Validation(CodeValidationFailed, f...)
+}
+
+// As extrai o *Error de err, se houver
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
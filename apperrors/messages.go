@@ -0,0 +1,233 @@
+package apperrors
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	LanguagePortuguese = "pt-BR"
+	LanguageEnglish    = "en"
+
+	DefaultLanguage = LanguagePortuguese
+)
+
+// Códigos de erro dos services
+const (
+	CodeInvalidID         = "invalid_id"
+	CodeInvalidAuthorID   = "invalid_author_id"
+	CodeInvalidBookID     = "invalid_book_id"
+	CodeInvalidCategoryID = "invalid_category_id"
+	CodeInvalidTagID      = "invalid_tag_id"
+	CodeInvalidISBN       = "invalid_isbn"
+	CodeInvalidOrder      = "invalid_order"
+	CodeInvalidCursor     = "invalid_cursor"
+	CodeInvalidJSON       = "invalid_json"
+	CodeValidationFailed  = "validation_failed"
+	CodeRouteNotFound     = "route_not_found"
+	CodeMethodNotAllowed  = "method_not_allowed"
+
+	CodeAuthorNotFound     = "author_not_found"
+	CodeAuthorNameTaken    = "author_name_taken"
+	CodeAuthorHasBooks     = "author_has_books"
+	CodeAuthorsNotFound    = "authors_not_found"
+	CodeBookNotFound       = "book_not_found"
+	CodeBookISBNTaken      = "book_isbn_taken"
+	CodeBookNeedsAuthor    = "book_needs_author"
+	CodeCategoryNotFound   = "category_not_found"
+	CodeCategoryNameTaken  = "category_name_taken"
+	CodeCategoryHasBooks   = "category_has_books"
+	CodeCategoriesNotFound = "categories_not_found"
+	CodeQuoteNotFound      = "quote_not_found"
+	CodeNoQuotes           = "no_quotes_available"
+	CodeTagNotFound        = "tag_not_found"
+	CodeTagNameTaken       = "tag_name_taken"
+	CodeQuoteTagNotFound   = "quote_tag_not_found"
+)
+
+// Códigos de erro de campo
+const (
+	CodeRequired   = "required"
+	CodeTooShort   = "too_short"
+	CodeTooLong    = "too_long"
+	CodeInvalid    = "invalid"
+	CodeNegative   = "negative"
+	CodeOutOfRange = "out_of_range"
+)
+
+var messages = map[string]map[string]string{
+	LanguagePortuguese: {
+		CodeInvalidID:         "ID inválido",
+		CodeInvalidAuthorID:   "ID de autor inválido",
+		CodeInvalidBookID:     "ID de livro inválido",
+		CodeInvalidCategoryID: "ID de categoria inválido",
+		CodeInvalidTagID:      "ID de tag inválido",
+		CodeInvalidISBN:       "ISBN inválido",
+		CodeInvalidOrder:      "ordenação inválida",
+		CodeInvalidCursor:     "cursor inválido",
+		CodeInvalidJSON:       "JSON inválido",
+		CodeValidationFailed:  "dados inválidos",
+		CodeRouteNotFound:     "rota não encontrada",
+		CodeMethodNotAllowed:  "método não permitido nesta rota",
+
+		CodeAuthorNotFound:     "autor não encontrado",
+		CodeAuthorNameTaken:    "já existe um autor com este nome",
+		CodeAuthorHasBooks:     "não é possível deletar autor com livros associados",
+		CodeAuthorsNotFound:    "um ou mais autores não encontrados",
+		CodeBookNotFound:       "livro não encontrado",
+		CodeBookISBNTaken:      "já existe um livro com este ISBN",
+		CodeBookNeedsAuthor:    "livro deve ter pelo menos um autor",
+		CodeCategoryNotFound:   "categoria não encontrada",
+		CodeCategoryNameTaken:  "já existe uma categoria com este nome",
+		CodeCategoryHasBooks:   "não é possível deletar categoria com livros associados",
+		CodeCategoriesNotFound: "uma ou mais categorias não encontradas",
+		CodeQuoteNotFound:      "citação não encontrada",
+		CodeNoQuotes:           "nenhuma citação disponível",
+		CodeTagNotFound:        "tag não encontrada",
+		CodeTagNameTaken:       "já existe uma tag com este nome",
+		CodeQuoteTagNotFound:   "a citação não tem esta tag",
+	},
+	LanguageEnglish: {
+		CodeInvalidID:         "invalid ID",
+		CodeInvalidAuthorID:   "invalid author ID",
+		CodeInvalidBookID:     "invalid book ID",
+		CodeInvalidCategoryID: "invalid category ID",
+		CodeInvalidTagID:      "invalid tag ID",
+		CodeInvalidISBN:       "invalid ISBN",
+		CodeInvalidOrder:      "invalid ordering",
+		CodeInvalidCursor:     "invalid cursor",
+		CodeInvalidJSON:       "invalid JSON",
+		CodeValidationFailed:  "invalid data",
+		CodeRouteNotFound:     "route not found",
+		CodeMethodNotAllowed:  "method not allowed for this route",
+
+		CodeAuthorNotFound:     "author not found",
+		CodeAuthorNameTaken:    "an author with this name already exists",
+		CodeAuthorHasBooks:     "cannot delete an author that has books",
+		CodeAuthorsNotFound:    "one or more authors not found",
+		CodeBookNotFound:       "book not found",
+		CodeBookISBNTaken:      "a book with this ISBN already exists",
+		CodeBookNeedsAuthor:    "a book must have at least one author",
+		CodeCategoryNotFound:   "category not found",
+		CodeCategoryNameTaken:  "a category with this name already exists",
+		CodeCategoryHasBooks:   "cannot delete a category that has books",
+		CodeCategoriesNotFound: "one or more categories not found",
+		CodeQuoteNotFound:      "quote not found",
+		CodeNoQuotes:           "no quotes available",
+		CodeTagNotFound:        "tag not found",
+		CodeTagNameTaken:       "a tag with this name already exists",
+		CodeQuoteTagNotFound:   "the quote does not have this tag",
+	},
+}
+
+var fieldMessages = map[string]map[string]string{
+	LanguagePortuguese: {
+		CodeRequired:   "campo %s é obrigatório",
+		CodeTooShort:   "campo %s deve ter pelo menos %d caracteres",
+		CodeTooLong:    "campo %s deve ter no máximo %d caracteres",
+		CodeInvalid:    "campo %s inválido",
+		CodeNegative:   "campo %s não pode ser negativo",
+		CodeOutOfRange: "campo %s fora do intervalo permitido",
+	},
+	LanguageEnglish: {
+		CodeRequired:   "field %s is required",
+		CodeTooShort:   "field %s must have at least %d characters",
+		CodeTooLong:    "field %s must have at most %d characters",
+		CodeInvalid:    "field %s is invalid",
+		CodeNegative:   "field %s cannot be negative",
+		CodeOutOfRange: "field %s is out of range",
+	},
+}
+
+var fieldLabels = map[string]map[string]string{
+	LanguagePortuguese: {
+		"name":           "nome",
+		"title":          "título",
+		"published_year": "ano de publicação",
+		"pages":          "páginas",
+		"isbn":           "ISBN",
+		"book_id":        "livro",
+		"text":           "texto",
+		"kind":           "tipo",
+		"page":           "página",
+		"location_start": "posição inicial",
+		"location_end":   "posição final",
+		"author_id":      "autor",
+		"category_id":    "categoria",
+		"tag_id":         "tag",
+		"created_from":   "data inicial",
+		"created_to":     "data final",
+		"min_length":     "tamanho mínimo",
+		"max_length":     "tamanho máximo",
+		"published_from": "ano de publicação inicial",
+		"published_to":   "ano de publicação final",
+		"rating":         "avaliação",
+		"min_rating":     "avaliação mínima",
+		"favorite":       "favorita",
+		"mode":           "modo",
+		"count":          "quantidade",
+		"limit":          "limite",
+		"offset":         "deslocamento",
+	},
+	LanguageEnglish: {
+		"published_year": "published year",
+		"book_id":        "book",
+		"location_start": "start location",
+		"location_end":   "end location",
+		"author_id":      "author",
+		"category_id":    "category",
+		"tag_id":         "tag",
+		"created_from":   "start date",
+		"created_to":     "end date",
+		"min_length":     "minimum length",
+		"max_length":     "maximum length",
+		"published_from": "first publication year",
+		"published_to":   "last publication year",
+		"min_rating":     "minimum rating",
+	},
+}
+
+// Language escolhe o idioma suportado mais próximo de um Accept-Language
+func Language(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, "pt"):
+			return LanguagePortuguese
+		case strings.HasPrefix(tag, "en"):
+			return LanguageEnglish
+		}
+	}
+	return DefaultLanguage
+}
+
+func message(code, lang string) string {
+	if text, ok := messages[lang][code]; ok {
+		return text
+	}
+	if text, ok := messages[DefaultLanguage][code]; ok {
+		return text
+	}
+	return code
+}
+
+func fieldMessage(field FieldError, lang string) string {
+	format, ok := fieldMessages[lang][field.Code]
+	if !ok {
+		lang = DefaultLanguage
+		format, ok = fieldMessages[lang][field.Code]
+	}
+	if !ok {
+		return field.Field + ": " + field.Code
+	}
+
+	label := field.Field
+	if translated, ok := fieldLabels[lang][field.Field]; ok {
+		label = translated
+	}
+
+	if field.Code == CodeTooShort || field.Code == CodeTooLong {
+		return fmt.Sprintf(format, label, field.Limit)
+	}
+	return fmt.Sprintf(format, label)
+}
@@ -0,0 +1,435 @@
+package backup
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"quote-api/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvFile descreve o arquivo CSV de um tipo de registro. Listas de IDs vão em
+// uma coluna só, separadas por ";", e valores ausentes ficam vazios
+type csvFile struct {
+	name    string
+	kind    string
+	columns []string
+}
+
+// csvFiles segue a ordem dos tipos de registro, que é a ordem de importação
+var csvFiles = []csvFile{
+	{"categories.csv", typeCategory, []string{"id", "name", "created_at", "updated_at"}},
+	{"authors.csv", typeAuthor, []string{"id", "name", "created_at", "updated_at"}},
+	{"tags.csv", typeTag, []string{"id", "name", "created_at", "updated_at"}},
+	{"books.csv", typeBook, []string{"id", "title", "isbn", "published_year", "publisher", "pages",
+		"author_ids", "category_ids", "created_at", "updated_at"}},
+	{"quotes.csv", typeQuote, []string{"id", "book_id", "kind", "text", "page", "location_start", "location_end",
+		"favorite", "rating", "tag_ids", "created_at", "updated_at"}},
+	{"annotations.csv", typeAnnotation, []string{"id", "quote_id", "text", "location", "created_at", "updated_at"}},
+}
+
+// csvWriter grava cada tipo de registro no seu arquivo. Os arquivos são
+// escritos com a extensão .tmp e só substituem os anteriores em commit, todos
+// ou nenhum
+type csvWriter struct {
+	dir     string
+	files   []*os.File
+	writers []*csv.Writer
+}
+
+func newCSVWriter(dir string) (*csvWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	w := &csvWriter{dir: dir}
+	for _, spec := range csvFiles {
+		file, err := os.Create(filepath.Join(dir, spec.name+".tmp"))
+		if err != nil {
+			w.abort()
+			return nil, err
+		}
+		w.files = append(w.files, file)
+
+		writer := csv.NewWriter(file)
+		w.writers = append(w.writers, writer)
+		if err := writer.Write(spec.columns); err != nil {
+			w.abort()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (w *csvWriter) write(record interface{}) error {
+	var index int
+	var row []string
+
+	switch r := record.(type) {
+	case categoryRecord:
+		index, row = 0, []string{strconv.Itoa(r.ID), r.Name, formatTime(r.CreatedAt), formatTime(r.UpdatedAt)}
+	case authorRecord:
+		index, row = 1, []string{formatID(r.ID), r.Name, formatTime(r.CreatedAt), formatTime(r.UpdatedAt)}
+	case tagRecord:
+		index, row = 2, []string{formatID(r.ID), r.Name, formatTime(r.CreatedAt), formatTime(r.UpdatedAt)}
+	case bookRecord:
+		index, row = 3, []string{formatID(r.ID), r.Title, deref(r.ISBN), strconv.Itoa(r.PublishedYear), deref(r.Publisher),
+			strconv.Itoa(r.Pages), formatIDs(r.AuthorIDs), formatIDs(r.CategoryIDs), formatTime(r.CreatedAt), formatTime(r.UpdatedAt)}
+	case quoteRecord:
+		index, row = 4, []string{formatID(r.ID), formatID(r.BookID), string(r.Kind), r.Text, formatInt(r.Page),
+			formatInt(r.LocationStart), formatInt(r.LocationEnd), strconv.FormatBool(r.Favorite), formatInt(r.Rating),
+			formatIDs(r.TagIDs), formatTime(r.CreatedAt), formatTime(r.UpdatedAt)}
+	case annotationRecord:
+		index, row = 5, []string{formatID(r.ID), formatID(r.QuoteID), r.Text, formatInt(r.Location),
+			formatTime(r.CreatedAt), formatTime(r.UpdatedAt)}
+	default:
+		return fmt.Errorf("registro de tipo desconhecido: %T", record)
+	}
+
+	return w.writers[index].Write(row)
+}
+
+// commit grava o que falta e troca os arquivos anteriores pelos novos. Os
+// anteriores são renomeados para .old antes da troca; se alguma renomeação
+// falhar, eles voltam para o lugar, para que o diretório nunca misture
+// arquivos de dois backups
+func (w *csvWriter) commit() error {
+	for i, writer := range w.writers {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			w.abort()
+			return err
+		}
+		if err := w.files[i].Close(); err != nil {
+			w.abort()
+			return err
+		}
+	}
+
+	var saved, placed []string
+	rollback := func(err error) error {
+		for _, path := range placed {
+			os.Remove(path)
+		}
+		for _, path := range saved {
+			os.Rename(path+".old", path)
+		}
+		w.abort()
+		return err
+	}
+
+	for _, spec := range csvFiles {
+		path := filepath.Join(w.dir, spec.name)
+		err := os.Rename(path, path+".old")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return rollback(err)
+		}
+		saved = append(saved, path)
+	}
+
+	for _, spec := range csvFiles {
+		path := filepath.Join(w.dir, spec.name)
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return rollback(err)
+		}
+		placed = append(placed, path)
+	}
+
+	for _, path := range saved {
+		os.Remove(path + ".old")
+	}
+	return nil
+}
+
+// abort descarta os arquivos temporários
+func (w *csvWriter) abort() {
+	for i, file := range w.files {
+		file.Close()
+		os.Remove(filepath.Join(w.dir, csvFiles[i].name+".tmp"))
+	}
+}
+
+// csvReader lê os arquivos na ordem de csvFiles. Os arquivos que não existem
+// são pulados, e as colunas são encontradas pelo cabeçalho, em qualquer ordem
+type csvReader struct {
+	dir    string
+	index  int
+	file   *os.File
+	reader *csv.Reader
+	header map[string]int
+	line   int
+}
+
+func newCSVReader(dir string) *csvReader {
+	return &csvReader{dir: dir}
+}
+
+func (r *csvReader) next() (interface{}, error) {
+	for r.index < len(csvFiles) {
+		if r.reader == nil {
+			if err := r.open(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		row, err := r.reader.Read()
+		if err == io.EOF {
+			r.close()
+			r.index++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.line, _ = r.reader.FieldPos(0)
+
+		return parseCSVRow(csvFiles[r.index].kind, csvValues{header: r.header, row: row})
+	}
+	return nil, io.EOF
+}
+
+// open abre o arquivo atual e lê o cabeçalho, ou passa para o seguinte se o
+// arquivo não existir
+func (r *csvReader) open() error {
+	spec := csvFiles[r.index]
+	r.line = 0
+
+	file, err := os.Open(filepath.Join(r.dir, spec.name))
+	if errors.Is(err, fs.ErrNotExist) {
+		r.index++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		file.Close()
+		if err == io.EOF {
+			r.index++
+			return nil
+		}
+		return err
+	}
+
+	r.header = make(map[string]int, len(header))
+	for i, column := range header {
+		// planilhas costumam gravar o BOM do UTF-8 no início do arquivo
+		r.header[strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")] = i
+	}
+	r.file, r.reader = file, reader
+	return nil
+}
+
+func (r *csvReader) position() string {
+	if r.index >= len(csvFiles) {
+		return "fim do backup"
+	}
+	return fmt.Sprintf("%s, linha %d", csvFiles[r.index].name, r.line)
+}
+
+func (r *csvReader) close() {
+	if r.file != nil {
+		r.file.Close()
+	}
+	r.file, r.reader = nil, nil
+}
+
+func parseCSVRow(kind string, v csvValues) (interface{}, error) {
+	var record interface{}
+
+	switch kind {
+	case typeCategory:
+		record = categoryRecord{ID: int(v.id("id")), Name: v.text("name"), CreatedAt: v.time("created_at"), UpdatedAt: v.time("updated_at")}
+	case typeAuthor:
+		record = authorRecord{ID: v.id("id"), Name: v.text("name"), CreatedAt: v.time("created_at"), UpdatedAt: v.time("updated_at")}
+	case typeTag:
+		record = tagRecord{ID: v.id("id"), Name: v.text("name"), CreatedAt: v.time("created_at"), UpdatedAt: v.time("updated_at")}
+	case typeBook:
+		book := bookRecord{
+			ID:            v.id("id"),
+			Title:         v.text("title"),
+			ISBN:          v.optionalText("isbn"),
+			PublishedYear: v.number("published_year"),
+			Publisher:     v.optionalText("publisher"),
+			Pages:         v.number("pages"),
+			AuthorIDs:     v.ids("author_ids"),
+			CreatedAt:     v.time("created_at"),
+			UpdatedAt:     v.time("updated_at"),
+		}
+		for _, id := range v.ids("category_ids") {
+			book.CategoryIDs = append(book.CategoryIDs, int(id))
+		}
+		record = book
+	case typeQuote:
+		record = quoteRecord{
+			ID:            v.id("id"),
+			BookID:        v.id("book_id"),
+			Kind:          models.QuoteKind(v.text("kind")),
+			Text:          v.text("text"),
+			Page:          v.optionalNumber("page"),
+			LocationStart: v.optionalNumber("location_start"),
+			LocationEnd:   v.optionalNumber("location_end"),
+			Favorite:      v.boolean("favorite"),
+			Rating:        v.optionalNumber("rating"),
+			TagIDs:        v.ids("tag_ids"),
+			CreatedAt:     v.time("created_at"),
+			UpdatedAt:     v.time("updated_at"),
+		}
+	case typeAnnotation:
+		record = annotationRecord{
+			ID:        v.id("id"),
+			QuoteID:   v.id("quote_id"),
+			Text:      v.text("text"),
+			Location:  v.optionalNumber("location"),
+			CreatedAt: v.time("created_at"),
+			UpdatedAt: v.time("updated_at"),
+		}
+	}
+
+	if v.err != nil {
+		return nil, v.err
+	}
+	return record, nil
+}
+
+// csvValues converte as colunas de uma linha. O primeiro erro fica em err e
+// as conversões seguintes retornam o valor zero
+type csvValues struct {
+	header map[string]int
+	row    []string
+	err    error
+}
+
+func (v *csvValues) text(column string) string {
+	if i, ok := v.header[column]; ok && i < len(v.row) {
+		return v.row[i]
+	}
+	return ""
+}
+
+func (v *csvValues) optionalText(column string) *string {
+	if value := v.text(column); value != "" {
+		return &value
+	}
+	return nil
+}
+
+func (v *csvValues) fail(column string, err error) {
+	if v.err == nil {
+		v.err = fmt.Errorf("coluna %s: %w", column, err)
+	}
+}
+
+func (v *csvValues) id(column string) int64 {
+	id, err := strconv.ParseInt(strings.TrimSpace(v.text(column)), 10, 64)
+	if err != nil {
+		v.fail(column, err)
+	}
+	return id
+}
+
+func (v *csvValues) number(column string) int {
+	value := strings.TrimSpace(v.text(column))
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		v.fail(column, err)
+	}
+	return n
+}
+
+func (v *csvValues) optionalNumber(column string) *int {
+	if strings.TrimSpace(v.text(column)) == "" {
+		return nil
+	}
+	n := v.number(column)
+	return &n
+}
+
+func (v *csvValues) boolean(column string) bool {
+	value := strings.TrimSpace(v.text(column))
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		v.fail(column, err)
+	}
+	return b
+}
+
+func (v *csvValues) time(column string) time.Time {
+	value := strings.TrimSpace(v.text(column))
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		v.fail(column, err)
+	}
+	return t
+}
+
+func (v *csvValues) ids(column string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(v.text(column), ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			v.fail(column, err)
+			return nil
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func formatIDs[T int | int64](ids []T) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(int64(id), 10)
+	}
+	return strings.Join(parts, ";")
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
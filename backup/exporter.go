@@ -0,0 +1,238 @@
+package backup
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"quote-api/database"
+	"quote-api/models"
+	"quote-api/repository"
+)
+
+// pageSize é a quantidade de registros lida por query na exportação
+const pageSize = 500
+
+// Exporter grava a biblioteca inteira em um backup. Os registros são lidos em
+// páginas e gravados à medida que chegam, então a memória usada não depende do
+// tamanho da biblioteca
+type Exporter struct {
+	db           *sql.DB
+	authorRepo   *repository.AuthorRepository
+	bookRepo     *repository.BookRepository
+	categoryRepo *repository.CategoryRepository
+	quoteRepo    *repository.QuoteRepository
+	tagRepo      *repository.TagRepository
+}
+
+func NewExporter(
+	store *database.Store,
+	authorRepo *repository.AuthorRepository,
+	bookRepo *repository.BookRepository,
+	categoryRepo *repository.CategoryRepository,
+	quoteRepo *repository.QuoteRepository,
+	tagRepo *repository.TagRepository,
+) *Exporter {
+	return &Exporter{
+		db:           store.DB(),
+		authorRepo:   authorRepo,
+		bookRepo:     bookRepo,
+		categoryRepo: categoryRepo,
+		quoteRepo:    quoteRepo,
+		tagRepo:      tagRepo,
+	}
+}
+
+// Export grava o backup em path: um arquivo no formato JSONL ou um diretório
+// no formato CSV. O backup anterior só é substituído se a exportação terminar
+func (e *Exporter) Export(format Format, path string) (*Counts, error) {
+	switch format {
+	case FormatJSONL:
+		return e.exportJSONLFile(path)
+	case FormatCSV:
+		return e.ExportCSV(path)
+	}
+	return nil, fmt.Errorf("formato de backup desconhecido: %q", format)
+}
+
+func (e *Exporter) exportJSONLFile(path string) (*Counts, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), ".quotes-backup-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	// CreateTemp cria o arquivo só com permissão para o dono
+	if err := file.Chmod(0o644); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	counts, err := e.ExportJSONL(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// ExportJSONL grava o backup em w, um registro JSON por linha
+func (e *Exporter) ExportJSONL(w io.Writer) (*Counts, error) {
+	out := bufio.NewWriter(w)
+	counts, err := e.export(newJSONLWriter(out))
+	if err != nil {
+		return nil, err
+	}
+	if err := out.Flush(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// ExportCSV grava em dir um arquivo CSV por tipo de registro
+func (e *Exporter) ExportCSV(dir string) (*Counts, error) {
+	writer, err := newCSVWriter(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := e.export(writer)
+	if err != nil {
+		writer.abort()
+		return nil, err
+	}
+	if err := writer.commit(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// export lê tudo dentro de uma transação, para que o backup seja uma foto
+// consistente do banco mesmo com o servidor gravando ao mesmo tempo. As
+// citações vão agrupadas por livro, em ordem de leitura, cada uma seguida das
+// suas anotações
+func (e *Exporter) export(w recordWriter) (*Counts, error) {
+	tx, err := e.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	counts := &Counts{}
+	emit := func(record interface{}) error {
+		if err := w.write(record); err != nil {
+			return err
+		}
+		counts.add(record)
+		return nil
+	}
+
+	err = eachPage(e.categoryRepo.WithTx(tx).FindAll, func(category models.Category) error {
+		return emit(categoryRecord{ID: category.ID, Name: category.Name, CreatedAt: category.CreatedAt, UpdatedAt: category.UpdatedAt})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = eachPage(e.authorRepo.WithTx(tx).FindAll, func(author models.Author) error {
+		return emit(authorRecord{ID: author.ID, Name: author.Name, CreatedAt: author.CreatedAt, UpdatedAt: author.UpdatedAt})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = eachPage(e.tagRepo.WithTx(tx).FindAll, func(tag models.Tag) error {
+		return emit(tagRecord{ID: tag.ID, Name: tag.Name, CreatedAt: tag.CreatedAt, UpdatedAt: tag.UpdatedAt})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bookRepo := e.bookRepo.WithTx(tx)
+	err = eachBook(bookRepo, func(book models.Book) error {
+		return emit(newBookRecord(book))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// As citações são lidas livro a livro: a ordem de leitura de um livro sai
+	// do índice por book_id, enquanto ordenar todas de uma vez obrigaria cada
+	// página a ordenar a tabela inteira
+	quoteRepo := e.quoteRepo.WithTx(tx)
+	err = eachBook(bookRepo, func(book models.Book) error {
+		query := repository.QuoteQuery{BookIDs: []int64{book.ID}, Order: repository.QuoteOrderLocation, Limit: pageSize}
+		for {
+			page, err := quoteRepo.FindPageByQuery(query)
+			if err != nil {
+				return err
+			}
+			for _, quote := range page.Items {
+				if err := emit(newQuoteRecord(quote)); err != nil {
+					return err
+				}
+				for _, annotation := range quote.Annotations {
+					if err := emit(newAnnotationRecord(annotation)); err != nil {
+						return err
+					}
+				}
+			}
+			if page.Next == nil {
+				return nil
+			}
+			query.Cursor = page.Next
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// eachBook chama fn para cada livro, em ordem de título, com autores e
+// categorias
+func eachBook(bookRepo *repository.BookRepository, fn func(models.Book) error) error {
+	query := repository.BookQuery{Limit: pageSize}
+	for {
+		page, err := bookRepo.FindPageByQuery(query)
+		if err != nil {
+			return err
+		}
+		for _, book := range page.Items {
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		if page.Next == nil {
+			return nil
+		}
+		query.Cursor = page.Next
+	}
+}
+
+// eachPage chama fn para cada item de find, lendo pageSize itens por vez
+func eachPage[T any](find func(limit, offset int) ([]T, error), fn func(T) error) error {
+	for offset := 0; ; offset += pageSize {
+		items, err := find(pageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if len(items) < pageSize {
+			return nil
+		}
+	}
+}
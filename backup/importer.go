@@ -0,0 +1,463 @@
+package backup
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"quote-api/database"
+	"quote-api/dedupe"
+	"quote-api/models"
+	"quote-api/repository"
+	"strings"
+)
+
+// ImportReport resume uma importação. Created conta os registros gravados e
+// Matched, os que já existiam no banco e foram reaproveitados
+type ImportReport struct {
+	DryRun  bool
+	Created Counts
+	Matched Counts
+}
+
+// Importer grava um backup em um banco que pode já ter dados. Os IDs do
+// backup são trocados pelos do banco: cada registro é ligado a um registro
+// existente equivalente ou gravado com um ID novo
+type Importer struct {
+	db             *sql.DB
+	authorRepo     *repository.AuthorRepository
+	bookRepo       *repository.BookRepository
+	categoryRepo   *repository.CategoryRepository
+	quoteRepo      *repository.QuoteRepository
+	tagRepo        *repository.TagRepository
+	annotationRepo *repository.AnnotationRepository
+}
+
+func NewImporter(
+	store *database.Store,
+	authorRepo *repository.AuthorRepository,
+	bookRepo *repository.BookRepository,
+	categoryRepo *repository.CategoryRepository,
+	quoteRepo *repository.QuoteRepository,
+	tagRepo *repository.TagRepository,
+	annotationRepo *repository.AnnotationRepository,
+) *Importer {
+	return &Importer{
+		db:             store.DB(),
+		authorRepo:     authorRepo,
+		bookRepo:       bookRepo,
+		categoryRepo:   categoryRepo,
+		quoteRepo:      quoteRepo,
+		tagRepo:        tagRepo,
+		annotationRepo: annotationRepo,
+	}
+}
+
+// Import lê o backup em path, um arquivo JSONL ou um diretório CSV
+func (i *Importer) Import(format Format, path string, dryRun bool) (*ImportReport, error) {
+	switch format {
+	case FormatJSONL:
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return i.ImportJSONL(file, dryRun)
+	case FormatCSV:
+		return i.ImportCSV(path, dryRun)
+	}
+	return nil, fmt.Errorf("formato de backup desconhecido: %q", format)
+}
+
+// ImportJSONL importa um backup JSONL lido de r
+func (i *Importer) ImportJSONL(r io.Reader, dryRun bool) (*ImportReport, error) {
+	return i.run(newJSONLReader(r), dryRun)
+}
+
+// ImportCSV importa os arquivos CSV de dir. Arquivos ausentes contam como
+// vazios
+func (i *Importer) ImportCSV(dir string, dryRun bool) (*ImportReport, error) {
+	reader := newCSVReader(dir)
+	defer reader.close()
+	return i.run(reader, dryRun)
+}
+
+// run importa os registros em uma única transação: um registro inválido ou
+// que referencia outro ausente do backup desfaz a importação inteira. Com
+// dryRun, a transação é desfeita no final e o relatório mostra o que seria
+// gravado
+func (i *Importer) run(reader recordReader, dryRun bool) (*ImportReport, error) {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	run := &importRun{
+		authorRepo:     i.authorRepo.WithTx(tx),
+		bookRepo:       i.bookRepo.WithTx(tx),
+		categoryRepo:   i.categoryRepo.WithTx(tx),
+		quoteRepo:      i.quoteRepo.WithTx(tx),
+		tagRepo:        i.tagRepo.WithTx(tx),
+		annotationRepo: i.annotationRepo.WithTx(tx),
+		report:         &ImportReport{DryRun: dryRun},
+		categories:     make(map[int]int),
+		authors:        make(map[int64]int64),
+		tags:           make(map[int64]int64),
+		books:          make(map[int64]int64),
+		quotes:         make(map[int64]int64),
+		createdBooks:   make(map[int64]bool),
+		createdQuotes:  make(map[int64]bool),
+	}
+
+	for {
+		record, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = run.save(record)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", reader.position(), err)
+		}
+	}
+
+	if dryRun {
+		return run.report, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return run.report, nil
+}
+
+// importRun guarda o estado de uma importação em andamento. Os mapas levam
+// os IDs do backup aos IDs do banco
+type importRun struct {
+	authorRepo     *repository.AuthorRepository
+	bookRepo       *repository.BookRepository
+	categoryRepo   *repository.CategoryRepository
+	quoteRepo      *repository.QuoteRepository
+	tagRepo        *repository.TagRepository
+	annotationRepo *repository.AnnotationRepository
+	report         *ImportReport
+
+	categories map[int]int
+	authors    map[int64]int64
+	tags       map[int64]int64
+	books      map[int64]int64
+	quotes     map[int64]int64
+	// createdBooks e createdQuotes marcam, pelo ID do banco, os livros e as
+	// citações gravados nesta importação, que não têm com o que ser
+	// comparados: o backup é restaurado como está, mesmo com repetições
+	createdBooks  map[int64]bool
+	createdQuotes map[int64]bool
+
+	// duplicates indexa por dedupe.DuplicateKey as citações que o livro
+	// existingBookID já tinha
+	existingBookID int64
+	duplicates     map[string]int64
+}
+
+func (r *importRun) save(record interface{}) error {
+	switch record := record.(type) {
+	case categoryRecord:
+		return r.saveCategory(record)
+	case authorRecord:
+		return r.saveAuthor(record)
+	case tagRecord:
+		return r.saveTag(record)
+	case bookRecord:
+		return r.saveBook(record)
+	case quoteRecord:
+		return r.saveQuote(record)
+	case annotationRecord:
+		return r.saveAnnotation(record)
+	}
+	return fmt.Errorf("registro de tipo desconhecido: %T", record)
+}
+
+// Categorias, autores e tags são reaproveitados pelo nome, sem diferenciar
+// maiúsculas
+
+func (r *importRun) saveCategory(record categoryRecord) error {
+	name := strings.TrimSpace(record.Name)
+	if name == "" {
+		return errors.New("categoria sem nome")
+	}
+
+	category, err := r.categoryRepo.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if category != nil {
+		r.report.Matched.Categories++
+	} else {
+		if category, err = r.categoryRepo.Create(models.Category{Name: name}); err != nil {
+			return err
+		}
+		r.report.Created.Categories++
+	}
+
+	r.categories[record.ID] = category.ID
+	return nil
+}
+
+func (r *importRun) saveAuthor(record authorRecord) error {
+	name := strings.TrimSpace(record.Name)
+	if name == "" {
+		return errors.New("autor sem nome")
+	}
+
+	author, err := r.authorRepo.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if author != nil {
+		r.report.Matched.Authors++
+	} else {
+		if author, err = r.authorRepo.Create(models.Author{Name: name}); err != nil {
+			return err
+		}
+		r.report.Created.Authors++
+	}
+
+	r.authors[record.ID] = author.ID
+	return nil
+}
+
+func (r *importRun) saveTag(record tagRecord) error {
+	name := strings.TrimSpace(record.Name)
+	if name == "" {
+		return errors.New("tag sem nome")
+	}
+
+	tag, err := r.tagRepo.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if tag != nil {
+		r.report.Matched.Tags++
+	} else {
+		if tag, err = r.tagRepo.Create(models.Tag{Name: name}); err != nil {
+			return err
+		}
+		r.report.Created.Tags++
+	}
+
+	r.tags[record.ID] = tag.ID
+	return nil
+}
+
+// saveBook reaproveita um livro existente sem alterar os seus autores e
+// categorias
+func (r *importRun) saveBook(record bookRecord) error {
+	title := strings.TrimSpace(record.Title)
+	if title == "" {
+		return errors.New("livro sem título")
+	}
+
+	isbn := record.ISBN
+	if isbn != nil && strings.TrimSpace(*isbn) == "" {
+		isbn = nil
+	}
+
+	authorIDs := make([]int64, len(record.AuthorIDs))
+	for j, id := range record.AuthorIDs {
+		newID, ok := r.authors[id]
+		if !ok {
+			return fmt.Errorf("autor %d não está no backup", id)
+		}
+		authorIDs[j] = newID
+	}
+
+	categoryIDs := make([]int, len(record.CategoryIDs))
+	for j, id := range record.CategoryIDs {
+		newID, ok := r.categories[id]
+		if !ok {
+			return fmt.Errorf("categoria %d não está no backup", id)
+		}
+		categoryIDs[j] = newID
+	}
+
+	book, err := r.findBook(title, isbn)
+	if err != nil {
+		return err
+	}
+	if book != nil {
+		r.report.Matched.Books++
+	} else {
+		book, err = r.bookRepo.Create(models.Book{
+			Title:         title,
+			ISBN:          isbn,
+			PublishedYear: record.PublishedYear,
+			Publisher:     record.Publisher,
+			Pages:         record.Pages,
+		}, authorIDs, categoryIDs)
+		if err != nil {
+			return err
+		}
+		r.createdBooks[book.ID] = true
+		r.report.Created.Books++
+	}
+
+	r.books[record.ID] = book.ID
+	return nil
+}
+
+// findBook procura o livro pelo ISBN e depois pelo título. Um livro de mesmo
+// título com outro ISBN é outra edição e não é reaproveitado
+func (r *importRun) findBook(title string, isbn *string) (*models.Book, error) {
+	if isbn != nil {
+		book, err := r.bookRepo.FindByISBN(*isbn)
+		if book != nil || err != nil {
+			return book, err
+		}
+	}
+
+	book, err := r.bookRepo.FindByTitle(title)
+	if err != nil || book == nil {
+		return nil, err
+	}
+	if isbn != nil && book.ISBN != nil && *book.ISBN != *isbn {
+		return nil, nil
+	}
+	return book, nil
+}
+
+// saveQuote reaproveita uma citação que dedupe.Compare considera Duplicate,
+// mantendo a favorita, a avaliação e as tags que ela já tem
+func (r *importRun) saveQuote(record quoteRecord) error {
+	bookID, ok := r.books[record.BookID]
+	if !ok {
+		return fmt.Errorf("livro %d não está no backup", record.BookID)
+	}
+
+	if strings.TrimSpace(record.Text) == "" {
+		return errors.New("citação sem texto")
+	}
+
+	kind := record.Kind
+	switch kind {
+	case "":
+		kind = models.QuoteKindHighlight
+	case models.QuoteKindHighlight, models.QuoteKindNote, models.QuoteKindBookmark:
+	default:
+		return fmt.Errorf("tipo de citação inválido: %q", kind)
+	}
+
+	if record.Rating != nil && (*record.Rating < models.MinQuoteRating || *record.Rating > models.MaxQuoteRating) {
+		return fmt.Errorf("avaliação deve estar entre %d e %d", models.MinQuoteRating, models.MaxQuoteRating)
+	}
+
+	tagIDs := make([]int64, len(record.TagIDs))
+	for j, id := range record.TagIDs {
+		newID, ok := r.tags[id]
+		if !ok {
+			return fmt.Errorf("tag %d não está no backup", id)
+		}
+		tagIDs[j] = newID
+	}
+
+	quote := models.Quote{
+		BookID:        bookID,
+		Text:          record.Text,
+		Kind:          kind,
+		Page:          record.Page,
+		LocationStart: record.LocationStart,
+		LocationEnd:   record.LocationEnd,
+		Favorite:      record.Favorite,
+		Rating:        record.Rating,
+		CreatedAt:     record.CreatedAt,
+	}
+
+	if !r.createdBooks[bookID] {
+		existingID, err := r.findDuplicate(quote)
+		if err != nil {
+			return err
+		}
+		if existingID != 0 {
+			r.quotes[record.ID] = existingID
+			r.report.Matched.Quotes++
+			return nil
+		}
+	}
+
+	created, err := r.quoteRepo.Create(quote)
+	if err != nil {
+		return err
+	}
+	for _, tagID := range tagIDs {
+		if err := r.tagRepo.AddToQuote(created.ID, tagID); err != nil {
+			return err
+		}
+	}
+
+	r.quotes[record.ID] = created.ID
+	r.createdQuotes[created.ID] = true
+	r.report.Created.Quotes++
+	return nil
+}
+
+// findDuplicate procura a citação que o livro já tinha e que é Duplicate de
+// quote. As citações do livro são lidas de uma vez, na primeira citação dele,
+// para não comparar cada citação importada com todas as outras
+func (r *importRun) findDuplicate(quote models.Quote) (int64, error) {
+	if r.duplicates == nil || r.existingBookID != quote.BookID {
+		existing, err := r.quoteRepo.FindAllByBookID(quote.BookID)
+		if err != nil {
+			return 0, err
+		}
+
+		r.existingBookID = quote.BookID
+		r.duplicates = make(map[string]int64, len(existing))
+		for _, q := range existing {
+			if key := dedupe.DuplicateKey(q); r.duplicates[key] == 0 {
+				r.duplicates[key] = q.ID
+			}
+		}
+	}
+
+	return r.duplicates[dedupe.DuplicateKey(quote)], nil
+}
+
+// saveAnnotation não repete, em uma citação reaproveitada, uma anotação com o
+// mesmo texto
+func (r *importRun) saveAnnotation(record annotationRecord) error {
+	quoteID, ok := r.quotes[record.QuoteID]
+	if !ok {
+		return fmt.Errorf("citação %d não está no backup", record.QuoteID)
+	}
+
+	if strings.TrimSpace(record.Text) == "" {
+		return errors.New("anotação sem texto")
+	}
+
+	if !r.createdQuotes[quoteID] {
+		existing, err := r.annotationRepo.FindByQuoteID(quoteID)
+		if err != nil {
+			return err
+		}
+		for _, annotation := range existing {
+			if dedupe.TextHash(annotation.Text) == dedupe.TextHash(record.Text) {
+				r.report.Matched.Annotations++
+				return nil
+			}
+		}
+	}
+
+	_, err := r.annotationRepo.Create(models.Annotation{
+		QuoteID:   quoteID,
+		Text:      record.Text,
+		Location:  record.Location,
+		CreatedAt: record.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.report.Created.Annotations++
+	return nil
+}
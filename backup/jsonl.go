@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonlWriter grava um registro por linha, com o tipo no campo "type"
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &jsonlWriter{encoder: encoder}
+}
+
+func (w *jsonlWriter) write(record interface{}) error {
+	var line interface{}
+	switch r := record.(type) {
+	case categoryRecord:
+		line = struct {
+			Type string `json:"type"`
+			categoryRecord
+		}{typeCategory, r}
+	case authorRecord:
+		line = struct {
+			Type string `json:"type"`
+			authorRecord
+		}{typeAuthor, r}
+	case tagRecord:
+		line = struct {
+			Type string `json:"type"`
+			tagRecord
+		}{typeTag, r}
+	case bookRecord:
+		line = struct {
+			Type string `json:"type"`
+			bookRecord
+		}{typeBook, r}
+	case quoteRecord:
+		line = struct {
+			Type string `json:"type"`
+			quoteRecord
+		}{typeQuote, r}
+	case annotationRecord:
+		line = struct {
+			Type string `json:"type"`
+			annotationRecord
+		}{typeAnnotation, r}
+	default:
+		return fmt.Errorf("registro de tipo desconhecido: %T", record)
+	}
+	return w.encoder.Encode(line)
+}
+
+// jsonlReader lê uma linha por vez, sem limite de tamanho, e ignora as linhas
+// em branco
+type jsonlReader struct {
+	in   *bufio.Reader
+	line int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	return &jsonlReader{in: bufio.NewReader(r)}
+}
+
+func (r *jsonlReader) next() (interface{}, error) {
+	for {
+		data, err := r.in.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(data) == 0) {
+			return nil, err
+		}
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) > 0 {
+			return decodeLine(data)
+		}
+	}
+}
+
+func (r *jsonlReader) position() string {
+	return fmt.Sprintf("linha %d", r.line)
+}
+
+func decodeLine(data []byte) (interface{}, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	switch head.Type {
+	case typeCategory:
+		return decodeAs[categoryRecord](data)
+	case typeAuthor:
+		return decodeAs[authorRecord](data)
+	case typeTag:
+		return decodeAs[tagRecord](data)
+	case typeBook:
+		return decodeAs[bookRecord](data)
+	case typeQuote:
+		return decodeAs[quoteRecord](data)
+	case typeAnnotation:
+		return decodeAs[annotationRecord](data)
+	}
+	return nil, fmt.Errorf("tipo de registro desconhecido: %q", head.Type)
+}
+
+func decodeAs[T any](data []byte) (interface{}, error) {
+	var record T
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
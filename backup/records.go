@@ -0,0 +1,190 @@
+package backup
+
+import (
+	"quote-api/models"
+	"time"
+)
+
+// Format é o formato de um backup
+type Format string
+
+const (
+	// FormatJSONL grava um único arquivo com um registro JSON por linha
+	FormatJSONL Format = "jsonl"
+	// FormatCSV grava um diretório com um arquivo CSV por tipo de registro
+	FormatCSV Format = "csv"
+)
+
+// Valid indica se f é um formato conhecido
+func (f Format) Valid() bool {
+	return f == FormatJSONL || f == FormatCSV
+}
+
+// Tipos de registro, na ordem em que aparecem no backup. Um registro só
+// referencia registros de tipos anteriores, e cada anotação vem depois da sua
+// citação, então o backup pode ser importado lendo uma única vez
+const (
+	typeCategory   = "category"
+	typeAuthor     = "author"
+	typeTag        = "tag"
+	typeBook       = "book"
+	typeQuote      = "quote"
+	typeAnnotation = "annotation"
+)
+
+// Counts conta os registros de cada tipo
+type Counts struct {
+	Categories  int
+	Authors     int
+	Tags        int
+	Books       int
+	Quotes      int
+	Annotations int
+}
+
+func (c *Counts) add(record interface{}) {
+	switch record.(type) {
+	case categoryRecord:
+		c.Categories++
+	case authorRecord:
+		c.Authors++
+	case tagRecord:
+		c.Tags++
+	case bookRecord:
+		c.Books++
+	case quoteRecord:
+		c.Quotes++
+	case annotationRecord:
+		c.Annotations++
+	}
+}
+
+// recordWriter grava os registros de um backup, na ordem em que chegam
+type recordWriter interface {
+	write(record interface{}) error
+}
+
+// recordReader lê os registros de um backup, um de cada vez
+type recordReader interface {
+	// next retorna o próximo registro ou io.EOF no fim do backup
+	next() (interface{}, error)
+	// position descreve onde está o último registro lido, para as mensagens
+	// de erro
+	position() string
+}
+
+// Os registros trazem os IDs do banco de origem; na importação, eles só servem
+// para ligar os registros entre si
+
+type categoryRecord struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type authorRecord struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type tagRecord struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// bookRecord guarda os autores na ordem de exibição
+type bookRecord struct {
+	ID            int64     `json:"id"`
+	Title         string    `json:"title"`
+	ISBN          *string   `json:"isbn"`
+	PublishedYear int       `json:"published_year"`
+	Publisher     *string   `json:"publisher"`
+	Pages         int       `json:"pages"`
+	AuthorIDs     []int64   `json:"author_ids"`
+	CategoryIDs   []int     `json:"category_ids"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type quoteRecord struct {
+	ID            int64            `json:"id"`
+	BookID        int64            `json:"book_id"`
+	Kind          models.QuoteKind `json:"kind"`
+	Text          string           `json:"text"`
+	Page          *int             `json:"page"`
+	LocationStart *int             `json:"location_start"`
+	LocationEnd   *int             `json:"location_end"`
+	Favorite      bool             `json:"favorite"`
+	Rating        *int             `json:"rating"`
+	TagIDs        []int64          `json:"tag_ids"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+type annotationRecord struct {
+	ID        int64     `json:"id"`
+	QuoteID   int64     `json:"quote_id"`
+	Text      string    `json:"text"`
+	Location  *int      `json:"location"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newBookRecord(book models.Book) bookRecord {
+	record := bookRecord{
+		ID:            book.ID,
+		Title:         book.Title,
+		ISBN:          book.ISBN,
+		PublishedYear: book.PublishedYear,
+		Publisher:     book.Publisher,
+		Pages:         book.Pages,
+		AuthorIDs:     []int64{},
+		CategoryIDs:   []int{},
+		CreatedAt:     book.CreatedAt,
+		UpdatedAt:     book.UpdatedAt,
+	}
+	for _, author := range book.Authors {
+		record.AuthorIDs = append(record.AuthorIDs, author.ID)
+	}
+	for _, category := range book.Categories {
+		record.CategoryIDs = append(record.CategoryIDs, category.ID)
+	}
+	return record
+}
+
+func newQuoteRecord(quote models.Quote) quoteRecord {
+	record := quoteRecord{
+		ID:            quote.ID,
+		BookID:        quote.BookID,
+		Kind:          quote.Kind,
+		Text:          quote.Text,
+		Page:          quote.Page,
+		LocationStart: quote.LocationStart,
+		LocationEnd:   quote.LocationEnd,
+		Favorite:      quote.Favorite,
+		Rating:        quote.Rating,
+		TagIDs:        []int64{},
+		CreatedAt:     quote.CreatedAt,
+		UpdatedAt:     quote.UpdatedAt,
+	}
+	for _, tag := range quote.Tags {
+		record.TagIDs = append(record.TagIDs, tag.ID)
+	}
+	return record
+}
+
+func newAnnotationRecord(annotation models.Annotation) annotationRecord {
+	return annotationRecord{
+		ID:        annotation.ID,
+		QuoteID:   annotation.QuoteID,
+		Text:      annotation.Text,
+		Location:  annotation.Location,
+		CreatedAt: annotation.CreatedAt,
+		UpdatedAt: annotation.UpdatedAt,
+	}
+}
@@ -2,51 +2,99 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-var DB *sql.DB
+// DefaultDSN é o banco usado quando Config.DSN está vazio
+const DefaultDSN = "./quotes.db"
 
-func Init() {
-	var err error
+// Config define como abrir o banco
+type Config struct {
+	// DSN é o caminho do arquivo SQLite ou uma URI "file:" com parâmetros do
+	// driver. ":memory:" abre um banco temporário, útil em testes
+	DSN string
+	// SkipMigrations abre o banco sem aplicar as migrations pendentes
+	SkipMigrations bool
+}
+
+// Store é a conexão com um banco SQLite. Cada Store é independente, então um
+// mesmo processo pode abrir vários bancos
+type Store struct {
+	db *sql.DB
+}
 
-	DB, err = sql.Open("sqlite3", "./quotes.db")
+// Open abre o banco, habilita as chaves estrangeiras e aplica as migrations
+// pendentes, a menos que cfg.SkipMigrations esteja ligado
+func Open(cfg Config) (*Store, error) {
+	dsn := cfg.DSN
+	if dsn == "" {
+		dsn = DefaultDSN
+	}
 
+	db, err := sql.Open("sqlite3", withForeignKeys(dsn))
 	if err != nil {
-		log.Fatal("Erro ao abrir o banco:", err)
+		return nil, fmt.Errorf("erro ao abrir o banco: %w", err)
 	}
 
-	if err = DB.Ping(); err != nil {
-		log.Fatal("Erro ao conectar ao banco:", err)
+	// Cada conexão com ":memory:" seria um banco diferente
+	if strings.Contains(dsn, ":memory:") {
+		db.SetMaxOpenConns(1)
 	}
 
-	configureSQLite()
-	RunMigrations()
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao conectar ao banco: %w", err)
+	}
+
+	//db.Exec("PRAGMA journal_mode = WAL")        // Write-Ahead Logging para melhor concorrência
+	//db.Exec("PRAGMA synchronous = NORMAL")      // Balance entre segurança e performance
+	//db.Exec("PRAGMA cache_size = -64000")       // 64MB de cache
+	//db.Exec("PRAGMA temp_store = MEMORY")       // Tabelas temporárias em memória
+	//db.Exec("PRAGMA mmap_size = 30000000000")
+
+	log.Println("Connected to database")
+
+	store := &Store{db: db}
+
+	if !cfg.SkipMigrations {
+		if err := store.Migrate(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
 
 	log.Println("database initialized")
 
+	return store, nil
 }
 
-func configureSQLite() {
-	_, err := DB.Exec("PRAGMA foreign_keys = ON;")
-	if err != nil {
-		log.Fatal("Erro ao habilitar chaves estrangeiras:", err)
+// withForeignKeys liga as chaves estrangeiras pelo DSN. Um PRAGMA valeria só
+// para a conexão em que rodou, e não para as demais do pool
+func withForeignKeys(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys=") || strings.Contains(dsn, "_fk=") {
+		return dsn
 	}
 
-	//DB.Exec("PRAGMA journal_mode = WAL")        // Write-Ahead Logging para melhor concorrência
-	//DB.Exec("PRAGMA synchronous = NORMAL")      // Balance entre segurança e performance
-	//DB.Exec("PRAGMA cache_size = -64000")       // 64MB de cache
-	//DB.Exec("PRAGMA temp_store = MEMORY")       // Tabelas temporárias em memória
-	//DB.Exec("PRAGMA mmap_size = 30000000000")
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_foreign_keys=on"
+}
 
-	log.Println("Connected to database")
+// DB retorna a conexão usada pelos repositórios
+func (s *Store) DB() *sql.DB {
+	return s.db
 }
 
-func Close() {
-	if DB != nil {
-		DB.Close()
-		log.Println("Closed database connection")
+func (s *Store) Close() error {
+	if err := s.db.Close(); err != nil {
+		return err
 	}
+	log.Println("Closed database connection")
+	return nil
 }
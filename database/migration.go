@@ -1,160 +1,291 @@
 package database
 
-import "log"
+import (
+	"database/sql"
+	"fmt"
+)
 
-func RunMigrations() {
-	log.Println("Running migrations...")
+// Migration é um passo numerado do schema. Up e Down rodam dentro da mesma
+// transação que registra a versão em schema_migrations
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+	Down    func(tx *sql.Tx) error
+}
 
-	createAuthorTable()
-	createBookTable()
-	createBookAuthorTable()
-	createBookCategoryTable()
-	createQuoteTable()
-	createBookCategoryTable()
+// migrations lista todos os passos do schema em ordem. Novas alterações entram
+// sempre no final, com a próxima versão; passos já publicados não devem mudar.
+// Os passos usam IF NOT EXISTS para que bancos criados antes do versionamento
+// sejam adotados sem erro
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_author_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS author (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        );
+        `),
+		Down: execAll("DROP TABLE IF EXISTS author"),
+	},
+	{
+		Version: 2,
+		Name:    "create_book_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS book (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            title TEXT NOT NULL,
+            isbn TEXT UNIQUE,
+            published_year INTEGER NOT NULL,
+            publisher TEXT,
+            pages INTEGER NOT NULL DEFAULT 0,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 
-	log.Println("Migrations done.")
-}
+            CHECK (published_year >= 0 AND published_year <= 9999),
+            CHECK (pages >= 0)
+        );
+        `),
+		Down: execAll("DROP TABLE IF EXISTS book"),
+	},
+	{
+		Version: 3,
+		Name:    "create_category_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS category (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL UNIQUE,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        );
+        `),
+		Down: execAll("DROP TABLE IF EXISTS category"),
+	},
+	{
+		Version: 4,
+		Name:    "create_book_author_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS book_author (
+            book_id INTEGER NOT NULL,
+            author_id INTEGER NOT NULL,
+            "order" INTEGER NOT NULL DEFAULT 1,
 
-func createAuthorTable() {
-	query := `
-    CREATE TABLE IF NOT EXISTS author (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        name TEXT NOT NULL,
-        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-    );
-    `
-
-	_, err := DB.Exec(query)
-	if err != nil {
-		log.Fatal("Erro ao criar tabela author:", err)
-	}
-	log.Println(" Tabela author criada/verificada")
-}
+            PRIMARY KEY (book_id, author_id),
+            FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+            FOREIGN KEY (author_id) REFERENCES author(id) ON DELETE CASCADE,
 
-func createBookTable() {
-	query := `
-    CREATE TABLE IF NOT EXISTS book (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        title TEXT NOT NULL,
-        isbn TEXT UNIQUE,
-        published_year INTEGER NOT NULL,
-        publisher TEXT,
-        pages INTEGER NOT NULL DEFAULT 0,
-        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        
-        CHECK (published_year >= 0 AND published_year <= 9999),
-        CHECK (pages >= 0)
-    );
-    `
-
-	_, err := DB.Exec(query)
-	if err != nil {
-		log.Fatal("Erro ao criar tabela book:", err)
-	}
-	log.Println("Tabela book criada/verificada")
+            CHECK ("order" >= 1)
+        );
+        `),
+		Down: execAll("DROP TABLE IF EXISTS book_author"),
+	},
+	{
+		Version: 5,
+		Name:    "create_book_category_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS book_category (
+            book_id INTEGER NOT NULL,
+            category_id INTEGER NOT NULL,
 
-}
+            PRIMARY KEY (book_id, category_id),
+            FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+            FOREIGN KEY (category_id) REFERENCES category(id) ON DELETE CASCADE
+        );
+        `),
+		Down: execAll("DROP TABLE IF EXISTS book_category"),
+	},
+	{
+		Version: 6,
+		Name:    "create_quote_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS quote (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            book_id INTEGER NOT NULL,
+            text TEXT NOT NULL,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 
-func createCategoryTable() {
-	query := `
-    CREATE TABLE IF NOT EXISTS category (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        name TEXT NOT NULL UNIQUE,
-        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-    );
-    `
-
-	_, err := DB.Exec(query)
-	if err != nil {
-		log.Fatal("Erro ao criar tabela category:", err)
-	}
-	log.Println("Tabela category criada/verificada")
-}
+            FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
 
-func createQuoteTable() {
-	query := `
-    CREATE TABLE IF NOT EXISTS quote (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        book_id INTEGER NOT NULL,
-        text TEXT NOT NULL,
-        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        
-        FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
-        
-        CHECK (LENGTH(text) >= 1)
-    );
-    `
-
-	_, err := DB.Exec(query)
-	if err != nil {
-		log.Fatal("Erro ao criar tabela quote:", err)
-	}
-	log.Println("Tabela quote criada/verificada")
-}
+            CHECK (LENGTH(text) >= 1)
+        );
+        `),
+		Down: execAll("DROP TABLE IF EXISTS quote"),
+	},
+	{
+		// Colunas do Kindle. O CHECK de kind fica na própria coluna para que
+		// o DROP COLUMN do rollback seja aceito pelo SQLite
+		Version: 7,
+		Name:    "add_quote_kindle_columns",
+		Up: func(tx *sql.Tx) error {
+			columns := []struct{ name, definition string }{
+				{"kind", "TEXT NOT NULL DEFAULT 'highlight' CHECK (kind IN ('highlight', 'note', 'bookmark'))"},
+				{"page", "INTEGER"},
+				{"location_start", "INTEGER"},
+				{"location_end", "INTEGER"},
+			}
+			for _, column := range columns {
+				if err := addColumnIfMissing(tx, "quote", column.name, column.definition); err != nil {
+					return err
+				}
+			}
 
-func createBookAuthorTable() {
-	query := `
-    CREATE TABLE IF NOT EXISTS book_author (
-        book_id INTEGER NOT NULL,
-        author_id INTEGER NOT NULL,
-        "order" INTEGER NOT NULL DEFAULT 1,
-        
-        PRIMARY KEY (book_id, author_id),
-        FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
-        FOREIGN KEY (author_id) REFERENCES author(id) ON DELETE CASCADE,
-        
-        CHECK ("order" >= 1)
-    );
-    `
-
-	_, err := DB.Exec(query)
-	if err != nil {
-		log.Fatal("❌ Erro ao criar tabela book_author:", err)
-	}
-	log.Println("  ✓ Tabela book_author criada/verificada")
+			_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_quote_book_location ON quote (book_id, location_start)")
+			return err
+		},
+		Down: execAll(
+			"DROP INDEX IF EXISTS idx_quote_book_location",
+			"ALTER TABLE quote DROP COLUMN location_end",
+			"ALTER TABLE quote DROP COLUMN location_start",
+			"ALTER TABLE quote DROP COLUMN page",
+			"ALTER TABLE quote DROP COLUMN kind",
+		),
+	},
+	{
+		Version: 8,
+		Name:    "create_annotation_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS annotation (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            quote_id INTEGER NOT NULL,
+            text TEXT NOT NULL,
+            location INTEGER,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+            FOREIGN KEY (quote_id) REFERENCES quote(id) ON DELETE CASCADE,
+
+            CHECK (LENGTH(text) >= 1)
+        );
+        `,
+			"CREATE INDEX IF NOT EXISTS idx_annotation_quote ON annotation (quote_id)",
+		),
+		Down: execAll("DROP TABLE IF EXISTS annotation"),
+	},
+	{
+		Version: 9,
+		Name:    "create_quote_search_index",
+		Up:      createSearchIndex,
+		Down:    dropSearchIndex,
+	},
+	{
+		// COLLATE NOCASE faz o UNIQUE, as buscas por nome e a ordenação
+		// ignorarem maiúsculas/minúsculas
+		Version: 10,
+		Name:    "create_tag_tables",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS tag (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL COLLATE NOCASE UNIQUE,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+            CHECK (LENGTH(name) >= 1)
+        );
+        `, `
+        CREATE TABLE IF NOT EXISTS quote_tag (
+            quote_id INTEGER NOT NULL,
+            tag_id INTEGER NOT NULL,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+            PRIMARY KEY (quote_id, tag_id),
+            FOREIGN KEY (quote_id) REFERENCES quote(id) ON DELETE CASCADE,
+            FOREIGN KEY (tag_id) REFERENCES tag(id) ON DELETE CASCADE
+        );
+        `,
+			"CREATE INDEX IF NOT EXISTS idx_quote_tag_tag ON quote_tag (tag_id)",
+		),
+		Down: execAll(
+			"DROP TABLE IF EXISTS quote_tag",
+			"DROP TABLE IF EXISTS tag",
+		),
+	},
+	{
+		Version: 11,
+		Name:    "add_quote_favorite_rating",
+		Up: func(tx *sql.Tx) error {
+			columns := []struct{ name, definition string }{
+				{"favorite", "INTEGER NOT NULL DEFAULT 0 CHECK (favorite IN (0, 1))"},
+				{"rating", "INTEGER CHECK (rating BETWEEN 1 AND 5)"},
+			}
+			for _, column := range columns {
+				if err := addColumnIfMissing(tx, "quote", column.name, column.definition); err != nil {
+					return err
+				}
+			}
+
+			_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_quote_favorite ON quote (favorite) WHERE favorite = 1")
+			return err
+		},
+		Down: execAll(
+			"DROP INDEX IF EXISTS idx_quote_favorite",
+			"ALTER TABLE quote DROP COLUMN rating",
+			"ALTER TABLE quote DROP COLUMN favorite",
+		),
+	},
+	{
+		// O UNIQUE (scope, day) garante uma só citação do dia por escopo, mesmo
+		// com vários processos gravando no mesmo banco
+		Version: 12,
+		Name:    "create_daily_quote_table",
+		Up: execAll(`
+        CREATE TABLE IF NOT EXISTS daily_quote (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            day TEXT NOT NULL,
+            scope TEXT NOT NULL,
+            quote_id INTEGER NOT NULL,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+            UNIQUE (scope, day),
+            FOREIGN KEY (quote_id) REFERENCES quote(id) ON DELETE CASCADE
+        );
+        `,
+			"CREATE INDEX IF NOT EXISTS idx_daily_quote_quote ON daily_quote (quote_id)",
+		),
+		Down: execAll("DROP TABLE IF EXISTS daily_quote"),
+	},
+	{
+		// O UNIQUE de tag.name (COLLATE NOCASE) deixava "Ação" e "AÇÃO" como
+		// tags diferentes; name_key guarda o nome em models.TagKey e é ela
+		// que identifica a tag. Tags unidas na migração não voltam no rollback
+		Version: 13,
+		Name:    "add_tag_name_key",
+		Up:      addTagNameKey,
+		Down: execAll(
+			"DROP INDEX IF EXISTS idx_tag_name_key",
+			"ALTER TABLE tag DROP COLUMN name_key",
+		),
+	},
 }
 
-func createBookCategoryTable() {
-	query := `
-    CREATE TABLE IF NOT EXISTS book_category (
-        book_id INTEGER NOT NULL,
-        category_id INTEGER NOT NULL,
-        
-        PRIMARY KEY (book_id, category_id),
-        FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
-        FOREIGN KEY (category_id) REFERENCES category(id) ON DELETE CASCADE
-    );
-    `
-
-	_, err := DB.Exec(query)
-	if err != nil {
-		log.Fatal("Erro ao criar tabela book_category:", err)
+// execAll cria um passo de migration que executa as queries em ordem
+func execAll(queries ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, query := range queries {
+			if _, err := tx.Exec(query); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	log.Println(" Tabela book_category criada/verificada")
 }
 
-func DropAllTables() {
-	log.Println("Removendo todas as tabelas...")
-
-	tables := []string{
-		"DROP TABLE IF EXISTS book_category",
-		"DROP TABLE IF EXISTS book_author",
-		"DROP TABLE IF EXISTS quote",
-		"DROP TABLE IF EXISTS category",
-		"DROP TABLE IF EXISTS book",
-		"DROP TABLE IF EXISTS author",
+// addColumnIfMissing permite adicionar colunas em bancos que já as receberam
+// antes do versionamento das migrations
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("erro ao ler colunas da tabela %s: %w", table, err)
 	}
-
-	for _, dropQuery := range tables {
-		_, err := DB.Exec(dropQuery)
-		if err != nil {
-			log.Printf("Aviso ao remover tabela: %v", err)
-		}
+	if count > 0 {
+		return nil
 	}
 
-	log.Println("Todas as tabelas removidas!")
+	_, err = tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
 }
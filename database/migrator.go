@@ -0,0 +1,280 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Migrate aplica todas as migrations pendentes e cria o índice de busca se
+// faltar
+func (s *Store) Migrate() error {
+	log.Println("Running migrations...")
+
+	if err := s.migrateTo(LatestVersion()); err != nil {
+		return fmt.Errorf("erro ao aplicar migrations: %w", err)
+	}
+	if err := s.ensureSearchIndex(); err != nil {
+		return err
+	}
+
+	log.Println("Migrations done.")
+	return nil
+}
+
+// MigrateTo leva o schema até a versão pedida, aplicando ou revertendo
+// migrations conforme necessário. Versão 0 reverte todas
+func (s *Store) MigrateTo(version int) error {
+	if err := s.migrateTo(version); err != nil {
+		return fmt.Errorf("erro ao migrar schema: %w", err)
+	}
+
+	current, err := s.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	log.Printf("Schema na versão %d", current)
+	return nil
+}
+
+// Rollback reverte as últimas migrations aplicadas. steps não pode ser
+// negativo nem maior que o número de migrations aplicadas
+func (s *Store) Rollback(steps int) error {
+	applied, err := s.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	if steps < 0 || steps > len(applied) {
+		return fmt.Errorf("não é possível reverter %d migrations (aplicadas: %d)", steps, len(applied))
+	}
+
+	current, target := 0, 0
+	if len(applied) > 0 {
+		current = applied[len(applied)-1]
+	}
+	if steps < len(applied) {
+		target = applied[len(applied)-steps-1]
+	}
+
+	if err := s.migrateTo(target); err != nil {
+		return fmt.Errorf("erro ao reverter migrations: %w", err)
+	}
+	log.Printf("Schema revertido da versão %d para %d", current, target)
+	return nil
+}
+
+// LatestVersion retorna a versão da última migration conhecida
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// CurrentVersion retorna a maior versão aplicada, ou 0 em um banco vazio
+func (s *Store) CurrentVersion() (int, error) {
+	applied, err := s.appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+	if len(applied) == 0 {
+		return 0, nil
+	}
+	return applied[len(applied)-1], nil
+}
+
+// MigrationStatus indica se uma migration já foi aplicada
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+// MigrationStatuses lista as migrations conhecidas, da mais antiga para a mais nova
+func (s *Store) MigrationStatuses() ([]MigrationStatus, error) {
+	versions, err := s.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool)
+	for _, version := range versions {
+		applied[version] = true
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		statuses = append(statuses, MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: applied[migration.Version],
+		})
+	}
+	return statuses, nil
+}
+
+// PrintMigrationStatus lista as migrations e se já foram aplicadas
+func (s *Store) PrintMigrationStatus() error {
+	statuses, err := s.MigrationStatuses()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range statuses {
+		status := "pendente"
+		if migration.Applied {
+			status = "aplicada"
+		}
+		fmt.Printf("%03d %-30s %s\n", migration.Version, migration.Name, status)
+	}
+	return nil
+}
+
+func (s *Store) migrateTo(target int) error {
+	if target < 0 || target > LatestVersion() {
+		return fmt.Errorf("versão %d inexistente (última: %d)", target, LatestVersion())
+	}
+
+	versions, err := s.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[int]bool)
+	for _, version := range versions {
+		applied[version] = true
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= target && !applied[migration.Version] {
+			if err := s.applyMigration(migration); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > target && applied[migration.Version] {
+			if err := s.revertMigration(migration); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) createMigrationsTable() error {
+	_, err := s.db.Exec(`
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        name TEXT NOT NULL,
+        applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+    `)
+	if err != nil {
+		return fmt.Errorf("erro ao criar tabela schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions retorna as versões registradas em ordem crescente
+func (s *Store) appliedVersions() ([]int, error) {
+	if err := s.createMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query("SELECT version FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (s *Store) applyMigration(migration Migration) error {
+	err := s.inMigrationTx(func(tx *sql.Tx) error {
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+		_, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	log.Printf(" Migration %03d_%s aplicada", migration.Version, migration.Name)
+	return nil
+}
+
+func (s *Store) revertMigration(migration Migration) error {
+	err := s.inMigrationTx(func(tx *sql.Tx) error {
+		if err := migration.Down(tx); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rollback %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	log.Printf(" Migration %03d_%s revertida", migration.Version, migration.Name)
+	return nil
+}
+
+func (s *Store) inMigrationTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DropAllTables remove todas as tabelas, inclusive o histórico de migrations
+func (s *Store) DropAllTables() error {
+	log.Println("Removendo todas as tabelas...")
+
+	tables := []string{
+		"DROP TABLE IF EXISTS quote_fts",
+		"DROP TABLE IF EXISTS book_category",
+		"DROP TABLE IF EXISTS book_author",
+		"DROP TABLE IF EXISTS daily_quote",
+		"DROP TABLE IF EXISTS quote_tag",
+		"DROP TABLE IF EXISTS tag",
+		"DROP TABLE IF EXISTS annotation",
+		"DROP TABLE IF EXISTS quote",
+		"DROP TABLE IF EXISTS category",
+		"DROP TABLE IF EXISTS book",
+		"DROP TABLE IF EXISTS author",
+		"DROP TABLE IF EXISTS schema_migrations",
+	}
+
+	for _, dropQuery := range tables {
+		if _, err := s.db.Exec(dropQuery); err != nil {
+			return fmt.Errorf("erro ao remover tabela: %w", err)
+		}
+	}
+
+	log.Println("Todas as tabelas removidas!")
+	return nil
+}
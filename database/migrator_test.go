@@ -0,0 +1,95 @@
+package database
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Open e as migrations registram cada passo no log padrão
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(Config{DSN: filepath.Join(t.TempDir(), "quotes.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func checkVersion(t *testing.T, store *Store, want int) {
+	t.Helper()
+	current, err := store.CurrentVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != want {
+		t.Errorf("versão %d, quer %d", current, want)
+	}
+}
+
+func TestRollback_DownAndUp(t *testing.T) {
+	store := newTestStore(t)
+	latest := LatestVersion()
+	checkVersion(t, store, latest)
+
+	if err := store.Rollback(1); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion(t, store, migrations[len(migrations)-2].Version)
+
+	if err := store.Rollback(len(migrations) - 1); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion(t, store, 0)
+
+	var tables int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'quote'`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Error("a tabela quote continua depois de reverter tudo")
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion(t, store, latest)
+
+	statuses, err := store.MigrationStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Errorf("migration %d não foi reaplicada", status.Version)
+		}
+	}
+	if _, err := store.db.Exec(`INSERT INTO author (name) VALUES ('Machado de Assis')`); err != nil {
+		t.Errorf("schema reaplicado não aceita inserts: %v", err)
+	}
+}
+
+func TestRollback_InvalidSteps(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, steps := range []int{-1, len(migrations) + 1} {
+		if err := store.Rollback(steps); err == nil {
+			t.Errorf("Rollback(%d) não retornou erro", steps)
+		}
+	}
+	checkVersion(t, store, LatestVersion())
+
+	if err := store.Rollback(0); err != nil {
+		t.Errorf("Rollback(0): %v", err)
+	}
+	checkVersion(t, store, LatestVersion())
+}
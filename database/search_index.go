@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// O índice quote_fts guarda o texto da citação, o título do livro e os nomes
+// dos autores, com rowid igual ao id da citação. Os triggers o mantêm em dia
+// com alterações em quote, book, author e book_author
+var searchIndexStatements = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS quote_fts USING fts5(
+        text, title, authors,
+        tokenize = 'unicode61 remove_diacritics 2'
+    )`,
+	`DELETE FROM quote_fts`,
+	`INSERT INTO quote_fts (rowid, text, title, authors)
+        SELECT q.id, q.text, b.title, ` + authorsOf("q.book_id") + `
+        FROM quote q
+        INNER JOIN book b ON b.id = q.book_id`,
+
+	`CREATE TRIGGER IF NOT EXISTS quote_fts_quote_insert AFTER INSERT ON quote BEGIN
+        INSERT INTO quote_fts (rowid, text, title, authors)
+        SELECT NEW.id, NEW.text, b.title, ` + authorsOf("NEW.book_id") + `
+        FROM book b WHERE b.id = NEW.book_id;
+    END`,
+	`CREATE TRIGGER IF NOT EXISTS quote_fts_quote_update AFTER UPDATE OF text, book_id ON quote BEGIN
+        DELETE FROM quote_fts WHERE rowid = OLD.id;
+        INSERT INTO quote_fts (rowid, text, title, authors)
+        SELECT NEW.id, NEW.text, b.title, ` + authorsOf("NEW.book_id") + `
+        FROM book b WHERE b.id = NEW.book_id;
+    END`,
+	`CREATE TRIGGER IF NOT EXISTS quote_fts_quote_delete AFTER DELETE ON quote BEGIN
+        DELETE FROM quote_fts WHERE rowid = OLD.id;
+    END`,
+	`CREATE TRIGGER IF NOT EXISTS quote_fts_book_update AFTER UPDATE OF title ON book BEGIN
+        UPDATE quote_fts SET title = NEW.title
+        WHERE rowid IN (SELECT id FROM quote WHERE book_id = NEW.id);
+    END`,
+	`CREATE TRIGGER IF NOT EXISTS quote_fts_author_update AFTER UPDATE OF name ON author BEGIN
+        UPDATE quote_fts SET authors = ` + authorsOf("(SELECT book_id FROM quote WHERE id = quote_fts.rowid)") + `
+        WHERE rowid IN (
+            SELECT q.id FROM quote q
+            INNER JOIN book_author ba ON ba.book_id = q.book_id
+            WHERE ba.author_id = NEW.id
+        );
+    END`,
+	`CREATE TRIGGER IF NOT EXISTS quote_fts_book_author_insert AFTER INSERT ON book_author BEGIN
+        UPDATE quote_fts SET authors = ` + authorsOf("NEW.book_id") + `
+        WHERE rowid IN (SELECT id FROM quote WHERE book_id = NEW.book_id);
+    END`,
+	`CREATE TRIGGER IF NOT EXISTS quote_fts_book_author_delete AFTER DELETE ON book_author BEGIN
+        UPDATE quote_fts SET authors = ` + authorsOf("OLD.book_id") + `
+        WHERE rowid IN (SELECT id FROM quote WHERE book_id = OLD.book_id);
+    END`,
+}
+
+var dropSearchIndexStatements = []string{
+	"DROP TRIGGER IF EXISTS quote_fts_book_author_delete",
+	"DROP TRIGGER IF EXISTS quote_fts_book_author_insert",
+	"DROP TRIGGER IF EXISTS quote_fts_author_update",
+	"DROP TRIGGER IF EXISTS quote_fts_book_update",
+	"DROP TRIGGER IF EXISTS quote_fts_quote_delete",
+	"DROP TRIGGER IF EXISTS quote_fts_quote_update",
+	"DROP TRIGGER IF EXISTS quote_fts_quote_insert",
+	"DROP TABLE IF EXISTS quote_fts",
+}
+
+func authorsOf(bookID string) string {
+	return "(SELECT group_concat(a.name, ' ') FROM book_author ba INNER JOIN author a ON a.id = ba.author_id WHERE ba.book_id = " + bookID + ")"
+}
+
+// createSearchIndex cria e popula o índice FTS5. Se o SQLite foi compilado
+// sem FTS5 (build sem a tag sqlite_fts5), nada é criado e a busca usa LIKE
+func createSearchIndex(tx *sql.Tx) error {
+	if !fts5Available(tx) {
+		log.Println(" FTS5 indisponível (compile com -tags sqlite_fts5); busca usará LIKE")
+		return nil
+	}
+	return execAll(searchIndexStatements...)(tx)
+}
+
+func dropSearchIndex(tx *sql.Tx) error {
+	return execAll(dropSearchIndexStatements...)(tx)
+}
+
+// ensureSearchIndex cria o índice em bancos migrados por um build sem FTS5,
+// quando o build atual tem suporte. O contrário não é possível: os triggers
+// de um índice existente exigem FTS5 a cada escrita em quote
+func (s *Store) ensureSearchIndex() error {
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'quote_fts')").Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar índice de busca: %w", err)
+	}
+
+	available := fts5Available(s.db)
+	if exists && !available {
+		return errors.New("o banco possui o índice de busca quote_fts; compile com -tags sqlite_fts5")
+	}
+	if exists || !available {
+		return nil
+	}
+
+	if err := s.inMigrationTx(createSearchIndex); err != nil {
+		return fmt.Errorf("erro ao criar índice de busca: %w", err)
+	}
+	log.Println(" Índice de busca quote_fts criado")
+	return nil
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func fts5Available(db queryRower) bool {
+	var enabled bool
+	err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	return err == nil && enabled
+}
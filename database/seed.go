@@ -0,0 +1,205 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+type seedBook struct {
+	title         string
+	isbn          string
+	publishedYear int
+	publisher     string
+	pages         int
+	authors       []string
+	categories    []string
+	quotes        []seedQuote
+}
+
+type seedQuote struct {
+	text          string
+	page          int
+	locationStart int
+	locationEnd   int
+}
+
+var seedAuthors = []string{
+	"Marcus Aurelius",
+	"Frank Herbert",
+	"Andrew Hunt",
+	"David Thomas",
+	"Machado de Assis",
+	"Clarice Lispector",
+}
+
+var seedCategories = []string{
+	"Filosofia",
+	"Ficção Científica",
+	"Programação",
+	"Literatura Brasileira",
+}
+
+var seedBooks = []seedBook{
+	{
+		title:         "Meditations",
+		isbn:          "9780812968255",
+		publishedYear: 180,
+		publisher:     "Modern Library",
+		pages:         304,
+		authors:       []string{"Marcus Aurelius"},
+		categories:    []string{"Filosofia"},
+		quotes: []seedQuote{
+			{"You have power over your mind - not outside events. Realize this, and you will find strength.", 12, 180, 182},
+			{"The happiness of your life depends upon the quality of your thoughts.", 45, 640, 641},
+			{"Waste no more time arguing about what a good man should be. Be one.", 160, 2410, 2411},
+		},
+	},
+	{
+		title:         "Dune",
+		isbn:          "9780441013593",
+		publishedYear: 1965,
+		publisher:     "Ace",
+		pages:         896,
+		authors:       []string{"Frank Herbert"},
+		categories:    []string{"Ficção Científica"},
+		quotes: []seedQuote{
+			{"I must not fear. Fear is the mind-killer.", 8, 120, 121},
+			{"The mystery of life isn't a problem to solve, but a reality to experience.", 40, 610, 612},
+		},
+	},
+	{
+		title:         "The Pragmatic Programmer",
+		isbn:          "9780201616224",
+		publishedYear: 1999,
+		publisher:     "Addison-Wesley",
+		pages:         352,
+		authors:       []string{"Andrew Hunt", "David Thomas"},
+		categories:    []string{"Programação"},
+		quotes: []seedQuote{
+			{"Care about your craft.", 1, 95, 95},
+			{"Don't live with broken windows.", 5, 310, 311},
+			{"Every piece of knowledge must have a single, unambiguous, authoritative representation within a system.", 27, 820, 823},
+		},
+	},
+	{
+		title:         "Dom Casmurro",
+		isbn:          "9788525406958",
+		publishedYear: 1899,
+		publisher:     "L&PM",
+		pages:         256,
+		authors:       []string{"Machado de Assis"},
+		categories:    []string{"Literatura Brasileira"},
+		quotes: []seedQuote{
+			{"A vida é cheia de obrigações que a gente cumpre, por mais vontade que tenha de as infringir deliberadamente.", 30, 400, 402},
+			{"Capitu, apesar daqueles olhos que o Diabo lhe deu...", 75, 1050, 1051},
+		},
+	},
+	{
+		title:         "A Hora da Estrela",
+		isbn:          "9788532508126",
+		publishedYear: 1977,
+		publisher:     "Rocco",
+		pages:         88,
+		authors:       []string{"Clarice Lispector"},
+		categories:    []string{"Literatura Brasileira"},
+		quotes: []seedQuote{
+			{"Tudo no mundo começou com um sim.", 11, 20, 20},
+			{"Enquanto eu tiver perguntas e não houver resposta continuarei a escrever.", 12, 35, 36},
+		},
+	},
+}
+
+// Seed insere um conjunto de dados de exemplo em uma única transação. Se já
+// houver autores cadastrados, o seed é ignorado para não duplicar dados
+func (s *Store) Seed() error {
+	log.Println("Inserindo dados de exemplo...")
+
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM author").Scan(&count); err != nil {
+		return fmt.Errorf("erro ao verificar dados existentes: %w", err)
+	}
+	if count > 0 {
+		log.Println("Banco já possui dados, seed ignorado")
+		return nil
+	}
+
+	if err := s.inMigrationTx(seed); err != nil {
+		return fmt.Errorf("erro ao inserir dados de exemplo: %w", err)
+	}
+
+	log.Println("Dados de exemplo inseridos!")
+	return nil
+}
+
+func seed(tx *sql.Tx) error {
+	now := time.Now()
+
+	authorIDs := make(map[string]int64)
+	for _, name := range seedAuthors {
+		result, err := tx.Exec(
+			"INSERT INTO author (name, created_at, updated_at) VALUES (?, ?, ?)",
+			name, now, now,
+		)
+		if err != nil {
+			return err
+		}
+		authorIDs[name], _ = result.LastInsertId()
+	}
+
+	categoryIDs := make(map[string]int64)
+	for _, name := range seedCategories {
+		result, err := tx.Exec(
+			"INSERT INTO category (name, created_at, updated_at) VALUES (?, ?, ?)",
+			name, now, now,
+		)
+		if err != nil {
+			return err
+		}
+		categoryIDs[name], _ = result.LastInsertId()
+	}
+
+	for _, book := range seedBooks {
+		result, err := tx.Exec(`
+            INSERT INTO book (title, isbn, published_year, publisher, pages, created_at, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?)
+        `, book.title, book.isbn, book.publishedYear, book.publisher, book.pages, now, now)
+		if err != nil {
+			return err
+		}
+		bookID, _ := result.LastInsertId()
+
+		for i, author := range book.authors {
+			_, err = tx.Exec(
+				`INSERT INTO book_author (book_id, author_id, "order") VALUES (?, ?, ?)`,
+				bookID, authorIDs[author], i+1,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, category := range book.categories {
+			_, err = tx.Exec(
+				"INSERT INTO book_category (book_id, category_id) VALUES (?, ?)",
+				bookID, categoryIDs[category],
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, quote := range book.quotes {
+			_, err = tx.Exec(`
+                INSERT INTO quote (book_id, text, kind, page, location_start, location_end, created_at, updated_at)
+                VALUES (?, ?, 'highlight', ?, ?, ?, ?, ?)
+            `, bookID, quote.text, quote.page, quote.locationStart, quote.locationEnd, now, now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"database/sql"
+	"quote-api/models"
+)
+
+// addTagNameKey cria tag.name_key e a preenche com models.TagKey, que o SQLite
+// não sabe calcular. Tags que passam a ter a mesma chave são unidas na de
+// menor ID antes de o índice UNIQUE ser criado
+func addTagNameKey(tx *sql.Tx) error {
+	if err := addColumnIfMissing(tx, "tag", "name_key", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT id, name FROM tag ORDER BY id")
+	if err != nil {
+		return err
+	}
+
+	type tagKey struct {
+		id  int64
+		key string
+	}
+	var tags []tagKey
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		tags = append(tags, tagKey{id, models.TagKey(name)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	kept := make(map[string]int64, len(tags))
+	for _, tag := range tags {
+		keptID, ok := kept[tag.key]
+		if !ok {
+			kept[tag.key] = tag.id
+			if _, err := tx.Exec("UPDATE tag SET name_key = ? WHERE id = ?", tag.key, tag.id); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err := tx.Exec(`
+            INSERT OR IGNORE INTO quote_tag (quote_id, tag_id, created_at)
+            SELECT quote_id, ?, created_at FROM quote_tag WHERE tag_id = ?
+        `, keptID, tag.id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM quote_tag WHERE tag_id = ?", tag.id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM tag WHERE id = ?", tag.id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_name_key ON tag (name_key)")
+	return err
+}
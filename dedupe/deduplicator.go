@@ -0,0 +1,152 @@
+package dedupe
+
+import (
+	"database/sql"
+	"quote-api/database"
+	"quote-api/models"
+	"quote-api/repository"
+)
+
+// Merge registra uma citação removida em favor de outra do mesmo livro
+type Merge struct {
+	BookID    int64
+	KeptID    int64
+	RemovedID int64
+	Relation  Relation
+}
+
+// Report resume uma execução do Deduplicator
+type Report struct {
+	DryRun        bool
+	BooksScanned  int
+	QuotesScanned int
+	Merges        []Merge
+}
+
+// Deduplicator remove duplicatas de citações já gravadas, aplicando as mesmas
+// regras usadas na importação do Kindle
+type Deduplicator struct {
+	db             *sql.DB
+	quoteRepo      *repository.QuoteRepository
+	annotationRepo *repository.AnnotationRepository
+	tagRepo        *repository.TagRepository
+}
+
+func NewDeduplicator(store *database.Store, quoteRepo *repository.QuoteRepository, annotationRepo *repository.AnnotationRepository, tagRepo *repository.TagRepository) *Deduplicator {
+	return &Deduplicator{
+		db:             store.DB(),
+		quoteRepo:      quoteRepo,
+		annotationRepo: annotationRepo,
+		tagRepo:        tagRepo,
+	}
+}
+
+// Run percorre as citações livro a livro e mantém apenas a versão mais recente
+// de cada destaque. As anotações, tags, favorita e avaliação das removidas
+// passam para a mantida. Com dryRun, apenas relata o que seria feito
+func (d *Deduplicator) Run(dryRun bool) (*Report, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	quoteRepo := d.quoteRepo.WithTx(tx)
+	annotationRepo := d.annotationRepo.WithTx(tx)
+	tagRepo := d.tagRepo.WithTx(tx)
+
+	bookIDs, err := quoteRepo.FindBookIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: dryRun}
+
+	for _, bookID := range bookIDs {
+		quotes, err := quoteRepo.FindAllByBookID(bookID)
+		if err != nil {
+			return nil, err
+		}
+
+		report.BooksScanned++
+		report.QuotesScanned += len(quotes)
+
+		for _, merge := range FindMerges(quotes) {
+			if !dryRun {
+				if err := annotationRepo.MoveToQuote(merge.RemovedID, merge.KeptID); err != nil {
+					return nil, err
+				}
+				if err := tagRepo.MoveToQuote(merge.RemovedID, merge.KeptID); err != nil {
+					return nil, err
+				}
+				if err := quoteRepo.MergeMarks(merge.RemovedID, merge.KeptID); err != nil {
+					return nil, err
+				}
+				if err := quoteRepo.Delete(merge.RemovedID); err != nil {
+					return nil, err
+				}
+			}
+			report.Merges = append(report.Merges, merge)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// FindMerges decide, entre citações de um mesmo livro, quais devem ser removidas.
+// Em cada par repetido fica a mais recente; no empate, a de maior ID
+func FindMerges(quotes []models.Quote) []Merge {
+	removed := make(map[int]bool)
+	var merges []Merge
+
+	for i := range quotes {
+		if removed[i] {
+			continue
+		}
+
+		for j := i + 1; j < len(quotes); j++ {
+			if removed[j] {
+				continue
+			}
+
+			relation := Compare(quotes[i], quotes[j])
+			if relation == Unrelated {
+				continue
+			}
+
+			kept, loser := j, i
+			if newer(quotes[i], quotes[j]) {
+				kept, loser = i, j
+			}
+
+			removed[loser] = true
+			merges = append(merges, Merge{
+				BookID:    quotes[i].BookID,
+				KeptID:    quotes[kept].ID,
+				RemovedID: quotes[loser].ID,
+				Relation:  relation,
+			})
+
+			if loser == i {
+				break
+			}
+		}
+	}
+
+	return merges
+}
+
+func newer(a, b models.Quote) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.ID > b.ID
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
@@ -0,0 +1,182 @@
+package dedupe
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"quote-api/database"
+	"quote-api/models"
+	"quote-api/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// database.Open registra cada conexão no log padrão
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// dedupeFixture é um livro com duas versões de um destaque, cada uma com as
+// suas marcas, e uma citação repetida sem marcas
+type dedupeFixture struct {
+	deduplicator   *Deduplicator
+	quoteRepo      *repository.QuoteRepository
+	annotationRepo *repository.AnnotationRepository
+	tagRepo        *repository.TagRepository
+
+	short, extended, copy1, copy2 int64
+}
+
+func newDedupeFixture(t *testing.T) *dedupeFixture {
+	t.Helper()
+
+	store, err := database.Open(database.Config{DSN: filepath.Join(t.TempDir(), "quotes.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	f := &dedupeFixture{
+		quoteRepo:      repository.NewQuoteRepository(store),
+		annotationRepo: repository.NewAnnotationRepository(store),
+		tagRepo:        repository.NewTagRepository(store),
+	}
+	f.deduplicator = NewDeduplicator(store, f.quoteRepo, f.annotationRepo, f.tagRepo)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	book, err := repository.NewBookRepository(store).Create(models.Book{Title: "Dom Casmurro"}, nil, nil)
+	must(err)
+
+	create := func(quote models.Quote, day int) int64 {
+		t.Helper()
+		quote.BookID = book.ID
+		quote.CreatedAt = time.Date(2024, time.May, day, 10, 0, 0, 0, time.UTC)
+		created, err := f.quoteRepo.Create(quote)
+		must(err)
+		return created.ID
+	}
+	f.short = create(highlight(0, 10, 12, "Capitu, olhos de ressaca"), 1)
+	f.extended = create(highlight(0, 10, 15, "Capitu, olhos de ressaca. Olhos de cigana"), 2)
+	f.copy1 = create(highlight(0, 40, 41, "A vida é cheia de obrigações"), 3)
+	f.copy2 = create(highlight(0, 40, 41, "A vida é cheia de obrigações"), 4)
+
+	// a versão antiga tem a favorita, a avaliação maior, uma tag própria, uma
+	// em comum com a nova e uma anotação
+	must(f.quoteRepo.SetFavorite(f.short, true))
+	must(f.quoteRepo.SetRating(f.short, intPtr(5)))
+	must(f.quoteRepo.SetRating(f.extended, intPtr(3)))
+	for name, quotes := range map[string][]int64{"releitura": {f.short}, "amor": {f.short, f.extended}, "ciúme": {f.extended}} {
+		tag, err := f.tagRepo.Create(models.Tag{Name: name})
+		must(err)
+		for _, id := range quotes {
+			must(f.tagRepo.AddToQuote(id, tag.ID))
+		}
+	}
+	_, err = f.annotationRepo.Create(models.Annotation{QuoteID: f.short, Text: "Releia"})
+	must(err)
+
+	return f
+}
+
+func (f *dedupeFixture) quote(t *testing.T, id int64) *models.Quote {
+	t.Helper()
+	quote, err := f.quoteRepo.FindByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return quote
+}
+
+func TestDeduplicator_Run(t *testing.T) {
+	f := newDedupeFixture(t)
+
+	report, err := f.deduplicator.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int64]Merge{
+		f.short: {BookID: 1, KeptID: f.extended, RemovedID: f.short, Relation: Overlapping},
+		f.copy1: {BookID: 1, KeptID: f.copy2, RemovedID: f.copy1, Relation: Duplicate},
+	}
+	if len(report.Merges) != len(want) {
+		t.Fatalf("merges %+v, quer %+v", report.Merges, want)
+	}
+	for _, merge := range report.Merges {
+		if merge != want[merge.RemovedID] {
+			t.Errorf("merge %+v, quer %+v", merge, want[merge.RemovedID])
+		}
+	}
+	if report.BooksScanned != 1 || report.QuotesScanned != 4 {
+		t.Errorf("%d livros e %d citações verificados, quer 1 e 4", report.BooksScanned, report.QuotesScanned)
+	}
+
+	for _, id := range []int64{f.short, f.copy1} {
+		if exists, _ := f.quoteRepo.Exists(id); exists {
+			t.Errorf("citação %d não foi removida", id)
+		}
+	}
+
+	kept := f.quote(t, f.extended)
+	if !kept.Favorite {
+		t.Error("a mantida deveria herdar a favorita")
+	}
+	if kept.Rating == nil || *kept.Rating != 5 {
+		t.Errorf("avaliação = %v, quer a maior, 5", kept.Rating)
+	}
+	var tags []string
+	for _, tag := range kept.Tags {
+		tags = append(tags, tag.Name)
+	}
+	if want := []string{"amor", "ciúme", "releitura"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, quer %v", tags, want)
+	}
+	if len(kept.Annotations) != 1 || kept.Annotations[0].Text != "Releia" {
+		t.Errorf("anotações = %+v, quer a da removida", kept.Annotations)
+	}
+
+	copy2 := f.quote(t, f.copy2)
+	if copy2.Favorite || copy2.Rating != nil {
+		t.Errorf("a cópia sem marcas ganhou favorita %v e avaliação %v", copy2.Favorite, copy2.Rating)
+	}
+
+	again, err := f.deduplicator.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Merges) != 0 {
+		t.Errorf("a segunda execução removeu %+v", again.Merges)
+	}
+}
+
+func TestDeduplicator_RunDryRun(t *testing.T) {
+	f := newDedupeFixture(t)
+
+	report, err := f.deduplicator.Run(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || len(report.Merges) != 2 {
+		t.Errorf("relatório %+v, quer 2 merges em dry-run", report)
+	}
+
+	total, err := f.quoteRepo.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Errorf("%d citações depois do dry-run, quer 4", total)
+	}
+	if kept := f.quote(t, f.extended); kept.Favorite || len(kept.Tags) != 2 {
+		t.Errorf("o dry-run mudou a citação mantida: favorita %v, tags %v", kept.Favorite, kept.Tags)
+	}
+}
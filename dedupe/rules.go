@@ -0,0 +1,107 @@
+package dedupe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"quote-api/models"
+	"strconv"
+	"strings"
+)
+
+// Relation descreve como duas citações do mesmo livro se relacionam
+type Relation int
+
+const (
+	Unrelated Relation = iota
+	// Duplicate: mesma posição e mesmo texto
+	Duplicate
+	// Overlapping: intervalos sobrepostos e um texto contém o outro, como
+	// acontece quando um destaque é estendido ou ajustado no Kindle
+	Overlapping
+)
+
+func (r Relation) String() string {
+	switch r {
+	case Duplicate:
+		return "duplicate"
+	case Overlapping:
+		return "overlapping"
+	}
+	return "unrelated"
+}
+
+// NormalizeText colapsa espaços e ignora maiúsculas/minúsculas, já que o Kindle
+// pode mudar quebras de linha ao regravar um destaque
+func NormalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// TextHash identifica o texto normalizado de uma citação
+func TextHash(text string) string {
+	sum := sha256.Sum256([]byte(NormalizeText(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// DuplicateKey identifica uma citação para a relação Duplicate: duas citações
+// são Duplicate exatamente quando têm a mesma chave. Serve para procurar
+// duplicatas em um mapa em vez de comparar as citações duas a duas
+func DuplicateKey(quote models.Quote) string {
+	return fmt.Sprintf("%s|%s|%s|%s", quote.Kind, formatInt(quote.LocationStart), formatInt(quote.LocationEnd), TextHash(quote.Text))
+}
+
+// Compare classifica duas citações do mesmo livro e do mesmo tipo
+func Compare(a, b models.Quote) Relation {
+	if a.Kind != b.Kind {
+		return Unrelated
+	}
+
+	if DuplicateKey(a) == DuplicateKey(b) {
+		return Duplicate
+	}
+
+	if !overlaps(a, b) {
+		return Unrelated
+	}
+
+	textA, textB := NormalizeText(a.Text), NormalizeText(b.Text)
+	if strings.Contains(textA, textB) || strings.Contains(textB, textA) {
+		return Overlapping
+	}
+
+	return Unrelated
+}
+
+// Latest indica se b é mais recente que a. Em caso de empate, ou se b não
+// tiver data, vence b, que é o último visto
+func Latest(a, b models.Quote) bool {
+	return b.CreatedAt.IsZero() || !b.CreatedAt.Before(a.CreatedAt)
+}
+
+// overlaps compara intervalos de posição. Sem posição nos dois lados (PDFs,
+// por exemplo), cai para a mesma página
+func overlaps(a, b models.Quote) bool {
+	if a.LocationStart != nil && b.LocationStart != nil {
+		return *a.LocationStart <= end(b) && *b.LocationStart <= end(a)
+	}
+
+	if a.LocationStart == nil && b.LocationStart == nil {
+		return a.Page != nil && b.Page != nil && *a.Page == *b.Page
+	}
+
+	return false
+}
+
+func end(q models.Quote) int {
+	if q.LocationEnd != nil {
+		return *q.LocationEnd
+	}
+	return *q.LocationStart
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return strconv.Itoa(*n)
+}
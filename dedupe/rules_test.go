@@ -0,0 +1,216 @@
+package dedupe
+
+import (
+	"quote-api/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intPtr(n int) *int { return &n }
+
+// highlight monta um destaque com posição; end 0 repete start
+func highlight(id int64, start, end int, text string) models.Quote {
+	if end == 0 {
+		end = start
+	}
+	return models.Quote{
+		ID:            id,
+		BookID:        1,
+		Kind:          models.QuoteKindHighlight,
+		Text:          text,
+		LocationStart: intPtr(start),
+		LocationEnd:   intPtr(end),
+	}
+}
+
+func TestCompare(t *testing.T) {
+	onPage := func(page int, text string) models.Quote {
+		return models.Quote{Kind: models.QuoteKindHighlight, Text: text, Page: intPtr(page)}
+	}
+	note := highlight(0, 10, 12, "Capitu, olhos de ressaca")
+	note.Kind = models.QuoteKindNote
+
+	tests := []struct {
+		name string
+		a, b models.Quote
+		want Relation
+	}{
+		{
+			name: "mesma posição e texto",
+			a:    highlight(0, 10, 12, "Capitu, olhos de ressaca"),
+			b:    highlight(0, 10, 12, "Capitu, olhos de ressaca"),
+			want: Duplicate,
+		},
+		{
+			name: "texto com outras quebras de linha e maiúsculas",
+			a:    highlight(0, 10, 12, "Capitu,\nolhos  de ressaca"),
+			b:    highlight(0, 10, 12, "capitu, olhos de RESSACA"),
+			want: Duplicate,
+		},
+		{
+			name: "mesmo texto em outra posição",
+			a:    highlight(0, 10, 12, "Capitu, olhos de ressaca"),
+			b:    highlight(0, 90, 92, "Capitu, olhos de ressaca"),
+			want: Unrelated,
+		},
+		{
+			name: "tipos diferentes",
+			a:    highlight(0, 10, 12, "Capitu, olhos de ressaca"),
+			b:    note,
+			want: Unrelated,
+		},
+		{
+			name: "destaque estendido",
+			a:    highlight(0, 10, 12, "Capitu, olhos de ressaca"),
+			b:    highlight(0, 10, 15, "Capitu, olhos de ressaca. Olhos de cigana oblíqua e dissimulada"),
+			want: Overlapping,
+		},
+		{
+			name: "intervalo contido em outro",
+			a:    highlight(0, 10, 20, "Disse que eram olhos de ressaca, de cigana oblíqua"),
+			b:    highlight(0, 12, 14, "olhos de ressaca"),
+			want: Overlapping,
+		},
+		{
+			name: "destaque encurtado por um dos lados",
+			a:    highlight(0, 10, 15, "Capitu, olhos de ressaca. Olhos de cigana"),
+			b:    highlight(0, 13, 15, "Olhos de cigana"),
+			want: Overlapping,
+		},
+		{
+			name: "intervalos sobrepostos com textos diferentes",
+			a:    highlight(0, 10, 15, "Capitu, olhos de ressaca"),
+			b:    highlight(0, 14, 18, "A vida é cheia de obrigações"),
+			want: Unrelated,
+		},
+		{
+			name: "texto contido em posição distante",
+			a:    highlight(0, 10, 15, "Capitu, olhos de ressaca"),
+			b:    highlight(0, 40, 41, "olhos de ressaca"),
+			want: Unrelated,
+		},
+		{
+			name: "sem posição, mesma página",
+			a:    onPage(7, "olhos de ressaca"),
+			b:    onPage(7, "Capitu, olhos de ressaca"),
+			want: Overlapping,
+		},
+		{
+			name: "sem posição, páginas diferentes",
+			a:    onPage(7, "olhos de ressaca"),
+			b:    onPage(8, "Capitu, olhos de ressaca"),
+			want: Unrelated,
+		},
+		{
+			name: "só um com posição",
+			a:    onPage(7, "olhos de ressaca"),
+			b:    highlight(0, 10, 12, "Capitu, olhos de ressaca"),
+			want: Unrelated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare(a, b) = %s, quer %s", got, tt.want)
+			}
+			if got := Compare(tt.b, tt.a); got != tt.want {
+				t.Errorf("Compare(b, a) = %s, quer %s", got, tt.want)
+			}
+			if duplicate := DuplicateKey(tt.a) == DuplicateKey(tt.b); duplicate != (tt.want == Duplicate) {
+				t.Errorf("DuplicateKey iguais = %v, mas Compare = %s", duplicate, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMerges(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.May, d, 10, 0, 0, 0, time.UTC) }
+	at := func(quote models.Quote, d int) models.Quote {
+		quote.CreatedAt = day(d)
+		return quote
+	}
+
+	tests := []struct {
+		name   string
+		quotes []models.Quote
+		want   []Merge
+	}{
+		{
+			name: "duplicata exata fica com a mais recente",
+			quotes: []models.Quote{
+				at(highlight(1, 10, 12, "Capitu, olhos de ressaca"), 5),
+				at(highlight(2, 10, 12, "Capitu, olhos de ressaca"), 3),
+			},
+			want: []Merge{{BookID: 1, KeptID: 1, RemovedID: 2, Relation: Duplicate}},
+		},
+		{
+			name: "no empate de data fica a de maior ID",
+			quotes: []models.Quote{
+				at(highlight(1, 10, 12, "Capitu, olhos de ressaca"), 3),
+				at(highlight(2, 10, 12, "Capitu, olhos de ressaca"), 3),
+			},
+			want: []Merge{{BookID: 1, KeptID: 2, RemovedID: 1, Relation: Duplicate}},
+		},
+		{
+			name: "versão estendida substitui a anterior",
+			quotes: []models.Quote{
+				at(highlight(1, 10, 12, "Capitu, olhos de ressaca"), 1),
+				at(highlight(2, 10, 15, "Capitu, olhos de ressaca. Olhos de cigana"), 2),
+			},
+			want: []Merge{{BookID: 1, KeptID: 2, RemovedID: 1, Relation: Overlapping}},
+		},
+		{
+			name: "a versão encurtada mais recente vence",
+			quotes: []models.Quote{
+				at(highlight(1, 10, 15, "Capitu, olhos de ressaca. Olhos de cigana"), 1),
+				at(highlight(2, 13, 15, "Olhos de cigana"), 2),
+			},
+			want: []Merge{{BookID: 1, KeptID: 2, RemovedID: 1, Relation: Overlapping}},
+		},
+		{
+			name: "três versões ficam só com a última",
+			quotes: []models.Quote{
+				at(highlight(1, 10, 11, "Capitu"), 1),
+				at(highlight(2, 10, 12, "Capitu, olhos de ressaca"), 2),
+				at(highlight(3, 10, 15, "Capitu, olhos de ressaca. Olhos de cigana"), 3),
+			},
+			want: []Merge{
+				{BookID: 1, KeptID: 2, RemovedID: 1, Relation: Overlapping},
+				{BookID: 1, KeptID: 3, RemovedID: 2, Relation: Overlapping},
+			},
+		},
+		{
+			name: "destaques diferentes ficam",
+			quotes: []models.Quote{
+				at(highlight(1, 10, 12, "Capitu, olhos de ressaca"), 1),
+				at(highlight(2, 40, 41, "A vida é cheia de obrigações"), 2),
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindMerges(tt.quotes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindMerges = %+v, quer %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	older := models.Quote{CreatedAt: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)}
+	newer := models.Quote{CreatedAt: time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC)}
+
+	if !Latest(older, newer) || Latest(newer, older) {
+		t.Error("Latest deve preferir a data mais recente")
+	}
+	if !Latest(older, older) {
+		t.Error("no empate, Latest deve preferir b")
+	}
+	if !Latest(newer, models.Quote{}) {
+		t.Error("sem data, Latest deve preferir b")
+	}
+}
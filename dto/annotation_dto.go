@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+type AnnotationResponse struct {
+	ID        int64     `json:"id"`
+	Text      string    `json:"text"`
+	Location  *int      `json:"location,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -21,3 +21,10 @@ type AuthorSimple struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
 }
+
+type ListAuthorsResponse struct {
+	Authors []AuthorResponse `json:"authors"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
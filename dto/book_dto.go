@@ -34,9 +34,23 @@ type UpdateBookRequest struct {
 	CategoriesIDs []int   `json:"categories_ids" validate:"required,min=1,max=2"`
 }
 
+// BookSimple é o livro embutido nas citações, com os autores na ordem do livro
 type BookSimple struct {
-	ID            int64   `json:"id"`
-	Title         string  `json:"title"`
-	PublishedYear int     `json:"published_year"`
-	Isbn          *string `json:"isbn,omitempty"`
+	ID            int64            `json:"id"`
+	Title         string           `json:"title"`
+	PublishedYear int              `json:"published_year"`
+	Isbn          *string          `json:"isbn,omitempty"`
+	Authors       []AuthorSimple   `json:"authors"`
+	Categories    []CategorySimple `json:"categories"`
+}
+
+type ListBooksResponse struct {
+	Books   []BookResponse `json:"books"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	HasMore bool           `json:"has_more"`
+	// NextCursor e PrevCursor levam às páginas vizinhas via ?cursor=
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
@@ -20,3 +20,10 @@ type CategorySimple struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
 }
+
+type ListCategoriesResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+	Total      int                `json:"total"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+}
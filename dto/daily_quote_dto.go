@@ -0,0 +1,16 @@
+package dto
+
+// DailyQuoteResponse é a citação do dia de um escopo. Date segue o fuso
+// configurado no servidor
+type DailyQuoteResponse struct {
+	Date  string        `json:"date"`
+	Scope string        `json:"scope"`
+	Quote QuoteResponse `json:"quote"`
+}
+
+type ListDailyQuotesResponse struct {
+	Days   []DailyQuoteResponse `json:"days"`
+	Total  int                  `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+type ErrorResponse struct {
+	Error  string               `json:"error"`
+	Code   string               `json:"code,omitempty"`
+	Status int                  `json:"status"`
+	Fields []FieldErrorResponse `json:"fields,omitempty"`
+}
+
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
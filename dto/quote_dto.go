@@ -3,33 +3,104 @@ package dto
 import "time"
 
 type QuoteResponse struct {
-	ID        int64      `json:"id"`
-	Text      string     `json:"text"`
-	Book      BookSimple `json:"book"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ID            int64                `json:"id"`
+	Text          string               `json:"text"`
+	Kind          string               `json:"kind"`
+	Page          *int                 `json:"page,omitempty"`
+	LocationStart *int                 `json:"location_start,omitempty"`
+	LocationEnd   *int                 `json:"location_end,omitempty"`
+	Favorite      bool                 `json:"favorite"`
+	Rating        *int                 `json:"rating,omitempty"`
+	Book          BookSimple           `json:"book"`
+	Notes         []AnnotationResponse `json:"notes"`
+	Tags          []TagSimple          `json:"tags"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     *time.Time           `json:"updated_at,omitempty"`
 }
 
 type CreateQuoteRequest struct {
-	BookID int64  `json:"book_id" validate:"required"`
-	Text   string `json:"text" validate:"required"`
+	BookID        int64  `json:"book_id" validate:"required"`
+	Text          string `json:"text" validate:"required"`
+	Kind          string `json:"kind,omitempty" validate:"omitempty,oneof=highlight note bookmark"`
+	Page          *int   `json:"page,omitempty"`
+	LocationStart *int   `json:"location_start,omitempty"`
+	LocationEnd   *int   `json:"location_end,omitempty"`
 }
 
 type UpdateQuoteRequest struct {
 	Text *string `json:"text" validate:"required,min=3"`
 }
 
+// SetRatingRequest é o corpo de PUT /quotes/{id}/rating
+type SetRatingRequest struct {
+	Rating *int `json:"rating" validate:"required,min=1,max=5"`
+}
+
+// ListQuotesRequest reúne os filtros de GET /quotes. Os IDs aceitam listas
+// separadas por vírgula (?book_id=1,2) e casam com qualquer um deles
 type ListQuotesRequest struct {
-	Limit      int   `json:"limit"`
-	Offset     int   `json:"offset"`
-	BookID     int64 `json:"book_id,omitempty"`
-	CategoryID int   `json:"category_id,omitempty"`
-	AuthorID   int64 `json:"author_id,omitempty"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// Cursor é o next_cursor ou prev_cursor de uma resposta anterior e
+	// substitui Offset. Os filtros e a ordenação devem ser os mesmos
+	Cursor      string  `json:"cursor,omitempty"`
+	BookIDs     []int64 `json:"book_id,omitempty"`
+	AuthorIDs   []int64 `json:"author_id,omitempty"`
+	CategoryIDs []int   `json:"category_id,omitempty"`
+	TagIDs      []int64 `json:"tag_id,omitempty"`
+	// Tags filtra pelo nome das tags, sem diferenciar maiúsculas (?tag=funny,to-reread)
+	Tags []string `json:"tag,omitempty"`
+	// CreatedFrom e CreatedTo aceitam datas (2024-01-31) ou RFC 3339; uma data
+	// em CreatedTo inclui o dia inteiro
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	Search      string     `json:"search,omitempty"`
+	// SearchIn aceita "text" (padrão) ou "all", que inclui título e autores
+	SearchIn      string `json:"search_in,omitempty" validate:"omitempty,oneof=text all"`
+	MinLength     *int   `json:"min_length,omitempty"`
+	MaxLength     *int   `json:"max_length,omitempty"`
+	PublishedFrom *int   `json:"published_from,omitempty"`
+	PublishedTo   *int   `json:"published_to,omitempty"`
+	// Favorite lista só as favoritas (?favorite=true); MinRating deixa de
+	// fora as citações sem avaliação
+	Favorite  bool `json:"favorite,omitempty"`
+	MinRating *int `json:"min_rating,omitempty" validate:"omitempty,min=1,max=5"`
+	// OrderBy aceita "created_at" (padrão), "updated_at", "book_title",
+	// "location", que lista as citações de cada livro em ordem de leitura,
+	// "random" e "relevance" (padrão quando há busca)
+	OrderBy string `json:"order_by,omitempty" validate:"omitempty,oneof=created_at updated_at book_title location random relevance"`
 }
 
 type ListQuotesResponse struct {
 	Quotes []QuoteResponse `json:"quotes"`
-	Total  int             `json:"total"`
-	Limit  int             `json:"limit"`
-	Offset int             `json:"offset"`
+	// Total conta todas as citações que atendem aos filtros, não só as da página
+	Total   int  `json:"total"`
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"has_more"`
+	// NextCursor e PrevCursor levam às páginas vizinhas via ?cursor=; ficam
+	// vazios na ordenação aleatória ou por relevância
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// RandomQuotesResponse é a resposta de GET /quotes/random com ?count=
+type RandomQuotesResponse struct {
+	Quotes []QuoteResponse `json:"quotes"`
+}
+
+type QuoteSearchResultResponse struct {
+	Quote QuoteResponse `json:"quote"`
+	// Snippet é o trecho do texto, escapado como HTML, com os termos
+	// encontrados entre <mark> e </mark>
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+type SearchQuotesResponse struct {
+	Query   string                      `json:"query"`
+	Results []QuoteSearchResultResponse `json:"results"`
+	Total   int                         `json:"total"`
+	Limit   int                         `json:"limit"`
+	Offset  int                         `json:"offset"`
 }
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type TagResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// QuoteCount é a quantidade de citações com a tag
+	QuoteCount int       `json:"quote_count"`
+	Created    time.Time `json:"created"`
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateTagRequest struct {
+	Name string `json:"name"`
+}
+
+// AddQuoteTagRequest aplica uma tag pelo nome, criando-a se necessário
+type AddQuoteTagRequest struct {
+	Name string `json:"name"`
+}
+
+type TagSimple struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type ListTagsResponse struct {
+	Tags   []TagResponse `json:"tags"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
@@ -0,0 +1,302 @@
+package exporter
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"quote-api/models"
+	"quote-api/repository"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultAnkiDeck é o baralho raiz quando AnkiOptions.Deck está vazio
+const DefaultAnkiDeck = "Kindle"
+
+// AnkiOptions configura a exportação para o Anki
+type AnkiOptions struct {
+	// Deck é o baralho raiz. As citações vão para um sub-baralho com a
+	// primeira categoria do livro (Kindle::Filosofia); as de livros sem
+	// categoria ficam no próprio Deck
+	Deck string
+	// Cloze gera cartões de omissão: cada frase da citação vira uma lacuna e,
+	// nas citações de uma frase só, a segunda metade. Sem Cloze, a frente do
+	// cartão é a citação e o verso, o livro e os autores
+	Cloze bool
+	// Query escolhe as citações; a ordenação e a paginação são ignoradas.
+	// Notas soltas do Kindle (kind note) nunca são exportadas
+	Query repository.QuoteQuery
+}
+
+// AnkiSummary resume uma exportação para o Anki
+type AnkiSummary struct {
+	Notes int
+	Cards int
+	Decks int
+}
+
+// AnkiExporter gera um pacote do Anki (.apkg) ou um arquivo de texto separado
+// por tabulações com uma nota por citação. O GUID de cada nota vem do ID da
+// citação, então importar de novo atualiza as notas em vez de duplicá-las, e
+// o progresso de estudo dos cartões é mantido
+type AnkiExporter struct {
+	bookRepo  repository.Books
+	quoteRepo repository.Quotes
+	options   AnkiOptions
+}
+
+func NewAnkiExporter(bookRepo repository.Books, quoteRepo repository.Quotes, options AnkiOptions) *AnkiExporter {
+	if options.Deck == "" {
+		options.Deck = DefaultAnkiDeck
+	}
+	return &AnkiExporter{bookRepo: bookRepo, quoteRepo: quoteRepo, options: options}
+}
+
+// ExportFile grava em path um pacote .apkg ou, para qualquer outra extensão
+// (.txt, .tsv), o arquivo de texto
+func (e *AnkiExporter) ExportFile(path string) (*AnkiSummary, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary *AnkiSummary
+	if strings.EqualFold(filepath.Ext(path), ".apkg") {
+		summary, err = e.ExportPackage(file)
+	} else {
+		summary, err = e.ExportText(file)
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return summary, nil
+}
+
+// ExportText grava as notas no formato de importação de texto do Anki (2.1.55
+// ou mais recente), com cabeçalhos que indicam o tipo de nota, o baralho, o
+// GUID e as tags de cada linha. Usa os tipos de nota padrão do Anki: Basic
+// (Front, Back) ou, com Cloze, Cloze (Text, Back Extra)
+func (e *AnkiExporter) ExportText(w io.Writer) (*AnkiSummary, error) {
+	notes, err := e.loadNotes()
+	if err != nil {
+		return nil, err
+	}
+
+	noteType := "Basic"
+	if e.options.Cloze {
+		noteType = "Cloze"
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprint(out, "#separator:tab\n#html:true\n")
+	fmt.Fprintf(out, "#notetype:%s\n#guid column:1\n#deck column:2\n#tags column:5\n", noteType)
+
+	for _, note := range notes {
+		var lines []string
+		for _, line := range []string{note.book, note.authors, note.location} {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		back := strings.Join(lines, "<br>")
+		if note.notes != "" {
+			back += "<br><br>" + note.notes
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", note.guid, note.deck, note.text, back, strings.Join(note.tags, " "))
+	}
+
+	if err := out.Flush(); err != nil {
+		return nil, err
+	}
+	return summarize(notes), nil
+}
+
+// ankiNote é uma citação pronta para o Anki. Os campos já estão em HTML, sem
+// tabulações nem quebras de linha
+type ankiNote struct {
+	guid     string
+	deck     string
+	text     string
+	book     string
+	authors  string
+	location string
+	notes    string
+	tags     []string
+	// cards é a quantidade de cartões: 1, ou uma por lacuna no modo Cloze
+	cards int
+}
+
+// fields segue a ordem dos campos dos tipos de nota de ankiModel
+func (n ankiNote) fields() []string {
+	return []string{n.text, n.book, n.authors, n.location, n.notes}
+}
+
+// loadNotes lê as citações de Query livro a livro, em ordem de leitura
+func (e *AnkiExporter) loadNotes() ([]ankiNote, error) {
+	books, err := e.bookRepo.FindAll(-1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []ankiNote
+	err = eachBookQuotes(books, e.quoteRepo, e.options.Query, func(_ models.Book, quotes []models.Quote) error {
+		for _, quote := range quotes {
+			if quote.Kind != models.QuoteKindNote && quote.Book != nil {
+				notes = append(notes, e.note(quote))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (e *AnkiExporter) note(quote models.Quote) ankiNote {
+	book := quote.Book
+
+	deck := e.options.Deck
+	if len(book.Categories) > 0 {
+		deck += "::" + ankiDeckName(book.Categories[0].Name)
+	}
+
+	note := ankiNote{
+		guid:     fmt.Sprintf("quote-%d", quote.ID),
+		deck:     deck,
+		text:     ankiHTML(quote.Text),
+		book:     ankiHTML(book.Title),
+		authors:  ankiHTML(strings.Join(authorNames(book.Authors), ", ")),
+		location: ankiHTML(quotePosition(quote)),
+		cards:    1,
+	}
+
+	if e.options.Cloze {
+		note.text, note.cards = clozeText(quote.Text)
+	}
+
+	var annotations []string
+	for _, annotation := range quote.Annotations {
+		annotations = append(annotations, ankiHTML(annotation.Text))
+	}
+	note.notes = strings.Join(annotations, "<br>")
+
+	for _, tag := range quote.Tags {
+		note.tags = append(note.tags, strings.Join(strings.Fields(tag.Name), "_"))
+	}
+	if quote.Favorite {
+		note.tags = append(note.tags, "favorite")
+	}
+
+	return note
+}
+
+func summarize(notes []ankiNote) *AnkiSummary {
+	summary := &AnkiSummary{Notes: len(notes)}
+	decks := make(map[string]bool)
+	for _, note := range notes {
+		summary.Cards += note.cards
+		decks[note.deck] = true
+	}
+	summary.Decks = len(decks)
+	return summary
+}
+
+// ankiHTML escapa o texto para um campo do Anki, com as quebras de linha como
+// <br> e sem tabulações, que separam as colunas do arquivo de texto
+func ankiHTML(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\t", " ")
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// ankiDeckName evita que "::" no nome da categoria crie outro nível de baralho
+func ankiDeckName(name string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(name), " "), "::", ":")
+}
+
+// sentenceEnd casa com o fim de uma frase e os espaços que a seguem
+var sentenceEnd = regexp.MustCompile(`[.!?…]+["'”’»)\]]*\s+`)
+
+// clozeText marca as lacunas do modo Cloze e retorna o campo e a quantidade
+// de lacunas. Chaves e dois-pontos viram entidades HTML para não se
+// confundirem com a sintaxe {{c1::texto}}
+func clozeText(text string) (string, int) {
+	text = strings.TrimSpace(text)
+
+	var parts []string
+	start := 0
+	for _, match := range sentenceEnd.FindAllStringIndex(text, -1) {
+		parts = append(parts, text[start:match[1]])
+		start = match[1]
+	}
+	if start < len(text) {
+		parts = append(parts, text[start:])
+	}
+
+	if len(parts) == 1 {
+		words := strings.Fields(text)
+		if len(words) > 1 {
+			visible := strings.Join(words[:(len(words)+1)/2], " ")
+			hidden := strings.Join(words[(len(words)+1)/2:], " ")
+			return clozeEscape(visible) + " {{c1::" + clozeEscape(hidden) + "}}", 1
+		}
+	}
+
+	var b strings.Builder
+	for i, part := range parts {
+		trimmed := strings.TrimRightFunc(part, unicode.IsSpace)
+		fmt.Fprintf(&b, "{{c%d::%s}}%s", i+1, clozeEscape(trimmed), ankiSpace(part[len(trimmed):]))
+	}
+	return b.String(), len(parts)
+}
+
+func clozeEscape(text string) string {
+	return strings.NewReplacer("{", "&#123;", "}", "&#125;", ":", "&#58;").Replace(ankiHTML(text))
+}
+
+// ankiSpace converte os espaços entre as frases, mantendo as quebras de linha
+func ankiSpace(space string) string {
+	if strings.Contains(space, "\n") {
+		return strings.Repeat("<br>", strings.Count(space, "\n"))
+	}
+	if space == "" {
+		return ""
+	}
+	return " "
+}
+
+// htmlTag casa com as tags removidas de um campo para o checksum e a ordenação
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// fieldChecksum é o checksum que o Anki guarda do primeiro campo da nota para
+// encontrar duplicatas: os 8 primeiros dígitos hexadecimais do SHA-1 do texto
+// sem HTML
+func fieldChecksum(field string) int64 {
+	sum := sha1.Sum([]byte(stripHTML(field)))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+func stripHTML(field string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(field, ""))
+}
+
+// stableID deriva um ID do Anki, que é um inteiro positivo, de uma chave. Os
+// IDs ficam abaixo de 2^52 para sobreviver a leitores de JSON que usam float64
+func stableID(key string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return int64(hash.Sum64()>>12) + 1
+}
@@ -0,0 +1,293 @@
+package exporter
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ankiSchema é o schema da coleção do Anki (versão 11), o que os pacotes .apkg
+// trazem em collection.anki2 e que todas as versões do Anki importam
+var ankiSchema = []string{
+	`CREATE TABLE col (
+        id integer PRIMARY KEY, crt integer NOT NULL, mod integer NOT NULL, scm integer NOT NULL,
+        ver integer NOT NULL, dty integer NOT NULL, usn integer NOT NULL, ls integer NOT NULL,
+        conf text NOT NULL, models text NOT NULL, decks text NOT NULL, dconf text NOT NULL, tags text NOT NULL
+    )`,
+	`CREATE TABLE notes (
+        id integer PRIMARY KEY, guid text NOT NULL, mid integer NOT NULL, mod integer NOT NULL,
+        usn integer NOT NULL, tags text NOT NULL, flds text NOT NULL, sfld integer NOT NULL,
+        csum integer NOT NULL, flags integer NOT NULL, data text NOT NULL
+    )`,
+	`CREATE TABLE cards (
+        id integer PRIMARY KEY, nid integer NOT NULL, did integer NOT NULL, ord integer NOT NULL,
+        mod integer NOT NULL, usn integer NOT NULL, type integer NOT NULL, queue integer NOT NULL,
+        due integer NOT NULL, ivl integer NOT NULL, factor integer NOT NULL, reps integer NOT NULL,
+        lapses integer NOT NULL, left integer NOT NULL, odue integer NOT NULL, odid integer NOT NULL,
+        flags integer NOT NULL, data text NOT NULL
+    )`,
+	`CREATE TABLE revlog (
+        id integer PRIMARY KEY, cid integer NOT NULL, usn integer NOT NULL, ease integer NOT NULL,
+        ivl integer NOT NULL, lastIvl integer NOT NULL, factor integer NOT NULL, time integer NOT NULL,
+        type integer NOT NULL
+    )`,
+	"CREATE TABLE graves (usn integer NOT NULL, oid integer NOT NULL, type integer NOT NULL)",
+	"CREATE INDEX ix_notes_usn ON notes (usn)",
+	"CREATE INDEX ix_cards_usn ON cards (usn)",
+	"CREATE INDEX ix_revlog_usn ON revlog (usn)",
+	"CREATE INDEX ix_cards_nid ON cards (nid)",
+	"CREATE INDEX ix_cards_sched ON cards (did, queue, due)",
+	"CREATE INDEX ix_revlog_cid ON revlog (cid)",
+	"CREATE INDEX ix_notes_csum ON notes (csum)",
+}
+
+// ankiFields são os campos dos tipos de nota do pacote, na ordem de
+// ankiNote.fields
+var ankiFields = []string{"Text", "Book", "Authors", "Location", "Notes"}
+
+const ankiCSS = `.card { font-family: Georgia, serif; font-size: 22px; text-align: center; color: black; background-color: white; }
+.book { font-style: italic; }
+.authors, .location, .notes { font-size: 16px; }
+.location { color: #777; }
+.cloze { font-weight: bold; color: blue; }`
+
+const ankiBack = `<hr id=answer>
+<div class="book">{{Book}}</div>
+<div class="authors">{{Authors}}</div>
+{{#Location}}<div class="location">{{Location}}</div>{{/Location}}
+{{#Notes}}<div class="notes">{{Notes}}</div>{{/Notes}}`
+
+// ankiModel descreve o tipo de nota do pacote. Os tipos têm nomes e IDs
+// próprios para não alterar os tipos Basic e Cloze da coleção de quem importa
+func ankiModel(cloze bool, deckID, now int64) (int64, map[string]interface{}) {
+	name := "Kindle Quote"
+	kind := 0
+	template := map[string]interface{}{
+		"name": "Card 1", "ord": 0, "did": nil, "bqfmt": "", "bafmt": "",
+		"qfmt": "{{Text}}",
+		"afmt": "{{FrontSide}}\n" + ankiBack,
+	}
+	if cloze {
+		name = "Kindle Quote (cloze)"
+		kind = 1
+		template["name"] = "Cloze"
+		template["qfmt"] = "{{cloze:Text}}"
+		template["afmt"] = "{{cloze:Text}}\n" + ankiBack
+	}
+
+	fields := make([]map[string]interface{}, len(ankiFields))
+	for i, field := range ankiFields {
+		fields[i] = map[string]interface{}{
+			"name": field, "ord": i, "sticky": false, "rtl": false,
+			"font": "Arial", "size": 20, "media": []string{},
+		}
+	}
+
+	id := stableID("model:" + name)
+	model := map[string]interface{}{
+		"id":        strconv.FormatInt(id, 10),
+		"name":      name,
+		"type":      kind,
+		"mod":       now,
+		"usn":       -1,
+		"sortf":     0,
+		"did":       deckID,
+		"tmpls":     []interface{}{template},
+		"flds":      fields,
+		"css":       ankiCSS,
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"tags":      []string{},
+		"vers":      []interface{}{},
+	}
+	if !cloze {
+		// o cartão existe sempre que Text estiver preenchido
+		model["req"] = []interface{}{[]interface{}{0, "any", []int{0}}}
+	}
+	return id, model
+}
+
+func ankiDeck(id int64, name string, now int64) map[string]interface{} {
+	return map[string]interface{}{
+		"id": id, "name": name, "mod": now, "usn": -1, "desc": "",
+		"dyn": 0, "conf": 1, "collapsed": false,
+		"extendNew": 10, "extendRev": 50,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0},
+		"lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+const ankiDeckConf = `{"1": {"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true, "timer": 0, "replayq": true, "dyn": false,
+ "new": {"bury": true, "delays": [1, 10], "initialFactor": 2500, "ints": [1, 4, 7], "order": 1, "perDay": 20, "separate": true},
+ "lapse": {"delays": [10], "leechAction": 0, "leechFails": 8, "minInt": 1, "mult": 0},
+ "rev": {"bury": true, "ease4": 1.3, "fuzz": 0.05, "ivlFct": 1, "maxIvl": 36500, "minSpace": 1, "perDay": 100}}}`
+
+// ExportPackage grava um pacote .apkg: um zip com a coleção collection.anki2,
+// um banco SQLite criado em um arquivo temporário, e o índice de mídia vazio.
+// Cada categoria vira um baralho, e os cartões novos seguem a ordem de leitura
+func (e *AnkiExporter) ExportPackage(w io.Writer) (*AnkiSummary, error) {
+	notes, err := e.loadNotes()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp("", "quotes-*.anki2")
+	if err != nil {
+		return nil, err
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	if err := e.writeCollection(path, notes); err != nil {
+		return nil, err
+	}
+
+	archive := zip.NewWriter(w)
+	if err := addZipFile(archive, "collection.anki2", path); err != nil {
+		return nil, err
+	}
+	media, err := archive.CreateHeader(&zip.FileHeader{Name: "media", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := media.Write([]byte("{}")); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return summarize(notes), nil
+}
+
+func (e *AnkiExporter) writeCollection(path string, notes []ankiNote) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range ankiSchema {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	seconds, millis := now.Unix(), now.UnixMilli()
+
+	// o Default (ID 1) precisa existir em toda coleção; o baralho raiz é
+	// incluído para que os sub-baralhos não dependam dele já existir
+	deckIDs := map[string]int64{e.options.Deck: stableID("deck:" + e.options.Deck)}
+	for _, note := range notes {
+		if _, ok := deckIDs[note.deck]; !ok {
+			deckIDs[note.deck] = stableID("deck:" + note.deck)
+		}
+	}
+
+	decks := map[string]interface{}{"1": ankiDeck(1, "Default", seconds)}
+	names := make([]string, 0, len(deckIDs))
+	for name := range deckIDs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		decks[strconv.FormatInt(deckIDs[name], 10)] = ankiDeck(deckIDs[name], name, seconds)
+	}
+
+	modelID, model := ankiModel(e.options.Cloze, deckIDs[e.options.Deck], seconds)
+	conf := map[string]interface{}{
+		"nextPos": len(notes) + 1, "estTimes": true, "activeDecks": []int{1}, "sortType": "noteFld",
+		"timeLim": 0, "sortBackwards": false, "addToCur": true, "curDeck": 1, "newBury": true,
+		"newSpread": 0, "dueCounts": true, "curModel": strconv.FormatInt(modelID, 10), "collapseTime": 1200,
+	}
+
+	jsonConf, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	jsonModels, err := json.Marshal(map[string]interface{}{strconv.FormatInt(modelID, 10): model})
+	if err != nil {
+		return err
+	}
+	jsonDecks, err := json.Marshal(decks)
+	if err != nil {
+		return err
+	}
+
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Unix()
+	_, err = tx.Exec(
+		"INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')",
+		dayStart, millis, millis, string(jsonConf), string(jsonModels), string(jsonDecks), ankiDeckConf,
+	)
+	if err != nil {
+		return err
+	}
+
+	noteStmt, err := tx.Prepare("INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')")
+	if err != nil {
+		return err
+	}
+	defer noteStmt.Close()
+
+	cardStmt, err := tx.Prepare("INSERT INTO cards VALUES (?, ?, ?, ?, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')")
+	if err != nil {
+		return err
+	}
+	defer cardStmt.Close()
+
+	for position, note := range notes {
+		noteID := stableID("note:" + note.guid)
+		fields := note.fields()
+
+		tags := ""
+		if len(note.tags) > 0 {
+			tags = " " + strings.Join(note.tags, " ") + " "
+		}
+
+		_, err := noteStmt.Exec(noteID, note.guid, modelID, seconds, tags,
+			strings.Join(fields, "\x1f"), stripHTML(fields[0]), fieldChecksum(fields[0]))
+		if err != nil {
+			return err
+		}
+
+		for ord := 0; ord < note.cards; ord++ {
+			cardID := stableID("card:" + note.guid + ":" + strconv.Itoa(ord))
+			if _, err := cardStmt.Exec(cardID, noteID, deckIDs[note.deck], ord, seconds, position+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func addZipFile(archive *zip.Writer, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, file)
+	return err
+}
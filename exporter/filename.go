@@ -0,0 +1,142 @@
+package exporter
+
+import (
+	"fmt"
+	"path"
+	"quote-api/models"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// DefaultFileName gera nomes como "o-nome-da-rosa.md"
+const DefaultFileName = "{{slug .Title}}.md"
+
+// FileNameData são os campos disponíveis no template de nome de arquivo. Os
+// valores não contêm barras, então "/" no template sempre cria subdiretórios
+type FileNameData struct {
+	ID    int64
+	Title string
+	// Author é o primeiro autor; Authors, todos separados por vírgula
+	Author    string
+	Authors   string
+	Year      int
+	ISBN      string
+	Publisher string
+	// Category é a primeira categoria do livro
+	Category string
+}
+
+// FileNameTemplate monta o caminho do arquivo de cada livro, relativo ao
+// diretório de destino
+type FileNameTemplate struct {
+	template *template.Template
+}
+
+// ParseFileName interpreta um text/template com os campos de FileNameData e as
+// funções slug (minúsculas, palavras separadas por hífen) e lower.
+// Ex.: "{{.Author}}/{{.Year}} - {{.Title}}.md"
+func ParseFileName(text string) (*FileNameTemplate, error) {
+	tmpl, err := template.New("filename").Option("missingkey=error").Funcs(template.FuncMap{
+		"slug":  slug,
+		"lower": strings.ToLower,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template de nome de arquivo inválido: %w", err)
+	}
+	return &FileNameTemplate{template: tmpl}, nil
+}
+
+// Execute retorna o caminho do arquivo do livro, com "/" como separador e
+// terminado em ext
+func (t *FileNameTemplate) Execute(book models.Book, ext string) (string, error) {
+	data := FileNameData{
+		ID:        book.ID,
+		Title:     cleanSegment(book.Title),
+		Authors:   cleanSegment(strings.Join(authorNames(book.Authors), ", ")),
+		Year:      book.PublishedYear,
+		ISBN:      cleanSegment(deref(book.ISBN)),
+		Publisher: cleanSegment(deref(book.Publisher)),
+	}
+	if len(book.Authors) > 0 {
+		data.Author = cleanSegment(book.Authors[0].Name)
+	}
+	if len(book.Categories) > 0 {
+		data.Category = cleanSegment(book.Categories[0].Name)
+	}
+
+	var b strings.Builder
+	if err := t.template.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("erro ao gerar o nome do arquivo do livro %d: %w", book.ID, err)
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(b.String(), "/") {
+		segment = cleanSegment(segment)
+		if segment != "" && segment != "." && segment != ".." {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		segments = []string{fmt.Sprint(book.ID)}
+	}
+
+	name := path.Join(segments...)
+	if !strings.HasSuffix(strings.ToLower(name), ext) {
+		name += ext
+	}
+	return name, nil
+}
+
+// cleanSegment troca por hífens os caracteres que não valem em nomes de
+// arquivo no Windows, no macOS ou no Linux, e os que quebram links do
+// Obsidian (#, ^, [ e ]), e colapsa os espaços
+func cleanSegment(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|#^[]`, r) || unicode.IsControl(r) {
+			return '-'
+		}
+		return r
+	}, s)
+	return strings.Trim(strings.Join(strings.Fields(s), " "), " .")
+}
+
+// slug deixa só letras e números, em minúsculas, separados por hífens
+func slug(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// uniquePaths acrescenta o ID ao nome dos arquivos que coincidem, sem
+// diferenciar maiúsculas, para não gravar dois livros no mesmo arquivo. Todos
+// os que coincidem recebem o ID, então o nome não depende da ordem dos livros
+func uniquePaths(paths []string, ids []int64) {
+	count := make(map[string]int, len(paths))
+	for _, p := range paths {
+		count[strings.ToLower(p)]++
+	}
+
+	for i, p := range paths {
+		if count[strings.ToLower(p)] > 1 {
+			ext := path.Ext(p)
+			paths[i] = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(p, ext), ids[i], ext)
+		}
+	}
+}
+
+func authorNames(authors []models.Author) []string {
+	names := make([]string, len(authors))
+	for i, author := range authors {
+		names[i] = author.Name
+	}
+	return names
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,255 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type FileStatus string
+
+const (
+	StatusCreated   FileStatus = "created"
+	StatusUpdated   FileStatus = "updated"
+	StatusUnchanged FileStatus = "unchanged"
+	// StatusRemoved: o arquivo era de um livro que não existe mais, ou que
+	// mudou de nome
+	StatusRemoved FileStatus = "removed"
+	// StatusKept: o livro não existe mais, mas o arquivo tem conteúdo do
+	// usuário e foi mantido; a exportação deixa de gerenciá-lo
+	StatusKept FileStatus = "kept"
+)
+
+// FileResult descreve o que aconteceu com cada arquivo da exportação. Path é
+// relativo ao diretório de destino
+type FileResult struct {
+	Path   string
+	Status FileStatus
+}
+
+// Report resume uma exportação
+type Report struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	Removed   int
+	Kept      int
+	Files     []FileResult
+}
+
+func (r *Report) record(path string, status FileStatus) {
+	switch status {
+	case StatusCreated:
+		r.Created++
+	case StatusUpdated:
+		r.Updated++
+	case StatusUnchanged:
+		r.Unchanged++
+	case StatusRemoved:
+		r.Removed++
+	case StatusKept:
+		r.Kept++
+	}
+
+	r.Files = append(r.Files, FileResult{Path: path, Status: status})
+}
+
+// document é um arquivo a exportar. key identifica a sua origem (um livro, um
+// autor) entre exportações, para que renomear o livro mova o arquivo em vez de
+// deixar o antigo para trás
+type document struct {
+	key     string
+	path    string
+	content []byte
+}
+
+// manifest guarda, no próprio diretório de destino, o arquivo gerado para cada
+// key na última exportação
+type manifest struct {
+	Files map[string]string `json:"files"`
+}
+
+func readManifest(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &manifest{Files: map[string]string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.Files == nil {
+		m.Files = map[string]string{}
+	}
+	for key, path := range m.Files {
+		if !isLocalPath(path) {
+			delete(m.Files, key)
+		}
+	}
+	return &m, nil
+}
+
+// isLocalPath indica se path, relativo ao diretório de destino e com "/" como
+// separador, fica dentro dele. O manifesto é um arquivo comum no diretório, e
+// um caminho como "../x" ou absoluto nele faria a exportação apagar ou mover
+// arquivos fora do destino
+func isLocalPath(path string) bool {
+	return filepath.IsLocal(filepath.FromSlash(path))
+}
+
+// writeDocuments grava os documentos em dir, reescrevendo só os arquivos cujo
+// conteúdo mudou, e remove os arquivos que a exportação anterior gerou e que
+// não fazem mais parte dela. O manifesto, chamado manifestName, fica em dir.
+// Se keep não for nil, os arquivos de keys que sumiram só são removidos quando
+// keep retorna false para o seu conteúdo; os de keys que mudaram de caminho
+// sempre são removidos
+func writeDocuments(dir, manifestName string, documents []document, keep func(content []byte) bool) (*Report, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	manifestPath := filepath.Join(dir, manifestName)
+	previous, err := readManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	current := &manifest{Files: make(map[string]string, len(documents))}
+	inUse := make(map[string]bool, len(documents))
+	for _, doc := range documents {
+		current.Files[doc.key] = doc.path
+		inUse[doc.path] = true
+	}
+
+	report := &Report{}
+
+	for _, doc := range documents {
+		status, err := writeDocument(dir, doc)
+		if err != nil {
+			return nil, err
+		}
+		report.record(doc.path, status)
+	}
+
+	keys := make([]string, 0, len(previous.Files))
+	for key := range previous.Files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := previous.Files[key]
+		if inUse[path] || !isLocalPath(path) {
+			continue
+		}
+		inUse[path] = true
+
+		file := filepath.Join(dir, filepath.FromSlash(path))
+		if _, moved := current.Files[key]; !moved && keep != nil {
+			content, err := os.ReadFile(file)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if keep(content) {
+				report.record(path, StatusKept)
+				continue
+			}
+		}
+
+		err := os.Remove(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		removeEmptyDirs(dir, path)
+		report.record(path, StatusRemoved)
+	}
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(manifestPath, append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// removeEmptyDirs remove os subdiretórios de path que ficaram vazios,
+// de dentro para fora, sem sair de dir
+func removeEmptyDirs(dir, path string) {
+	if !isLocalPath(path) {
+		return
+	}
+	for parent := filepath.Dir(filepath.FromSlash(path)); parent != "."; parent = filepath.Dir(parent) {
+		if os.Remove(filepath.Join(dir, parent)) != nil {
+			return
+		}
+	}
+}
+
+// writeDocument grava doc se o arquivo não existir ou tiver outro conteúdo
+func writeDocument(dir string, doc document) (FileStatus, error) {
+	if !isLocalPath(doc.path) {
+		return "", fmt.Errorf("caminho fora do diretório de destino: %q", doc.path)
+	}
+	path := filepath.Join(dir, filepath.FromSlash(doc.path))
+
+	status := StatusUpdated
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		status = StatusCreated
+	case err != nil:
+		return "", err
+	case bytes.Equal(existing, doc.content):
+		return StatusUnchanged, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := writeFile(path, doc.content); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// writeFile grava em um arquivo temporário e o renomeia, para que uma
+// exportação interrompida não deixe arquivos pela metade
+func writeFile(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
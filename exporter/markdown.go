@@ -0,0 +1,262 @@
+package exporter
+
+import (
+	"fmt"
+	"quote-api/models"
+	"quote-api/repository"
+	"strconv"
+	"strings"
+)
+
+// MarkdownManifest é o arquivo, no diretório de destino, em que o
+// MarkdownExporter lembra quais arquivos gerou
+const MarkdownManifest = ".quotes-export.json"
+
+// MarkdownExporter grava um arquivo Markdown por livro com citações, com os
+// dados do livro no front matter e as citações em ordem de leitura
+type MarkdownExporter struct {
+	bookRepo  repository.Books
+	quoteRepo repository.Quotes
+	fileName  *FileNameTemplate
+}
+
+// NewMarkdownExporter cria o exportador; fileName nil usa DefaultFileName
+func NewMarkdownExporter(bookRepo repository.Books, quoteRepo repository.Quotes, fileName *FileNameTemplate) *MarkdownExporter {
+	if fileName == nil {
+		fileName, _ = ParseFileName(DefaultFileName)
+	}
+	return &MarkdownExporter{bookRepo: bookRepo, quoteRepo: quoteRepo, fileName: fileName}
+}
+
+// Export grava os arquivos em dir. Reexportar para o mesmo diretório só
+// reescreve os livros cujo conteúdo mudou, move os arquivos dos livros que
+// mudaram de nome e remove os dos livros que não existem mais ou ficaram sem
+// citações
+func (e *MarkdownExporter) Export(dir string) (*Report, error) {
+	books, err := loadBooks(e.bookRepo, e.quoteRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(books))
+	ids := make([]int64, len(books))
+	for i, book := range books {
+		if paths[i], err = e.fileName.Execute(book.Book, ".md"); err != nil {
+			return nil, err
+		}
+		ids[i] = book.ID
+	}
+	uniquePaths(paths, ids)
+
+	documents := make([]document, len(books))
+	for i, book := range books {
+		documents[i] = document{
+			key:     fmt.Sprintf("book:%d", book.ID),
+			path:    paths[i],
+			content: []byte(renderMarkdown(book)),
+		}
+	}
+
+	return writeDocuments(dir, MarkdownManifest, documents, nil)
+}
+
+// bookQuotes é um livro com as suas citações em ordem de leitura
+type bookQuotes struct {
+	models.Book
+	Quotes []models.Quote
+}
+
+// pageSize é a quantidade de citações lida por query
+const pageSize = 500
+
+// booksPerQuery é a quantidade de livros cujas citações são lidas juntas
+const booksPerQuery = 100
+
+// loadBooks lê os livros, com autores e categorias, e as citações de cada um
+// com as anotações. Livros sem citações ficam de fora
+func loadBooks(bookRepo repository.Books, quoteRepo repository.Quotes) ([]bookQuotes, error) {
+	books, err := bookRepo.FindAll(-1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []bookQuotes
+	err = eachBookQuotes(books, quoteRepo, repository.QuoteQuery{}, func(book models.Book, quotes []models.Quote) error {
+		result = append(result, bookQuotes{Book: book, Quotes: quotes})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// eachBookQuotes chama fn, na ordem de books, para cada livro com citações que
+// atendem a query, com as citações em ordem de leitura. As citações são lidas
+// booksPerQuery livros por vez, em páginas de pageSize: ler todas de uma vez
+// passaria do limite de variáveis do SQLite ao carregar tags e anotações, e
+// seguir o cursor pela tabela inteira a ordenaria de novo a cada página. A
+// ordenação e a paginação de query são ignoradas
+func eachBookQuotes(books []models.Book, quoteRepo repository.Quotes, query repository.QuoteQuery,
+	fn func(book models.Book, quotes []models.Quote) error) error {
+	if len(query.BookIDs) > 0 {
+		wanted := make(map[int64]bool, len(query.BookIDs))
+		for _, id := range query.BookIDs {
+			wanted[id] = true
+		}
+		var filtered []models.Book
+		for _, book := range books {
+			if wanted[book.ID] {
+				filtered = append(filtered, book)
+			}
+		}
+		books = filtered
+	}
+
+	query.Order = repository.QuoteOrderLocation
+	query.Limit = pageSize
+	query.Offset = 0
+
+	for start := 0; start < len(books); start += booksPerQuery {
+		chunk := books[start:min(start+booksPerQuery, len(books))]
+		query.BookIDs = make([]int64, len(chunk))
+		for i, book := range chunk {
+			query.BookIDs[i] = book.ID
+		}
+
+		byBook := make(map[int64][]models.Quote)
+		query.Cursor = nil
+		for {
+			page, err := quoteRepo.FindPageByQuery(query)
+			if err != nil {
+				return err
+			}
+			for _, quote := range page.Items {
+				byBook[quote.BookID] = append(byBook[quote.BookID], quote)
+			}
+			if page.Next == nil {
+				break
+			}
+			query.Cursor = page.Next
+		}
+
+		for _, book := range chunk {
+			if quotes := byBook[book.ID]; len(quotes) > 0 {
+				if err := fn(book, quotes); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func renderMarkdown(book bookQuotes) string {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	writeFrontMatter(&b, book.Book)
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n", book.Title)
+	if len(book.Authors) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(authorNames(book.Authors), ", "))
+	}
+
+	for _, quote := range book.Quotes {
+		b.WriteString("\n")
+		writeQuoteText(&b, quote)
+		if position := quotePosition(quote); position != "" {
+			fmt.Fprintf(&b, "\n%s\n", position)
+		}
+		writeAnnotations(&b, quote)
+	}
+
+	return b.String()
+}
+
+// writeFrontMatter escreve os dados do livro em YAML. As strings vão entre
+// aspas duplas com os escapes do Go, que o YAML também aceita
+func writeFrontMatter(b *strings.Builder, book models.Book) {
+	fmt.Fprintf(b, "book_id: %d\n", book.ID)
+	fmt.Fprintf(b, "title: %s\n", strconv.Quote(book.Title))
+	writeYAMLList(b, "authors", authorNames(book.Authors))
+	if book.ISBN != nil {
+		fmt.Fprintf(b, "isbn: %s\n", strconv.Quote(*book.ISBN))
+	}
+	if book.Publisher != nil {
+		fmt.Fprintf(b, "publisher: %s\n", strconv.Quote(*book.Publisher))
+	}
+	if book.PublishedYear > 0 {
+		fmt.Fprintf(b, "published_year: %d\n", book.PublishedYear)
+	}
+	writeYAMLList(b, "categories", categoryNames(book.Categories))
+}
+
+func writeYAMLList(b *strings.Builder, key string, values []string) {
+	if len(values) == 0 {
+		fmt.Fprintf(b, "%s: []\n", key)
+		return
+	}
+
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, value := range values {
+		fmt.Fprintf(b, "  - %s\n", strconv.Quote(value))
+	}
+}
+
+// writeQuoteText escreve os destaques como citação em bloco; notas soltas do
+// Kindle, sem destaque, viram um parágrafo
+func writeQuoteText(b *strings.Builder, quote models.Quote) {
+	if quote.Kind == models.QuoteKindNote {
+		fmt.Fprintf(b, "**Nota:** %s\n", strings.Join(strings.Fields(quote.Text), " "))
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(quote.Text), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			b.WriteString(">\n")
+			continue
+		}
+		fmt.Fprintf(b, "> %s\n", line)
+	}
+}
+
+// quotePosition descreve a posição e a página da citação, como "Posição
+// 120-125 · Página 4"
+func quotePosition(quote models.Quote) string {
+	var parts []string
+	if quote.LocationStart != nil {
+		location := fmt.Sprintf("Posição %d", *quote.LocationStart)
+		if quote.LocationEnd != nil && *quote.LocationEnd != *quote.LocationStart {
+			location += fmt.Sprintf("-%d", *quote.LocationEnd)
+		}
+		parts = append(parts, location)
+	}
+	if quote.Page != nil {
+		parts = append(parts, fmt.Sprintf("Página %d", *quote.Page))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func writeAnnotations(b *strings.Builder, quote models.Quote) {
+	if len(quote.Annotations) == 0 {
+		return
+	}
+
+	b.WriteString("\n")
+	for _, annotation := range quote.Annotations {
+		fmt.Fprintf(b, "- **Nota:** %s\n", strings.Join(strings.Fields(annotation.Text), " "))
+	}
+}
+
+func categoryNames(categories []models.Category) []string {
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = category.Name
+	}
+	return names
+}
@@ -0,0 +1,298 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"quote-api/models"
+	"quote-api/repository"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ObsidianManifest é o arquivo, na pasta de destino, em que o
+// ObsidianExporter lembra quais notas gerou
+const ObsidianManifest = ".quotes-obsidian.json"
+
+// DefaultObsidianFileName guarda as notas dos livros em Livros/, com o título
+// como nome, que é como o Obsidian mostra os links
+const DefaultObsidianFileName = "Livros/{{.Title}}.md"
+
+// authorFolder é a pasta das notas dos autores
+const authorFolder = "Autores"
+
+// MyNotesHeading abre a seção das notas pessoais, sempre no final da nota. Tudo
+// o que vier depois dela é mantido quando a nota é gerada de novo
+const MyNotesHeading = "## Minhas notas"
+
+// ObsidianExporter grava um vault do Obsidian com uma nota por livro com
+// citações e uma por autor desses livros, ligadas por wikilinks
+type ObsidianExporter struct {
+	bookRepo  repository.Books
+	quoteRepo repository.Quotes
+	fileName  *FileNameTemplate
+}
+
+// NewObsidianExporter cria o exportador; fileName nil usa
+// DefaultObsidianFileName para as notas dos livros
+func NewObsidianExporter(bookRepo repository.Books, quoteRepo repository.Quotes, fileName *FileNameTemplate) *ObsidianExporter {
+	if fileName == nil {
+		fileName, _ = ParseFileName(DefaultObsidianFileName)
+	}
+	return &ObsidianExporter{bookRepo: bookRepo, quoteRepo: quoteRepo, fileName: fileName}
+}
+
+// Export sincroniza as notas em dir, que pode ser o vault ou uma pasta dele.
+// Como na exportação em Markdown, só as notas que mudaram são reescritas e as
+// notas de livros e autores renomeados são movidas, levando as notas pessoais
+// junto. Notas de livros e autores que não existem mais só são apagadas se a
+// seção MyNotesHeading estiver vazia
+func (e *ObsidianExporter) Export(dir string) (*Report, error) {
+	books, err := loadBooks(e.bookRepo, e.quoteRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	bookPaths := make([]string, len(books))
+	bookIDs := make([]int64, len(books))
+	for i, book := range books {
+		if bookPaths[i], err = e.fileName.Execute(book.Book, ".md"); err != nil {
+			return nil, err
+		}
+		bookIDs[i] = book.ID
+	}
+	uniquePaths(bookPaths, bookIDs)
+
+	authors := vaultAuthors(books)
+	authorPaths := make([]string, len(authors))
+	authorIDs := make([]int64, len(authors))
+	for i, author := range authors {
+		authorPaths[i] = path.Join(authorFolder, cleanSegment(author.Name)+".md")
+		authorIDs[i] = author.ID
+	}
+	uniquePaths(authorPaths, authorIDs)
+
+	links := vaultLinks{books: map[int64]string{}, authors: map[int64]string{}}
+	for i, book := range books {
+		links.books[book.ID] = wikilink(bookPaths[i], book.Title)
+	}
+	for i, author := range authors {
+		links.authors[author.ID] = wikilink(authorPaths[i], author.Name)
+	}
+
+	var documents []document
+	for i, book := range books {
+		documents = append(documents, document{
+			key:     fmt.Sprintf("book:%d", book.ID),
+			path:    bookPaths[i],
+			content: []byte(renderBookNote(book, links)),
+		})
+	}
+	for i, author := range authors {
+		documents = append(documents, document{
+			key:     fmt.Sprintf("author:%d", author.ID),
+			path:    authorPaths[i],
+			content: []byte(renderAuthorNote(author, links)),
+		})
+	}
+
+	if err := keepMyNotes(dir, documents); err != nil {
+		return nil, err
+	}
+
+	return writeDocuments(dir, ObsidianManifest, documents, func(content []byte) bool {
+		return strings.TrimSpace(myNotes(string(content))) != ""
+	})
+}
+
+// vaultAuthor é um autor com os livros exportados dele
+type vaultAuthor struct {
+	models.Author
+	Books []models.Book
+}
+
+// vaultAuthors reúne os autores dos livros, ordenados pelo nome
+func vaultAuthors(books []bookQuotes) []vaultAuthor {
+	byID := make(map[int64]*vaultAuthor)
+	for _, book := range books {
+		for _, author := range book.Authors {
+			if byID[author.ID] == nil {
+				byID[author.ID] = &vaultAuthor{Author: author}
+			}
+			byID[author.ID].Books = append(byID[author.ID].Books, book.Book)
+		}
+	}
+
+	authors := make([]vaultAuthor, 0, len(byID))
+	for _, author := range byID {
+		authors = append(authors, *author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		a, b := strings.ToLower(authors[i].Name), strings.ToLower(authors[j].Name)
+		if a != b {
+			return a < b
+		}
+		return authors[i].ID < authors[j].ID
+	})
+	return authors
+}
+
+// vaultLinks guarda o wikilink da nota de cada livro e de cada autor
+type vaultLinks struct {
+	books   map[int64]string
+	authors map[int64]string
+}
+
+// wikilink aponta para a nota pelo caminho, sem a extensão, e mostra o nome.
+// O caminho evita ambiguidade entre um livro e um autor de mesmo nome
+func wikilink(notePath, name string) string {
+	target := strings.TrimSuffix(notePath, ".md")
+	name = strings.NewReplacer("|", "-", "[", "(", "]", ")").Replace(name)
+	if target == name {
+		return "[[" + target + "]]"
+	}
+	return "[[" + target + "|" + name + "]]"
+}
+
+func renderBookNote(book bookQuotes, links vaultLinks) string {
+	authorLinks := make([]string, len(book.Authors))
+	for i, author := range book.Authors {
+		authorLinks[i] = links.authors[author.ID]
+	}
+
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "book_id: %d\n", book.ID)
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(book.Title))
+	writeYAMLList(&b, "authors", authorLinks)
+	if book.ISBN != nil {
+		fmt.Fprintf(&b, "isbn: %s\n", strconv.Quote(*book.ISBN))
+	}
+	if book.Publisher != nil {
+		fmt.Fprintf(&b, "publisher: %s\n", strconv.Quote(*book.Publisher))
+	}
+	if book.PublishedYear > 0 {
+		fmt.Fprintf(&b, "published_year: %d\n", book.PublishedYear)
+	}
+	writeYAMLList(&b, "tags", categoryTags(book.Categories))
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n", book.Title)
+	if len(authorLinks) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(authorLinks, ", "))
+	}
+
+	b.WriteString("\n## Citações\n")
+	for _, quote := range book.Quotes {
+		b.WriteString("\n")
+		writeQuoteText(&b, quote)
+		// Em citações em bloco, o Obsidian exige o ID do bloco em uma linha
+		// própria, logo depois dela
+		fmt.Fprintf(&b, "\n^quote-%d\n", quote.ID)
+		if position := quotePosition(quote); position != "" {
+			fmt.Fprintf(&b, "\n%s\n", position)
+		}
+		writeAnnotations(&b, quote)
+	}
+
+	fmt.Fprintf(&b, "\n%s\n", MyNotesHeading)
+	return b.String()
+}
+
+func renderAuthorNote(author vaultAuthor, links vaultLinks) string {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "author_id: %d\n", author.ID)
+	fmt.Fprintf(&b, "name: %s\n", strconv.Quote(author.Name))
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n## Livros\n\n", author.Name)
+	for _, book := range author.Books {
+		fmt.Fprintf(&b, "- %s", links.books[book.ID])
+		if book.PublishedYear > 0 {
+			fmt.Fprintf(&b, " (%d)", book.PublishedYear)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\n%s\n", MyNotesHeading)
+	return b.String()
+}
+
+// categoryTags converte as categorias em tags do Obsidian, que não aceitam
+// espaços: "Ficção científica" vira "ficção-científica"
+func categoryTags(categories []models.Category) []string {
+	var tags []string
+	for _, category := range categories {
+		if tag := slug(category.Name); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// keepMyNotes acrescenta a cada documento as notas pessoais da versão gravada.
+// A versão gravada é a do caminho registrado no manifesto, que pode ser outro
+// se o livro ou o autor mudou de nome, ou a do próprio caminho do documento
+func keepMyNotes(dir string, documents []document) error {
+	previous, err := readManifest(filepath.Join(dir, ObsidianManifest))
+	if err != nil {
+		return err
+	}
+
+	for i, doc := range documents {
+		candidates := []string{doc.path}
+		if old, ok := previous.Files[doc.key]; ok && old != doc.path {
+			candidates = []string{old, doc.path}
+		}
+
+		for _, candidate := range candidates {
+			// as notas de fora do vault não são lidas, mesmo que o manifesto
+			// aponte para elas
+			if !isLocalPath(candidate) {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(candidate)))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if notes := myNotes(string(content)); notes != "" {
+				documents[i].content = append(doc.content, notes...)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// myNotes retorna o que vem depois da linha MyNotesHeading, ou "" se a nota não
+// tiver a seção
+func myNotes(content string) string {
+	for offset := 0; offset < len(content); {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content) - offset
+		}
+		line := content[offset : offset+end]
+		offset += end + 1
+
+		if strings.TrimRight(line, " \t\r") == MyNotesHeading {
+			if offset >= len(content) {
+				return ""
+			}
+			return content[offset:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"quote-api/dto"
+	"quote-api/models"
+	"quote-api/service"
+)
+
+type AuthorHandler struct {
+	service *service.AuthorService
+}
+
+func NewAuthorHandler(service *service.AuthorService) *AuthorHandler {
+	return &AuthorHandler{service: service}
+}
+
+func (h *AuthorHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /authors", h.List)
+	mux.HandleFunc("POST /authors", h.Create)
+	mux.HandleFunc("GET /authors/{id}", h.Get)
+	mux.HandleFunc("PUT /authors/{id}", h.Update)
+	mux.HandleFunc("DELETE /authors/{id}", h.Delete)
+	mux.HandleFunc("GET /authors/{id}/books", h.ListBooks)
+}
+
+// List lista autores; com ?search= filtra pelo nome
+func (h *AuthorHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	authors, total, err := h.service.Search(r.URL.Query().Get("search"), limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ListAuthorsResponse{
+		Authors: toAuthorResponses(authors),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+func (h *AuthorHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	author, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAuthorResponse(*author))
+}
+
+func (h *AuthorHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAuthorRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	author, err := h.service.Create(models.Author{Name: req.Name})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toAuthorResponse(*author))
+}
+
+func (h *AuthorHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateAuthorRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	author, err := h.service.Update(id, models.Author{Name: req.Name})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAuthorResponse(*author))
+}
+
+func (h *AuthorHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthorHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	books, err := h.service.GetBooksByAuthor(id, limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ListBooksResponse{
+		Books:  toBookResponses(books),
+		Total:  len(books),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
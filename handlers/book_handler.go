@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"net/http"
+	"quote-api/dto"
+	"quote-api/models"
+	"quote-api/repository"
+	"quote-api/service"
+)
+
+type BookHandler struct {
+	service *service.BookService
+}
+
+func NewBookHandler(service *service.BookService) *BookHandler {
+	return &BookHandler{service: service}
+}
+
+func (h *BookHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /books", h.List)
+	mux.HandleFunc("POST /books", h.Create)
+	mux.HandleFunc("GET /books/{id}", h.Get)
+	mux.HandleFunc("PUT /books/{id}", h.Update)
+	mux.HandleFunc("DELETE /books/{id}", h.Delete)
+}
+
+// List lista livros por título. Com ?isbn= retorna só o livro do ISBN; nos
+// demais casos combina ?author_id=, ?category_id= e ?search= e aceita
+// ?cursor= no lugar de ?offset=
+func (h *BookHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if isbn := query.Get("isbn"); isbn != "" {
+		book, err := h.service.GetByISBN(isbn)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ListBooksResponse{
+			Books: []dto.BookResponse{toBookResponse(*book)},
+			Total: 1,
+			Limit: 1,
+		})
+		return
+	}
+
+	authorID, ok := queryID(w, r, "author_id")
+	if !ok {
+		return
+	}
+
+	categoryID, ok := queryID(w, r, "category_id")
+	if !ok {
+		return
+	}
+
+	cursor, ok := queryCursor(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+	if cursor != nil {
+		offset = 0
+	}
+
+	bookQuery := repository.BookQuery{
+		Search: query.Get("search"),
+		Cursor: cursor,
+		Limit:  limit,
+		Offset: offset,
+	}
+	if authorID != nil {
+		bookQuery.AuthorID = *authorID
+	}
+	if categoryID != nil {
+		bookQuery.CategoryID = int(*categoryID)
+	}
+
+	page, total, err := h.service.List(bookQuery)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ListBooksResponse{
+		Books:      toBookResponses(page.Items),
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		HasMore:    page.HasMore,
+		NextCursor: cursorToken(page.Next),
+		PrevCursor: cursorToken(page.Prev),
+	})
+}
+
+func (h *BookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	book, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toBookResponse(*book))
+}
+
+func (h *BookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateBookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	book := models.Book{
+		Title:         req.Title,
+		ISBN:          req.ISBN,
+		PublishedYear: req.PublishedYear,
+		Publisher:     req.Publisher,
+	}
+	if req.Pages != nil {
+		book.Pages = *req.Pages
+	}
+
+	created, err := h.service.Create(book, toInt64s(req.AuthorIDs), req.CategoriesIDs)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toBookResponse(*created))
+}
+
+// Update atualiza os dados do livro e, quando enviados, autores e categorias
+func (h *BookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateBookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	book := models.Book{
+		Title:         req.Title,
+		ISBN:          req.ISBN,
+		PublishedYear: req.PublishedYear,
+		Publisher:     req.Publisher,
+	}
+	if req.Pages != nil {
+		book.Pages = *req.Pages
+	}
+
+	if _, err := h.service.Update(id, book); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if len(req.AuthorIDs) > 0 {
+		if err := h.service.UpdateAuthors(id, toInt64s(req.AuthorIDs)); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	if req.CategoriesIDs != nil {
+		if err := h.service.UpdateCategories(id, req.CategoriesIDs); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	updated, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toBookResponse(*updated))
+}
+
+func (h *BookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
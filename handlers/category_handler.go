@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"quote-api/dto"
+	"quote-api/models"
+	"quote-api/service"
+)
+
+type CategoryHandler struct {
+	service *service.CategoryService
+}
+
+func NewCategoryHandler(service *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: service}
+}
+
+func (h *CategoryHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /categories", h.List)
+	mux.HandleFunc("POST /categories", h.Create)
+	mux.HandleFunc("GET /categories/{id}", h.Get)
+	mux.HandleFunc("PUT /categories/{id}", h.Update)
+	mux.HandleFunc("DELETE /categories/{id}", h.Delete)
+	mux.HandleFunc("GET /categories/{id}/books", h.ListBooks)
+}
+
+// List lista categorias; com ?search= filtra pelo nome
+func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	categories, total, err := h.service.Search(r.URL.Query().Get("search"), limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ListCategoriesResponse{
+		Categories: toCategoryResponses(categories),
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+func (h *CategoryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	category, err := h.service.GetByID(int(id))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toCategoryResponse(*category))
+}
+
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateCategoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	category, err := h.service.Create(models.Category{Name: req.Name})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toCategoryResponse(*category))
+}
+
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCategoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	category, err := h.service.Update(int(id), models.Category{Name: req.Name})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toCategoryResponse(*category))
+}
+
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(int(id)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CategoryHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	books, err := h.service.GetBooksByCategory(int(id), limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ListBooksResponse{
+		Books:  toBookResponses(books),
+		Total:  len(books),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"quote-api/dto"
+	"quote-api/service"
+)
+
+type DailyQuoteHandler struct {
+	service *service.DailyQuoteService
+}
+
+func NewDailyQuoteHandler(service *service.DailyQuoteService) *DailyQuoteHandler {
+	return &DailyQuoteHandler{service: service}
+}
+
+func (h *DailyQuoteHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /quotes/daily", h.Today)
+	mux.HandleFunc("GET /quotes/daily/history", h.History)
+}
+
+// Today retorna a citação do dia, opcionalmente restrita por ?category_id=,
+// ?author_id= e ?tag_id=
+func (h *DailyQuoteHandler) Today(w http.ResponseWriter, r *http.Request) {
+	scope, ok := parseDailyScope(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.Today(scope)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toDailyQuoteResponse(*entry))
+}
+
+// History lista as citações do dia anteriores do mesmo escopo
+func (h *DailyQuoteHandler) History(w http.ResponseWriter, r *http.Request) {
+	scope, ok := parseDailyScope(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.service.History(scope, limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := dto.ListDailyQuotesResponse{
+		Days:   make([]dto.DailyQuoteResponse, 0, len(entries)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, entry := range entries {
+		if entry.Quote != nil {
+			response.Days = append(response.Days, toDailyQuoteResponse(entry))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func parseDailyScope(w http.ResponseWriter, r *http.Request) (service.DailyScope, bool) {
+	var scope service.DailyScope
+
+	categoryID, ok := queryID(w, r, "category_id")
+	if !ok {
+		return scope, false
+	}
+	authorID, ok := queryID(w, r, "author_id")
+	if !ok {
+		return scope, false
+	}
+	tagID, ok := queryID(w, r, "tag_id")
+	if !ok {
+		return scope, false
+	}
+
+	if categoryID != nil {
+		scope.CategoryID = int(*categoryID)
+	}
+	if authorID != nil {
+		scope.AuthorID = *authorID
+	}
+	if tagID != nil {
+		scope.TagID = *tagID
+	}
+
+	return scope, true
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"quote-api/dto"
+	"quote-api/models"
+	"quote-api/repository"
+)
+
+func toAuthorResponse(author models.Author) dto.AuthorResponse {
+	return dto.AuthorResponse{
+		ID:        author.ID,
+		Name:      author.Name,
+		CreatedAt: author.CreatedAt,
+	}
+}
+
+func toAuthorResponses(authors []models.Author) []dto.AuthorResponse {
+	responses := make([]dto.AuthorResponse, 0, len(authors))
+	for _, author := range authors {
+		responses = append(responses, toAuthorResponse(author))
+	}
+	return responses
+}
+
+func toCategoryResponse(category models.Category) dto.CategoryResponse {
+	return dto.CategoryResponse{
+		ID:      category.ID,
+		Name:    category.Name,
+		Created: category.CreatedAt,
+	}
+}
+
+func toCategoryResponses(categories []models.Category) []dto.CategoryResponse {
+	responses := make([]dto.CategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		responses = append(responses, toCategoryResponse(category))
+	}
+	return responses
+}
+
+// toBookResponse inclui os autores na ordem em que aparecem no livro
+func toBookResponse(book models.Book) dto.BookResponse {
+	pages := book.Pages
+
+	response := dto.BookResponse{
+		ID:            book.ID,
+		Title:         book.Title,
+		ISBN:          book.ISBN,
+		PublishedYear: book.PublishedYear,
+		Publisher:     book.Publisher,
+		Pages:         &pages,
+		Authors:       make([]dto.AuthorResponse, 0, len(book.Authors)),
+		Categories:    make([]dto.CategorySimple, 0, len(book.Categories)),
+		CreatedAt:     book.CreatedAt,
+	}
+
+	for i, author := range book.Authors {
+		order := i + 1
+		authorResponse := toAuthorResponse(author)
+		authorResponse.Order = &order
+		response.Authors = append(response.Authors, authorResponse)
+	}
+
+	for _, category := range book.Categories {
+		response.Categories = append(response.Categories, dto.CategorySimple{
+			ID:   int64(category.ID),
+			Name: category.Name,
+		})
+	}
+
+	return response
+}
+
+func toBookResponses(books []models.Book) []dto.BookResponse {
+	responses := make([]dto.BookResponse, 0, len(books))
+	for _, book := range books {
+		responses = append(responses, toBookResponse(book))
+	}
+	return responses
+}
+
+func toBookSimple(book models.Book) dto.BookSimple {
+	response := dto.BookSimple{
+		ID:            book.ID,
+		Title:         book.Title,
+		PublishedYear: book.PublishedYear,
+		Isbn:          book.ISBN,
+		Authors:       make([]dto.AuthorSimple, 0, len(book.Authors)),
+		Categories:    make([]dto.CategorySimple, 0, len(book.Categories)),
+	}
+
+	for _, author := range book.Authors {
+		response.Authors = append(response.Authors, dto.AuthorSimple{ID: author.ID, Name: author.Name})
+	}
+
+	for _, category := range book.Categories {
+		response.Categories = append(response.Categories, dto.CategorySimple{
+			ID:   int64(category.ID),
+			Name: category.Name,
+		})
+	}
+
+	return response
+}
+
+func toTagResponse(tag models.Tag) dto.TagResponse {
+	return dto.TagResponse{
+		ID:         tag.ID,
+		Name:       tag.Name,
+		QuoteCount: tag.QuoteCount,
+		Created:    tag.CreatedAt,
+	}
+}
+
+func toTagResponses(tags []models.Tag) []dto.TagResponse {
+	responses := make([]dto.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		responses = append(responses, toTagResponse(tag))
+	}
+	return responses
+}
+
+func toQuoteResponse(quote models.Quote) dto.QuoteResponse {
+	response := dto.QuoteResponse{
+		ID:            quote.ID,
+		Text:          quote.Text,
+		Kind:          string(quote.Kind),
+		Page:          quote.Page,
+		LocationStart: quote.LocationStart,
+		LocationEnd:   quote.LocationEnd,
+		Favorite:      quote.Favorite,
+		Rating:        quote.Rating,
+		Notes:         make([]dto.AnnotationResponse, 0, len(quote.Annotations)),
+		Tags:          make([]dto.TagSimple, 0, len(quote.Tags)),
+		CreatedAt:     quote.CreatedAt,
+	}
+
+	if quote.Book != nil {
+		response.Book = toBookSimple(*quote.Book)
+	}
+
+	if !quote.UpdatedAt.IsZero() {
+		updatedAt := quote.UpdatedAt
+		response.UpdatedAt = &updatedAt
+	}
+
+	for _, annotation := range quote.Annotations {
+		response.Notes = append(response.Notes, dto.AnnotationResponse{
+			ID:        annotation.ID,
+			Text:      annotation.Text,
+			Location:  annotation.Location,
+			CreatedAt: annotation.CreatedAt,
+		})
+	}
+
+	for _, tag := range quote.Tags {
+		response.Tags = append(response.Tags, dto.TagSimple{ID: tag.ID, Name: tag.Name})
+	}
+
+	return response
+}
+
+func toDailyQuoteResponse(entry models.DailyQuote) dto.DailyQuoteResponse {
+	response := dto.DailyQuoteResponse{Date: entry.Day, Scope: entry.Scope}
+	if entry.Quote != nil {
+		response.Quote = toQuoteResponse(*entry.Quote)
+	}
+	return response
+}
+
+func toQuoteResponses(quotes []models.Quote) []dto.QuoteResponse {
+	responses := make([]dto.QuoteResponse, 0, len(quotes))
+	for _, quote := range quotes {
+		responses = append(responses, toQuoteResponse(quote))
+	}
+	return responses
+}
+
+func toQuoteQuery(req dto.ListQuotesRequest) repository.QuoteQuery {
+	return repository.QuoteQuery{
+		BookIDs:       req.BookIDs,
+		AuthorIDs:     req.AuthorIDs,
+		CategoryIDs:   req.CategoryIDs,
+		TagIDs:        req.TagIDs,
+		TagNames:      req.Tags,
+		CreatedFrom:   req.CreatedFrom,
+		CreatedTo:     req.CreatedTo,
+		Text:          req.Search,
+		TextScope:     repository.SearchScope(req.SearchIn),
+		MinLength:     req.MinLength,
+		MaxLength:     req.MaxLength,
+		PublishedFrom: req.PublishedFrom,
+		PublishedTo:   req.PublishedTo,
+		FavoritesOnly: req.Favorite,
+		MinRating:     req.MinRating,
+		Order:         repository.QuoteOrder(req.OrderBy),
+		Limit:         req.Limit,
+		Offset:        req.Offset,
+	}
+}
+
+func toInt64s(ids []int) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, int64(id))
+	}
+	return result
+}
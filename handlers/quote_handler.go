@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"net/http"
+	"quote-api/apperrors"
+	"quote-api/dto"
+	"quote-api/models"
+	"quote-api/repository"
+	"quote-api/service"
+	"strings"
+)
+
+type QuoteHandler struct {
+	service *service.QuoteService
+}
+
+func NewQuoteHandler(service *service.QuoteService) *QuoteHandler {
+	return &QuoteHandler{service: service}
+}
+
+func (h *QuoteHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /quotes", h.List)
+	mux.HandleFunc("POST /quotes", h.Create)
+	mux.HandleFunc("GET /quotes/random", h.Random)
+	mux.HandleFunc("GET /quotes/search", h.Search)
+	mux.HandleFunc("GET /quotes/{id}", h.Get)
+	mux.HandleFunc("PUT /quotes/{id}", h.Update)
+	mux.HandleFunc("DELETE /quotes/{id}", h.Delete)
+	mux.HandleFunc("PUT /quotes/{id}/favorite", h.Favorite)
+	mux.HandleFunc("DELETE /quotes/{id}/favorite", h.Unfavorite)
+	mux.HandleFunc("PUT /quotes/{id}/rating", h.Rate)
+	mux.HandleFunc("DELETE /quotes/{id}/rating", h.ClearRating)
+	mux.HandleFunc("GET /books/{id}/quotes", h.ListByBook)
+	mux.HandleFunc("GET /authors/{id}/quotes", h.ListByAuthor)
+	mux.HandleFunc("GET /categories/{id}/quotes", h.ListByCategory)
+	mux.HandleFunc("GET /tags/{id}/quotes", h.ListByTag)
+}
+
+// List lista citações a partir dos campos de dto.ListQuotesRequest na query
+// string. Com ?search= busca no texto; com ?search_in=all busca também no
+// título do livro e no nome dos autores. Aceita ?cursor= no lugar de ?offset=
+func (h *QuoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseListQuotesRequest(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, ok := queryCursor(w, r)
+	if !ok {
+		return
+	}
+
+	query := toQuoteQuery(req)
+	query.Cursor = cursor
+
+	page, total, err := h.service.List(query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	offset := req.Offset
+	if cursor != nil {
+		offset = 0
+	}
+
+	writeQuotePage(w, page, total, req.Limit, offset)
+}
+
+// ListByTag lista as citações com a tag, na ordem padrão, e aceita ?cursor=
+func (h *QuoteHandler) ListByTag(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	cursor, ok := queryCursor(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+	if cursor != nil {
+		offset = 0
+	}
+
+	page, total, err := h.service.List(repository.QuoteQuery{
+		TagIDs: []int64{id},
+		Cursor: cursor,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeQuotePage(w, page, total, limit, offset)
+}
+
+func (h *QuoteHandler) ListByBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	quotes, total, err := h.service.GetByBookID(id, limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeQuoteList(w, quotes, total, limit, offset)
+}
+
+func (h *QuoteHandler) ListByAuthor(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	quotes, total, err := h.service.GetByAuthorID(id, limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeQuoteList(w, quotes, total, limit, offset)
+}
+
+func (h *QuoteHandler) ListByCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	quotes, total, err := h.service.GetByCategoryID(int(id), limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeQuoteList(w, quotes, total, limit, offset)
+}
+
+// Search faz a busca textual ranqueada com ?q=, opcionalmente restrita ao
+// texto da citação com ?in=text
+func (h *QuoteHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	results, total, err := h.service.FullTextSearch(query, r.URL.Query().Get("in"), limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := dto.SearchQuotesResponse{
+		Query:   query,
+		Results: make([]dto.QuoteSearchResultResponse, 0, len(results)),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+	for _, result := range results {
+		response.Results = append(response.Results, dto.QuoteSearchResultResponse{
+			Quote:   toQuoteResponse(result.Quote),
+			Snippet: result.Snippet,
+			Score:   result.Score,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Random sorteia uma citação entre as que atendem aos filtros de List;
+// ?mode=favorites favorece as favoritas e as mais bem avaliadas. Com ?count=
+// sorteia até count citações distintas e responde com uma lista
+func (h *QuoteHandler) Random(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseListQuotesRequest(w, r)
+	if !ok {
+		return
+	}
+
+	count, ok := queryInt(w, r, "count")
+	if !ok {
+		return
+	}
+
+	n := 1
+	if count != nil {
+		n = *count
+	}
+
+	quotes, err := h.service.GetRandom(toQuoteQuery(req), n, repository.RandomWeighting(r.URL.Query().Get("mode")))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if count == nil {
+		writeJSON(w, http.StatusOK, toQuoteResponse(quotes[0]))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.RandomQuotesResponse{Quotes: toQuoteResponses(quotes)})
+}
+
+func (h *QuoteHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	quote, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toQuoteResponse(*quote))
+}
+
+func (h *QuoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateQuoteRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	quote, err := h.service.Create(models.Quote{
+		BookID:        req.BookID,
+		Text:          req.Text,
+		Kind:          models.QuoteKind(req.Kind),
+		Page:          req.Page,
+		LocationStart: req.LocationStart,
+		LocationEnd:   req.LocationEnd,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toQuoteResponse(*quote))
+}
+
+// Update altera apenas o texto; os demais campos vêm da citação atual
+func (h *QuoteHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateQuoteRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Text == nil {
+		writeError(w, r, apperrors.Validation(apperrors.CodeValidationFailed,
+			apperrors.FieldError{Field: "text", Code: apperrors.CodeRequired}))
+		return
+	}
+
+	existing, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	existing.Text = *req.Text
+
+	quote, err := h.service.Update(id, *existing)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toQuoteResponse(*quote))
+}
+
+func (h *QuoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Favorite marca a citação como favorita e a retorna
+func (h *QuoteHandler) Favorite(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	quote, err := h.service.SetFavorite(id, true)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toQuoteResponse(*quote))
+}
+
+func (h *QuoteHandler) Unfavorite(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.service.SetFavorite(id, false); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Rate avalia a citação com {"rating": 1..5} e a retorna
+func (h *QuoteHandler) Rate(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetRatingRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Rating == nil {
+		writeError(w, r, apperrors.Validation(apperrors.CodeValidationFailed,
+			apperrors.FieldError{Field: "rating", Code: apperrors.CodeRequired}))
+		return
+	}
+
+	quote, err := h.service.SetRating(id, *req.Rating)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toQuoteResponse(*quote))
+}
+
+func (h *QuoteHandler) ClearRating(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.service.ClearRating(id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseListQuotesRequest(w http.ResponseWriter, r *http.Request) (dto.ListQuotesRequest, bool) {
+	query := r.URL.Query()
+
+	req := dto.ListQuotesRequest{
+		Cursor:   query.Get("cursor"),
+		OrderBy:  query.Get("order_by"),
+		Search:   strings.TrimSpace(query.Get("search")),
+		SearchIn: query.Get("search_in"),
+	}
+	var ok bool
+	if req.Limit, req.Offset, ok = pagination(w, r); !ok {
+		return req, false
+	}
+	if req.BookIDs, ok = queryIDs(w, r, "book_id"); !ok {
+		return req, false
+	}
+	if req.AuthorIDs, ok = queryIDs(w, r, "author_id"); !ok {
+		return req, false
+	}
+	if req.TagIDs, ok = queryIDs(w, r, "tag_id"); !ok {
+		return req, false
+	}
+
+	for _, name := range strings.Split(query.Get("tag"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			req.Tags = append(req.Tags, name)
+		}
+	}
+
+	categoryIDs, ok := queryIDs(w, r, "category_id")
+	if !ok {
+		return req, false
+	}
+	for _, id := range categoryIDs {
+		req.CategoryIDs = append(req.CategoryIDs, int(id))
+	}
+
+	if req.CreatedFrom, ok = queryTime(w, r, "created_from", false); !ok {
+		return req, false
+	}
+	if req.CreatedTo, ok = queryTime(w, r, "created_to", true); !ok {
+		return req, false
+	}
+
+	if req.MinLength, ok = queryInt(w, r, "min_length"); !ok {
+		return req, false
+	}
+	if req.MaxLength, ok = queryInt(w, r, "max_length"); !ok {
+		return req, false
+	}
+	if req.PublishedFrom, ok = queryInt(w, r, "published_from"); !ok {
+		return req, false
+	}
+	if req.PublishedTo, ok = queryInt(w, r, "published_to"); !ok {
+		return req, false
+	}
+
+	if req.Favorite, ok = queryBool(w, r, "favorite"); !ok {
+		return req, false
+	}
+	if req.MinRating, ok = queryInt(w, r, "min_rating"); !ok {
+		return req, false
+	}
+
+	return req, true
+}
+
+func writeQuotePage(w http.ResponseWriter, page repository.Page[models.Quote], total, limit, offset int) {
+	writeJSON(w, http.StatusOK, dto.ListQuotesResponse{
+		Quotes:     toQuoteResponses(page.Items),
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		HasMore:    page.HasMore,
+		NextCursor: cursorToken(page.Next),
+		PrevCursor: cursorToken(page.Prev),
+	})
+}
+
+func writeQuoteList(w http.ResponseWriter, quotes []models.Quote, total, limit, offset int) {
+	writeJSON(w, http.StatusOK, dto.ListQuotesResponse{
+		Quotes:  toQuoteResponses(quotes),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: offset+len(quotes) < total,
+	})
+}
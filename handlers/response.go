@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"quote-api/apperrors"
+	"quote-api/dto"
+	"quote-api/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// maxPageSize acompanha o limite aplicado pelos services
+const maxPageSize = 100
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	if body == nil {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Println("Erro ao escrever resposta:", err)
+	}
+}
+
+func writeErrorMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, dto.ErrorResponse{Error: message, Status: status})
+}
+
+// writeError converte o erro do service no status HTTP correspondente, com a
+// mensagem no idioma do Accept-Language. Erros internos não têm a mensagem
+// exposta ao cliente
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusFromError(err)
+	if status == http.StatusInternalServerError {
+		log.Println("Erro interno:", err)
+		writeErrorMessage(w, status, "erro interno do servidor")
+		return
+	}
+
+	appErr, ok := apperrors.As(err)
+	if !ok {
+		writeErrorMessage(w, status, err.Error())
+		return
+	}
+
+	lang := apperrors.Language(r.Header.Get("Accept-Language"))
+	response := dto.ErrorResponse{
+		Error:  appErr.Message(lang),
+		Code:   appErr.Code,
+		Status: status,
+	}
+	for _, field := range appErr.Fields {
+		response.Fields = append(response.Fields, dto.FieldErrorResponse{
+			Field:   field.Field,
+			Code:    field.Code,
+			Message: field.Message(lang),
+		})
+	}
+
+	writeJSON(w, status, response)
+}
+
+// statusFromError classifica os erros pelas categorias de apperrors. Erros do
+// banco viram 500, ou 409 em violação de constraint
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, apperrors.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, apperrors.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperrors.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, apperrors.ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// decodeJSON lê o corpo da requisição em target. Um valor do tipo errado
+// aponta o campo; os demais erros respondem CodeInvalidJSON
+func decodeJSON(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			writeInvalidField(w, r, typeErr.Field)
+			return false
+		}
+		writeError(w, r, apperrors.Validation(apperrors.CodeInvalidJSON))
+		return false
+	}
+	return true
+}
+
+// writeInvalidField responde 400 com o erro de validação do campo ou
+// parâmetro name, traduzido como os erros dos services
+func writeInvalidField(w http.ResponseWriter, r *http.Request, name string) {
+	var fields apperrors.Fields
+	fields.Add(name, apperrors.CodeInvalid)
+	writeError(w, r, fields.Err())
+}
+
+// pathIDCodes escolhe o código de erro dos IDs da rota que não se chamam "id"
+var pathIDCodes = map[string]string{
+	"tag_id": apperrors.CodeInvalidTagID,
+}
+
+// pathID lê um ID numérico da rota, respondendo 400 se for inválido
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		code, ok := pathIDCodes[name]
+		if !ok {
+			code = apperrors.CodeInvalidID
+		}
+		writeError(w, r, apperrors.Validation(code))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryID lê um ID opcional da query string
+func queryID(w http.ResponseWriter, r *http.Request, name string) (*int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		writeInvalidField(w, r, name)
+		return nil, false
+	}
+	return &id, true
+}
+
+// queryIDs lê uma lista opcional de IDs separados por vírgula
+func queryIDs(w http.ResponseWriter, r *http.Request, name string) ([]int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || id <= 0 {
+			writeInvalidField(w, r, name)
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// queryCursor lê o token opcional de paginação por cursor
+func queryCursor(w http.ResponseWriter, r *http.Request) (*repository.Cursor, bool) {
+	token := r.URL.Query().Get("cursor")
+	if token == "" {
+		return nil, true
+	}
+
+	cursor, err := repository.DecodeCursor(token)
+	if err != nil {
+		writeError(w, r, err)
+		return nil, false
+	}
+	return cursor, true
+}
+
+// cursorToken devolve o token do cursor, ou "" quando não há página
+func cursorToken(cursor *repository.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return cursor.Encode()
+}
+
+// queryInt lê um inteiro opcional da query string
+func queryInt(w http.ResponseWriter, r *http.Request, name string) (*int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		writeInvalidField(w, r, name)
+		return nil, false
+	}
+	return &number, true
+}
+
+// queryBool lê um booleano opcional da query string (true, false, 1 ou 0)
+func queryBool(w http.ResponseWriter, r *http.Request, name string) (bool, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, true
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		writeInvalidField(w, r, name)
+		return false, false
+	}
+	return b, true
+}
+
+// queryTime lê uma data (2006-01-02, em UTC) ou um instante RFC 3339. Com
+// wholeDay, uma data vira o início do dia seguinte, para servir de limite
+// exclusivo que inclui o dia informado
+func queryTime(w http.ResponseWriter, r *http.Request, name string, wholeDay bool) (*time.Time, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		writeInvalidField(w, r, name)
+		return nil, false
+	}
+	if wholeDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+// pagination lê limit e offset aplicando as mesmas regras dos services, para
+// que a resposta informe os valores realmente usados. Sem limit vale
+// maxPageSize; um valor que não é número ou fica fora de 1..maxPageSize, ou
+// um offset negativo, responde 400
+func pagination(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	query := r.URL.Query()
+	limit, offset := maxPageSize, 0
+	var fields apperrors.Fields
+
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		switch {
+		case err != nil:
+			fields.Add("limit", apperrors.CodeInvalid)
+		case n <= 0 || n > maxPageSize:
+			fields.Add("limit", apperrors.CodeOutOfRange)
+		default:
+			limit = n
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		switch {
+		case err != nil:
+			fields.Add("offset", apperrors.CodeInvalid)
+		case n < 0:
+			fields.Add("offset", apperrors.CodeNegative)
+		default:
+			offset = n
+		}
+	}
+
+	if err := fields.Err(); err != nil {
+		writeError(w, r, err)
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
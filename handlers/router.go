@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"quote-api/apperrors"
+	"strings"
+	"time"
+)
+
+// NewRouter registra as rotas de todos os recursos
+func NewRouter(
+	authorHandler *AuthorHandler,
+	bookHandler *BookHandler,
+	categoryHandler *CategoryHandler,
+	quoteHandler *QuoteHandler,
+	tagHandler *TagHandler,
+	dailyQuoteHandler *DailyQuoteHandler,
+) http.Handler {
+	mux := http.NewServeMux()
+
+	authorHandler.RegisterRoutes(mux)
+	bookHandler.RegisterRoutes(mux)
+	categoryHandler.RegisterRoutes(mux)
+	quoteHandler.RegisterRoutes(mux)
+	tagHandler.RegisterRoutes(mux)
+	dailyQuoteHandler.RegisterRoutes(mux)
+
+	// "/" só atende o que nenhuma rota casa: um caminho conhecido com outro
+	// método responde 405 com Allow, como o ServeMux faria sem o catch-all
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if allowed := allowedMethods(mux, r); len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeError(w, r, apperrors.MethodNotAllowed(apperrors.CodeMethodNotAllowed))
+			return
+		}
+		writeError(w, r, apperrors.NotFound(apperrors.CodeRouteNotFound))
+	})
+
+	return logRequests(mux)
+}
+
+// routeMethods são os métodos testados para montar o Allow
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost,
+	http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// allowedMethods lista os métodos com rota registrada para o caminho de r,
+// além do catch-all
+func allowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	var allowed []string
+	for _, method := range routeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "/" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// NewServer cria o servidor HTTP com timeouts adequados para a API
+func NewServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, recorder.status, time.Since(start))
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quote-api/apperrors"
+	"quote-api/dto"
+	"quote-api/repository/memory"
+	"quote-api/service"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// logRequests registra cada requisição no log padrão
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func newTestRouter() http.Handler {
+	store := memory.NewStore()
+	authorRepo, bookRepo, categoryRepo := store.Authors(), store.Books(), store.Categories()
+	quoteRepo, tagRepo := store.Quotes(), store.Tags()
+
+	return NewRouter(
+		NewAuthorHandler(service.NewAuthorService(authorRepo, bookRepo)),
+		NewBookHandler(service.NewBookService(bookRepo, authorRepo, categoryRepo)),
+		NewCategoryHandler(service.NewCategoryService(categoryRepo, bookRepo)),
+		NewQuoteHandler(service.NewQuoteService(quoteRepo, bookRepo)),
+		NewTagHandler(service.NewTagService(tagRepo, quoteRepo)),
+		NewDailyQuoteHandler(service.NewDailyQuoteService(store.DailyQuotes(), quoteRepo, service.DailyQuoteConfig{
+			Location: time.UTC,
+		})),
+	)
+}
+
+func serve(t *testing.T, router http.Handler, method, target, lang string) (*httptest.ResponseRecorder, dto.ErrorResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, nil)
+	if lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var body dto.ErrorResponse
+	if rec.Code >= 400 {
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%s %s: corpo de erro inválido: %v", method, target, err)
+		}
+	}
+	return rec, body
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		method, target string
+		status         int
+		allow          string
+	}{
+		{http.MethodPatch, "/authors", http.StatusMethodNotAllowed, "GET, HEAD, POST"},
+		{http.MethodPost, "/authors/1", http.StatusMethodNotAllowed, "GET, HEAD, PUT, DELETE"},
+		{http.MethodGet, "/nada", http.StatusNotFound, ""},
+		{http.MethodDelete, "/nada/1", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		rec, body := serve(t, router, tt.method, tt.target, "")
+		if rec.Code != tt.status {
+			t.Errorf("%s %s = %d, quer %d", tt.method, tt.target, rec.Code, tt.status)
+		}
+		if allow := rec.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s %s: Allow = %q, quer %q", tt.method, tt.target, allow, tt.allow)
+		}
+		wantCode := apperrors.CodeRouteNotFound
+		if tt.status == http.StatusMethodNotAllowed {
+			wantCode = apperrors.CodeMethodNotAllowed
+		}
+		if body.Code != wantCode {
+			t.Errorf("%s %s: code = %q, quer %q", tt.method, tt.target, body.Code, wantCode)
+		}
+	}
+}
+
+func TestPagination_Invalid(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		query, field, code, lang, message string
+	}{
+		{"limit=abc", "limit", apperrors.CodeInvalid, "", "campo limite inválido"},
+		{"limit=0", "limit", apperrors.CodeOutOfRange, "", "campo limite fora do intervalo permitido"},
+		{"limit=101", "limit", apperrors.CodeOutOfRange, "en", "field limit is out of range"},
+		{"offset=-1", "offset", apperrors.CodeNegative, "en", "field offset cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		for _, path := range []string{"/authors", "/quotes", "/tags"} {
+			target := path + "?" + tt.query
+			rec, body := serve(t, router, http.MethodGet, target, tt.lang)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("GET %s = %d, quer 400", target, rec.Code)
+				continue
+			}
+			if len(body.Fields) != 1 || body.Fields[0].Field != tt.field || body.Fields[0].Code != tt.code {
+				t.Errorf("GET %s: campos %+v, quer %s/%s", target, body.Fields, tt.field, tt.code)
+			}
+			if body.Error != tt.message {
+				t.Errorf("GET %s: mensagem %q, quer %q", target, body.Error, tt.message)
+			}
+		}
+	}
+
+	for _, target := range []string{"/authors", "/authors?limit=100&offset=0", "/quotes?limit=1"} {
+		if rec, _ := serve(t, router, http.MethodGet, target, ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, quer 200", target, rec.Code)
+		}
+	}
+}
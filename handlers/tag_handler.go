@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"quote-api/dto"
+	"quote-api/models"
+	"quote-api/service"
+)
+
+type TagHandler struct {
+	service *service.TagService
+}
+
+func NewTagHandler(service *service.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+func (h *TagHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /tags", h.List)
+	mux.HandleFunc("POST /tags", h.Create)
+	mux.HandleFunc("GET /tags/{id}", h.Get)
+	mux.HandleFunc("PUT /tags/{id}", h.Update)
+	mux.HandleFunc("DELETE /tags/{id}", h.Delete)
+	mux.HandleFunc("POST /quotes/{id}/tags", h.AddToQuote)
+	mux.HandleFunc("DELETE /quotes/{id}/tags/{tag_id}", h.RemoveFromQuote)
+}
+
+// List lista tags com a quantidade de citações de cada uma; com ?search=
+// filtra pelo nome
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	tags, total, err := h.service.Search(r.URL.Query().Get("search"), limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ListTagsResponse{
+		Tags:   toTagResponses(tags),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (h *TagHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	tag, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTagResponse(*tag))
+}
+
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateTagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	tag, err := h.service.Create(models.Tag{Name: req.Name})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toTagResponse(*tag))
+}
+
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	tag, err := h.service.Update(id, models.Tag{Name: req.Name})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTagResponse(*tag))
+}
+
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddToQuote aplica uma tag à citação pelo nome, criando a tag se necessário,
+// e retorna a tag aplicada
+func (h *TagHandler) AddToQuote(w http.ResponseWriter, r *http.Request) {
+	quoteID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AddQuoteTagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	tag, err := h.service.AddToQuote(quoteID, req.Name)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTagResponse(*tag))
+}
+
+func (h *TagHandler) RemoveFromQuote(w http.ResponseWriter, r *http.Request) {
+	quoteID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	tagID, ok := pathID(w, r, "tag_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveFromQuote(quoteID, tagID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clippingSeparator separa cada entrada do arquivo "My Clippings.txt"
+const clippingSeparator = "=========="
+
+type ClippingKind string
+
+const (
+	KindHighlight ClippingKind = "highlight"
+	KindNote      ClippingKind = "note"
+	KindBookmark  ClippingKind = "bookmark"
+)
+
+// Clipping representa uma entrada do arquivo "My Clippings.txt"
+type Clipping struct {
+	Title         string
+	Authors       []string
+	Kind          ClippingKind
+	Page          *int
+	LocationStart *int
+	LocationEnd   *int
+	AddedAt       time.Time
+	Text          string
+}
+
+// headerAuthorRegex aceita também os parênteses de largura total usados em japonês
+var headerAuthorRegex = regexp.MustCompile(`^(.*?)\s*[(（]([^()（）]*)[)）]\s*$`)
+
+// Parse lê um arquivo "My Clippings.txt" e retorna suas entradas na ordem
+// em que aparecem. Entradas malformadas geram erro com o número da entrada
+func Parse(r io.Reader) ([]Clipping, error) {
+	entries, err := parseEntries(r)
+	if err != nil {
+		return nil, err
+	}
+
+	clippings := make([]Clipping, 0, len(entries))
+	for _, entry := range entries {
+		if entry.err != nil {
+			return nil, fmt.Errorf("entrada %d: %w", entry.index, entry.err)
+		}
+		clippings = append(clippings, *entry.clipping)
+	}
+
+	return clippings, nil
+}
+
+// parsedEntry guarda o resultado de cada bloco, para que o importador possa
+// registrar entradas malformadas sem abortar o arquivo inteiro
+type parsedEntry struct {
+	index    int
+	clipping *Clipping
+	err      error
+}
+
+func parseEntries(r io.Reader) ([]parsedEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var entries []parsedEntry
+	var block []string
+
+	flush := func() {
+		if !isBlank(block) {
+			clipping, err := parseBlock(block)
+			entries = append(entries, parsedEntry{index: len(entries) + 1, clipping: clipping, err: err})
+		}
+		block = nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		line = strings.TrimPrefix(line, "\ufeff")
+
+		if strings.TrimSpace(line) == clippingSeparator {
+			flush()
+			continue
+		}
+
+		block = append(block, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	flush()
+
+	return entries, nil
+}
+
+func parseBlock(lines []string) (*Clipping, error) {
+	// Remove linhas vazias antes do cabeçalho
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("entrada incompleta")
+	}
+
+	clipping := Clipping{}
+	clipping.Title, clipping.Authors = parseHeader(lines[0])
+	if clipping.Title == "" {
+		return nil, fmt.Errorf("título ausente")
+	}
+
+	if err := parseMetadata(strings.TrimSpace(lines[1]), &clipping); err != nil {
+		return nil, err
+	}
+
+	clipping.Text = strings.TrimSpace(strings.Join(lines[2:], "\n"))
+
+	return &clipping, nil
+}
+
+// parseHeader separa "Título (Autor)". Vários autores vêm separados por ";"
+func parseHeader(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+
+	match := headerAuthorRegex.FindStringSubmatch(line)
+	if match == nil {
+		return line, nil
+	}
+
+	var authors []string
+	for _, name := range strings.Split(match[2], ";") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			authors = append(authors, name)
+		}
+	}
+
+	return strings.TrimSpace(match[1]), authors
+}
+
+func parseMetadata(line string, clipping *Clipping) error {
+	locale, kind, ok := detectLocale(line)
+	if !ok {
+		return fmt.Errorf("linha de metadados não reconhecida: %q", line)
+	}
+	clipping.Kind = kind
+
+	if page := locale.page.FindStringSubmatch(line); page != nil {
+		if n, err := strconv.Atoi(page[1]); err == nil {
+			clipping.Page = &n
+		}
+	}
+
+	if location := locale.location.FindStringSubmatch(line); location != nil {
+		start, end := parseLocationRange(location[1], location[2])
+		clipping.LocationStart = &start
+		clipping.LocationEnd = &end
+	}
+
+	if added := locale.added.FindStringSubmatch(line); added != nil {
+		addedAt, err := locale.parseDate(strings.TrimSpace(added[1]))
+		if err != nil {
+			return err
+		}
+		clipping.AddedAt = addedAt
+	}
+
+	return nil
+}
+
+// parseLocationRange trata intervalos abreviados dos Kindles antigos,
+// como "1234-40", que significa 1234-1240
+func parseLocationRange(startText, endText string) (int, int) {
+	start, _ := strconv.Atoi(startText)
+	if endText == "" {
+		return start, start
+	}
+
+	if len(endText) < len(startText) {
+		endText = startText[:len(startText)-len(endText)] + endText
+	}
+
+	end, _ := strconv.Atoi(endText)
+	if end < start {
+		end = start
+	}
+
+	return start, end
+}
+
+func isBlank(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
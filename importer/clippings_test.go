@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sampleClippings, com BOM e quebras de linha do Windows, tem um destaque, a
+// nota sobre ele, um marcador, um destaque de vários parágrafos e, no fim, um
+// separador sem entrada depois
+const sampleClippings = "\ufeffDom Casmurro (Machado de Assis)\r\n" +
+	"- Seu destaque ou posição 120-125 | Adicionado: sexta-feira, 3 de maio de 2024 10:15:22\r\n" +
+	"\r\n" +
+	"Capitu, olhos de ressaca\r\n" +
+	"==========\r\n" +
+	"Dom Casmurro (Machado de Assis)\r\n" +
+	"- Sua nota na posição 125 | Adicionado: sexta-feira, 3 de maio de 2024 10:16:00\r\n" +
+	"\r\n" +
+	"Releia\r\n" +
+	"==========\r\n" +
+	"Ficções (Jorge Luis Borges; Andrés Bioy Casares)\r\n" +
+	"- Your Bookmark on page 10 | location 300 | Added on Friday, May 3, 2024 9:00:00 AM\r\n" +
+	"\r\n" +
+	"\r\n" +
+	"==========\r\n" +
+	"Sem autor\r\n" +
+	"- Your Highlight on location 10-12 | Added on Friday, May 3, 2024 9:00:00 AM\r\n" +
+	"\r\n" +
+	"Primeiro parágrafo.\r\n" +
+	"\r\n" +
+	"Segundo parágrafo.\r\n" +
+	"==========\r\n"
+
+func TestParse(t *testing.T) {
+	clippings, err := Parse(strings.NewReader(sampleClippings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		Title   string
+		Authors []string
+		Kind    ClippingKind
+		Start   int
+		End     int
+		Text    string
+	}
+	var got []summary
+	for _, clipping := range clippings {
+		got = append(got, summary{
+			Title:   clipping.Title,
+			Authors: clipping.Authors,
+			Kind:    clipping.Kind,
+			Start:   *clipping.LocationStart,
+			End:     *clipping.LocationEnd,
+			Text:    clipping.Text,
+		})
+	}
+
+	want := []summary{
+		{"Dom Casmurro", []string{"Machado de Assis"}, KindHighlight, 120, 125, "Capitu, olhos de ressaca"},
+		{"Dom Casmurro", []string{"Machado de Assis"}, KindNote, 125, 125, "Releia"},
+		{"Ficções", []string{"Jorge Luis Borges", "Andrés Bioy Casares"}, KindBookmark, 300, 300, ""},
+		{"Sem autor", nil, KindHighlight, 10, 12, "Primeiro parágrafo.\n\nSegundo parágrafo."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entradas\n%+v\nquer\n%+v", got, want)
+	}
+
+	if page := clippings[2].Page; page == nil || *page != 10 {
+		t.Errorf("página do marcador = %v, quer 10", page)
+	}
+}
+
+func TestParse_Separator(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		entries int
+	}{
+		{"vazio", "", 0},
+		{"só separadores", "==========\n\n==========\n", 0},
+		{"sem separador no fim", "Livro\n- Your Highlight on location 1 | Added on Friday, May 3, 2024\n\nTexto", 1},
+		{"separador com espaços", "Livro\n- Your Highlight on location 1 | Added on Friday, May 3, 2024\n\nTexto\n  ==========  \nLivro\n- Your Highlight on location 2 | Added on Friday, May 3, 2024\n\nOutro", 2},
+		{"linhas em branco antes do cabeçalho", "\n\nLivro\n- Your Highlight on location 1 | Added on Friday, May 3, 2024\n\nTexto\n==========", 1},
+		{"separador dentro do texto não é separador", "Livro\n- Your Highlight on location 1 | Added on Friday, May 3, 2024\n\nantes ========== depois\n==========", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clippings, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(clippings) != tt.entries {
+				t.Errorf("%d entradas, quer %d", len(clippings), tt.entries)
+			}
+		})
+	}
+}
+
+func TestParse_MalformedEntry(t *testing.T) {
+	input := "Livro\n- Your Highlight on location 1 | Added on Friday, May 3, 2024\n\nTexto\n" +
+		"==========\nLivro sem metadados\n==========\n"
+
+	_, err := Parse(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "entrada 2") {
+		t.Errorf("erro = %v, quer um erro na entrada 2", err)
+	}
+}
@@ -0,0 +1,436 @@
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"quote-api/database"
+	"quote-api/dedupe"
+	"quote-api/models"
+	"quote-api/repository"
+	"sort"
+)
+
+// maxQuoteLength acompanha o limite aplicado pelo QuoteService
+const maxQuoteLength = 5000
+
+type EntryStatus string
+
+const (
+	StatusCreated  EntryStatus = "created"
+	StatusAttached EntryStatus = "attached"
+	// StatusDuplicate: a entrada já estava gravada com a mesma posição e texto
+	StatusDuplicate EntryStatus = "duplicate"
+	// StatusMerged: a entrada é outra versão de um destaque já gravado e
+	// apenas a mais recente foi mantida
+	StatusMerged  EntryStatus = "merged"
+	StatusSkipped EntryStatus = "skipped"
+	StatusFailed  EntryStatus = "failed"
+)
+
+// EntryResult descreve o que aconteceu com cada entrada do arquivo. Para notas
+// anexadas, QuoteID é o destaque que recebeu a anotação
+type EntryResult struct {
+	Index   int
+	Title   string
+	Status  EntryStatus
+	Reason  string
+	QuoteID int64
+}
+
+// Summary resume uma importação
+type Summary struct {
+	Created        int
+	Attached       int
+	Duplicates     int
+	Merged         int
+	Skipped        int
+	Failed         int
+	AuthorsCreated int
+	BooksCreated   int
+	Entries        []EntryResult
+}
+
+type Importer struct {
+	db             *sql.DB
+	authorRepo     *repository.AuthorRepository
+	bookRepo       *repository.BookRepository
+	quoteRepo      *repository.QuoteRepository
+	annotationRepo *repository.AnnotationRepository
+	tagRepo        *repository.TagRepository
+}
+
+func NewImporter(
+	store *database.Store,
+	authorRepo *repository.AuthorRepository,
+	bookRepo *repository.BookRepository,
+	quoteRepo *repository.QuoteRepository,
+	annotationRepo *repository.AnnotationRepository,
+	tagRepo *repository.TagRepository,
+) *Importer {
+	return &Importer{
+		db:             store.DB(),
+		authorRepo:     authorRepo,
+		bookRepo:       bookRepo,
+		quoteRepo:      quoteRepo,
+		annotationRepo: annotationRepo,
+		tagRepo:        tagRepo,
+	}
+}
+
+// ImportFile importa o arquivo "My Clippings.txt" localizado em path
+func (i *Importer) ImportFile(path string) (*Summary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return i.Import(file)
+}
+
+// Import lê as entradas de r e grava autores, livros e citações em uma única
+// transação. Cada entrada roda em um savepoint próprio, então uma falha
+// descarta apenas aquela entrada e é registrada no resumo. Notas são anexadas
+// ao destaque cuja posição as contém, em vez de virarem citações
+func (i *Importer) Import(r io.Reader) (*Summary, error) {
+	entries, err := parseEntries(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	run := &importRun{
+		tx:             tx,
+		authorRepo:     i.authorRepo.WithTx(tx),
+		bookRepo:       i.bookRepo.WithTx(tx),
+		quoteRepo:      i.quoteRepo.WithTx(tx),
+		annotationRepo: i.annotationRepo.WithTx(tx),
+		tagRepo:        i.tagRepo.WithTx(tx),
+		summary:        &Summary{},
+	}
+
+	// Notas são processadas depois dos destaques para que possam ser
+	// anexadas mesmo quando aparecem antes deles no arquivo
+	var notes []parsedEntry
+	for _, entry := range entries {
+		if entry.err == nil && entry.clipping.Kind == KindNote {
+			notes = append(notes, entry)
+			continue
+		}
+		run.importEntry(entry, run.saveClipping)
+	}
+
+	for _, entry := range notes {
+		run.importEntry(entry, run.saveNote)
+	}
+
+	sort.SliceStable(run.summary.Entries, func(a, b int) bool {
+		return run.summary.Entries[a].Index < run.summary.Entries[b].Index
+	})
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return run.summary, nil
+}
+
+// importRun guarda o estado de uma importação em andamento
+type importRun struct {
+	tx             *sql.Tx
+	authorRepo     *repository.AuthorRepository
+	bookRepo       *repository.BookRepository
+	quoteRepo      *repository.QuoteRepository
+	annotationRepo *repository.AnnotationRepository
+	tagRepo        *repository.TagRepository
+	summary        *Summary
+
+	// contadores da entrada atual, somados ao resumo apenas se ela for gravada
+	authorsCreated int
+	booksCreated   int
+}
+
+// saveFunc grava uma entrada e preenche Status, QuoteID e Reason do resultado
+type saveFunc func(clipping *Clipping, result *EntryResult) error
+
+func (r *importRun) importEntry(entry parsedEntry, save saveFunc) {
+	result := EntryResult{Index: entry.index}
+
+	if entry.err != nil {
+		r.record(result, StatusFailed, entry.err.Error())
+		return
+	}
+
+	clipping := entry.clipping
+	result.Title = clipping.Title
+
+	if reason := skipReason(clipping); reason != "" {
+		r.record(result, StatusSkipped, reason)
+		return
+	}
+
+	if len(clipping.Text) > maxQuoteLength {
+		r.record(result, StatusFailed, fmt.Sprintf("texto da citação deve ter no máximo %d caracteres", maxQuoteLength))
+		return
+	}
+
+	if _, err := r.tx.Exec("SAVEPOINT clipping"); err != nil {
+		r.record(result, StatusFailed, err.Error())
+		return
+	}
+
+	r.authorsCreated, r.booksCreated = 0, 0
+
+	if err := save(clipping, &result); err != nil {
+		r.tx.Exec("ROLLBACK TO clipping")
+		r.tx.Exec("RELEASE clipping")
+		r.record(result, StatusFailed, err.Error())
+		return
+	}
+
+	if _, err := r.tx.Exec("RELEASE clipping"); err != nil {
+		r.record(result, StatusFailed, err.Error())
+		return
+	}
+
+	r.summary.AuthorsCreated += r.authorsCreated
+	r.summary.BooksCreated += r.booksCreated
+
+	r.record(result, result.Status, result.Reason)
+}
+
+func (r *importRun) saveClipping(clipping *Clipping, result *EntryResult) error {
+	book, err := r.resolveBook(clipping)
+	if err != nil {
+		return err
+	}
+
+	incoming := models.Quote{
+		BookID:        book.ID,
+		Text:          clipping.Text,
+		Kind:          models.QuoteKind(clipping.Kind),
+		Page:          clipping.Page,
+		LocationStart: clipping.LocationStart,
+		LocationEnd:   clipping.LocationEnd,
+		CreatedAt:     clipping.AddedAt,
+	}
+
+	merged, err := r.mergeExisting(incoming, result)
+	if err != nil || merged {
+		return err
+	}
+
+	quote, err := r.quoteRepo.Create(incoming)
+	if err != nil {
+		return err
+	}
+
+	result.Status = StatusCreated
+	result.QuoteID = quote.ID
+	return nil
+}
+
+// mergeExisting compara a entrada com as citações já gravadas do livro. Se for
+// uma duplicata ou uma versão mais antiga, nada é gravado; se for a versão mais
+// recente de destaques existentes, o primeiro é atualizado e os demais removidos
+func (r *importRun) mergeExisting(incoming models.Quote, result *EntryResult) (bool, error) {
+	candidates, err := r.quoteRepo.FindOverlapping(incoming)
+	if err != nil {
+		return false, err
+	}
+
+	for _, existing := range candidates {
+		if dedupe.Compare(existing, incoming) == dedupe.Duplicate {
+			result.Status = StatusDuplicate
+			result.QuoteID = existing.ID
+			result.Reason = "citação já importada"
+			return true, nil
+		}
+	}
+
+	var superseded []models.Quote
+	for _, existing := range candidates {
+		if dedupe.Compare(existing, incoming) != dedupe.Overlapping {
+			continue
+		}
+
+		if !dedupe.Latest(existing, incoming) {
+			result.Status = StatusMerged
+			result.QuoteID = existing.ID
+			result.Reason = "uma versão mais recente deste destaque já existe"
+			return true, nil
+		}
+
+		superseded = append(superseded, existing)
+	}
+
+	if len(superseded) == 0 {
+		return false, nil
+	}
+
+	kept := superseded[0]
+	if _, err := r.quoteRepo.Supersede(kept.ID, incoming); err != nil {
+		return false, err
+	}
+
+	for _, other := range superseded[1:] {
+		if err := r.annotationRepo.MoveToQuote(other.ID, kept.ID); err != nil {
+			return false, err
+		}
+		if err := r.tagRepo.MoveToQuote(other.ID, kept.ID); err != nil {
+			return false, err
+		}
+		if err := r.quoteRepo.MergeMarks(other.ID, kept.ID); err != nil {
+			return false, err
+		}
+		if err := r.quoteRepo.Delete(other.ID); err != nil {
+			return false, err
+		}
+	}
+
+	result.Status = StatusMerged
+	result.QuoteID = kept.ID
+	result.Reason = fmt.Sprintf("substitui %d versão(ões) anterior(es) do destaque", len(superseded))
+	return true, nil
+}
+
+// saveNote anexa a nota ao destaque que contém sua posição. Sem destaque
+// correspondente, a nota é gravada como citação do tipo "note" para não se perder
+func (r *importRun) saveNote(clipping *Clipping, result *EntryResult) error {
+	highlight, err := r.findHighlightFor(clipping)
+	if err != nil {
+		return err
+	}
+
+	if highlight == nil {
+		if err := r.saveClipping(clipping, result); err != nil {
+			return err
+		}
+		result.Reason = "nenhum destaque contém a posição da nota"
+		return nil
+	}
+
+	for _, annotation := range highlight.Annotations {
+		if dedupe.TextHash(annotation.Text) == dedupe.TextHash(clipping.Text) {
+			result.Status = StatusDuplicate
+			result.QuoteID = highlight.ID
+			result.Reason = "nota já anexada a este destaque"
+			return nil
+		}
+	}
+
+	_, err = r.annotationRepo.Create(models.Annotation{
+		QuoteID:   highlight.ID,
+		Text:      clipping.Text,
+		Location:  clipping.LocationStart,
+		CreatedAt: clipping.AddedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.Status = StatusAttached
+	result.QuoteID = highlight.ID
+	return nil
+}
+
+func (r *importRun) findHighlightFor(clipping *Clipping) (*models.Quote, error) {
+	if clipping.LocationStart == nil {
+		return nil, nil
+	}
+
+	book, err := r.bookRepo.FindByTitle(clipping.Title)
+	if err != nil || book == nil {
+		return nil, err
+	}
+
+	// Notas do Kindle registram a posição final do destaque
+	return r.quoteRepo.FindHighlightAt(book.ID, *clipping.LocationEnd)
+}
+
+// resolveBook busca o livro pelo título e o cria com seus autores caso não exista
+func (r *importRun) resolveBook(clipping *Clipping) (*models.Book, error) {
+	book, err := r.bookRepo.FindByTitle(clipping.Title)
+	if err != nil {
+		return nil, err
+	}
+	if book != nil {
+		return book, nil
+	}
+
+	authorIDs, err := r.resolveAuthors(clipping.Authors)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err = r.bookRepo.Create(models.Book{Title: clipping.Title}, authorIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.booksCreated++
+	return book, nil
+}
+
+// resolveAuthors busca cada autor pelo nome e cria os que não existirem
+func (r *importRun) resolveAuthors(names []string) ([]int64, error) {
+	var ids []int64
+
+	for _, name := range names {
+		author, err := r.authorRepo.FindByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if author == nil {
+			author, err = r.authorRepo.Create(models.Author{Name: name})
+			if err != nil {
+				return nil, err
+			}
+			r.authorsCreated++
+		}
+
+		ids = append(ids, author.ID)
+	}
+
+	return ids, nil
+}
+
+func (r *importRun) record(result EntryResult, status EntryStatus, reason string) {
+	result.Status = status
+	result.Reason = reason
+
+	switch status {
+	case StatusCreated:
+		r.summary.Created++
+	case StatusAttached:
+		r.summary.Attached++
+	case StatusDuplicate:
+		r.summary.Duplicates++
+	case StatusMerged:
+		r.summary.Merged++
+	case StatusSkipped:
+		r.summary.Skipped++
+	case StatusFailed:
+		r.summary.Failed++
+	}
+
+	r.summary.Entries = append(r.summary.Entries, result)
+}
+
+func skipReason(clipping *Clipping) string {
+	if clipping.Kind == KindBookmark {
+		return "marcador não contém texto"
+	}
+	if clipping.Text == "" {
+		return "entrada sem texto"
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"quote-api/database"
+	"quote-api/repository"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// database.Open registra cada conexão no log padrão
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func newTestImporter(t *testing.T) (*Importer, *repository.QuoteRepository) {
+	t.Helper()
+
+	store, err := database.Open(database.Config{DSN: filepath.Join(t.TempDir(), "quotes.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	quoteRepo := repository.NewQuoteRepository(store)
+	importer := NewImporter(store,
+		repository.NewAuthorRepository(store),
+		repository.NewBookRepository(store),
+		quoteRepo,
+		repository.NewAnnotationRepository(store),
+		repository.NewTagRepository(store),
+	)
+	return importer, quoteRepo
+}
+
+// counts resume o resumo da importação sem a lista de entradas
+func counts(summary *Summary) Summary {
+	result := *summary
+	result.Entries = nil
+	return result
+}
+
+func TestImport(t *testing.T) {
+	importer, quoteRepo := newTestImporter(t)
+
+	summary, err := importer.Import(strings.NewReader(sampleClippings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Summary{Created: 2, Attached: 1, Skipped: 1, AuthorsCreated: 1, BooksCreated: 2}
+	if got := counts(summary); !reflect.DeepEqual(got, want) {
+		t.Errorf("resumo %+v, quer %+v", got, want)
+	}
+
+	quotes, err := quoteRepo.FindByQuery(repository.QuoteQuery{Order: repository.QuoteOrderLocation, Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("%d citações, quer 2", len(quotes))
+	}
+
+	capitu := quotes[0]
+	if capitu.Text != "Capitu, olhos de ressaca" || capitu.Book.Title != "Dom Casmurro" {
+		t.Errorf("primeira citação: %q de %q", capitu.Text, capitu.Book.Title)
+	}
+	if len(capitu.Annotations) != 1 || capitu.Annotations[0].Text != "Releia" {
+		t.Errorf("anotações do destaque: %+v", capitu.Annotations)
+	}
+	if *capitu.LocationStart != 120 || *capitu.LocationEnd != 125 {
+		t.Errorf("posição %d-%d, quer 120-125", *capitu.LocationStart, *capitu.LocationEnd)
+	}
+	if capitu.CreatedAt.Year() != 2024 {
+		t.Errorf("created_at = %v, quer a data da entrada", capitu.CreatedAt)
+	}
+}
+
+func TestImport_Idempotent(t *testing.T) {
+	importer, quoteRepo := newTestImporter(t)
+
+	if _, err := importer.Import(strings.NewReader(sampleClippings)); err != nil {
+		t.Fatal(err)
+	}
+	before, err := quoteRepo.FindByQuery(repository.QuoteQuery{Order: repository.QuoteOrderLocation, Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := importer.Import(strings.NewReader(sampleClippings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := counts(summary)
+	want := Summary{Duplicates: 3, Skipped: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resumo da reimportação %+v, quer %+v", got, want)
+	}
+	for _, entry := range summary.Entries {
+		if entry.Status == StatusDuplicate && entry.QuoteID == 0 {
+			t.Errorf("entrada %d duplicada sem a citação existente", entry.Index)
+		}
+	}
+
+	after, err := quoteRepo.FindByQuery(repository.QuoteQuery{Order: repository.QuoteOrderLocation, Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("%d citações depois de reimportar, quer %d", len(after), len(before))
+	}
+	for i := range after {
+		if after[i].ID != before[i].ID || len(after[i].Annotations) != len(before[i].Annotations) {
+			t.Errorf("citação %d mudou ao reimportar", before[i].ID)
+		}
+	}
+}
+
+func TestImport_FailedEntryKeepsTheRest(t *testing.T) {
+	importer, quoteRepo := newTestImporter(t)
+
+	input := "Livro sem metadados\n==========\n" + sampleClippings
+	summary, err := importer.Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Failed != 1 || summary.Entries[0].Status != StatusFailed {
+		t.Errorf("resumo %+v, quer a primeira entrada com falha", counts(summary))
+	}
+	total, err := quoteRepo.CountByQuery(repository.QuoteQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Errorf("%d citações, quer 2", total)
+	}
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clippingLocale descreve como o Kindle escreve a linha de metadados em um idioma
+type clippingLocale struct {
+	name     string
+	kind     *regexp.Regexp
+	kinds    map[string]ClippingKind
+	page     *regexp.Regexp
+	location *regexp.Regexp
+	added    *regexp.Regexp
+	months   map[string]time.Month
+}
+
+// clippingLocales é percorrido em ordem; o primeiro idioma cujo tipo de
+// entrada casar com a linha de metadados é usado para interpretá-la
+var clippingLocales = []clippingLocale{
+	{
+		name:     "en",
+		kind:     regexp.MustCompile(`(?i)^-\s*(?:your\s+)?(highlight|note|bookmark)`),
+		kinds:    map[string]ClippingKind{"highlight": KindHighlight, "note": KindNote, "bookmark": KindBookmark},
+		page:     regexp.MustCompile(`(?i)\bpage\s+([0-9ivxlcdm]+)`),
+		location: regexp.MustCompile(`(?i)\b(?:location|loc\.)\s+(\d+)(?:-(\d+))?`),
+		added:    regexp.MustCompile(`(?i)\badded on\s+(.+)$`),
+		months: map[string]time.Month{
+			"january": time.January, "february": time.February, "march": time.March,
+			"april": time.April, "may": time.May, "june": time.June,
+			"july": time.July, "august": time.August, "september": time.September,
+			"october": time.October, "november": time.November, "december": time.December,
+		},
+	},
+	{
+		name:     "pt-BR",
+		kind:     regexp.MustCompile(`(?i)^-\s*(?:seu|sua)\s+(destaque|nota|marcador)`),
+		kinds:    map[string]ClippingKind{"destaque": KindHighlight, "nota": KindNote, "marcador": KindBookmark},
+		page:     regexp.MustCompile(`(?i)\bpágina\s+([0-9ivxlcdm]+)`),
+		location: regexp.MustCompile(`(?i)\bposição\s+(\d+)(?:-(\d+))?`),
+		added:    regexp.MustCompile(`(?i)\badicionado:?\s+(?:em\s+)?(.+)$`),
+		months: map[string]time.Month{
+			"janeiro": time.January, "fevereiro": time.February, "março": time.March,
+			"abril": time.April, "maio": time.May, "junho": time.June,
+			"julho": time.July, "agosto": time.August, "setembro": time.September,
+			"outubro": time.October, "novembro": time.November, "dezembro": time.December,
+		},
+	},
+	{
+		name:     "es",
+		kind:     regexp.MustCompile(`(?i)^-\s*tu\s+(subrayado|nota|marcador)`),
+		kinds:    map[string]ClippingKind{"subrayado": KindHighlight, "nota": KindNote, "marcador": KindBookmark},
+		page:     regexp.MustCompile(`(?i)\bpágina\s+([0-9ivxlcdm]+)`),
+		location: regexp.MustCompile(`(?i)\bposición\s+(\d+)(?:-(\d+))?`),
+		added:    regexp.MustCompile(`(?i)\bañadido el\s+(.+)$`),
+		months: map[string]time.Month{
+			"enero": time.January, "febrero": time.February, "marzo": time.March,
+			"abril": time.April, "mayo": time.May, "junio": time.June,
+			"julio": time.July, "agosto": time.August, "septiembre": time.September, "setiembre": time.September,
+			"octubre": time.October, "noviembre": time.November, "diciembre": time.December,
+		},
+	},
+	{
+		name:     "de",
+		kind:     regexp.MustCompile(`(?i)^-\s*(?:ihre?|deine?)\s+(markierung|notiz|lesezeichen)`),
+		kinds:    map[string]ClippingKind{"markierung": KindHighlight, "notiz": KindNote, "lesezeichen": KindBookmark},
+		page:     regexp.MustCompile(`(?i)\bseite\s+([0-9ivxlcdm]+)`),
+		location: regexp.MustCompile(`(?i)\bposition\s+(\d+)(?:-(\d+))?`),
+		added:    regexp.MustCompile(`(?i)\bhinzugefügt am\s+(.+)$`),
+		months: map[string]time.Month{
+			"januar": time.January, "jänner": time.January, "februar": time.February, "märz": time.March,
+			"april": time.April, "mai": time.May, "juni": time.June,
+			"juli": time.July, "august": time.August, "september": time.September,
+			"oktober": time.October, "november": time.November, "dezember": time.December,
+		},
+	},
+	{
+		name:     "fr",
+		kind:     regexp.MustCompile(`(?i)^-\s*votre\s+(surlignement|note|signet)`),
+		kinds:    map[string]ClippingKind{"surlignement": KindHighlight, "note": KindNote, "signet": KindBookmark},
+		page:     regexp.MustCompile(`(?i)\bpage\s+([0-9ivxlcdm]+)`),
+		location: regexp.MustCompile(`(?i)\bemplacement\s+(\d+)(?:-(\d+))?`),
+		added:    regexp.MustCompile(`(?i)\bajouté le\s+(.+)$`),
+		months: map[string]time.Month{
+			"janvier": time.January, "février": time.February, "mars": time.March,
+			"avril": time.April, "mai": time.May, "juin": time.June,
+			"juillet": time.July, "août": time.August, "septembre": time.September,
+			"octobre": time.October, "novembre": time.November, "décembre": time.December,
+		},
+	},
+	{
+		name:     "ja",
+		kind:     regexp.MustCompile(`(ハイライト|メモ|ブックマーク)`),
+		kinds:    map[string]ClippingKind{"ハイライト": KindHighlight, "メモ": KindNote, "ブックマーク": KindBookmark},
+		page:     regexp.MustCompile(`(\d+)\s*ページ`),
+		location: regexp.MustCompile(`位置No\.\s*(\d+)(?:-(\d+))?`),
+		added:    regexp.MustCompile(`作成日[:：]\s*(.+)$`),
+	},
+}
+
+// englishDateLayouts são os formatos de data usados pelo Kindle em inglês. Os
+// sem horário vêm de arquivos editados à mão ou de ferramentas que o descartam
+var englishDateLayouts = []string{
+	"Monday, January 2, 2006 3:04:05 PM",
+	"Monday, January 2, 2006, 3:04:05 PM",
+	"Monday, 2 January 2006 15:04:05",
+	"Monday, 2 January 2006 3:04:05 PM",
+	"Monday, January 2, 2006",
+	"Monday, 2 January 2006",
+}
+
+var (
+	// "sexta-feira, 3 de maio de 2024 10:15:22", "Montag, 6. Mai 2024 10:15:22",
+	// "lundi 6 mai 2024 10:15:22", "lunes, 6 de mayo de 2024 1:15:22 p. m.".
+	// O horário é opcional: "sexta-feira, 3 de maio de 2024"
+	dayMonthYearRegex = regexp.MustCompile(`(?i)(\d{1,2})\.?\s+(?:de\s+)?(\p{L}+)\.?,?\s+(?:de\s+)?(\d{4})(?:,?\s+(\d{1,2}):(\d{2})(?::(\d{2}))?(?:\s*([ap])\.?\s?m\.?)?)?`)
+	// "2024年5月6日月曜日 10:15:22", "2024年5月6日 月曜日 午後1:15:22", "2024年5月6日"
+	japaneseDateRegex = regexp.MustCompile(`(\d{4})年(\d{1,2})月(\d{1,2})日(?:\D*?(午前|午後)?\s*(\d{1,2}):(\d{2})(?::(\d{2}))?)?`)
+)
+
+// detectLocale retorna o idioma da linha de metadados e o tipo de entrada
+func detectLocale(line string) (*clippingLocale, ClippingKind, bool) {
+	for i := range clippingLocales {
+		locale := &clippingLocales[i]
+
+		match := locale.kind.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		if kind, ok := locale.kinds[strings.ToLower(match[1])]; ok {
+			return locale, kind, true
+		}
+	}
+
+	return nil, "", false
+}
+
+// parseDate converte a data localizada para o horário local, o mesmo usado
+// pelo Kindle ao registrar a entrada
+func (l *clippingLocale) parseDate(value string) (time.Time, error) {
+	if l.name == "en" {
+		for _, layout := range englishDateLayouts {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	if l.name == "ja" {
+		return parseJapaneseDate(value)
+	}
+
+	return l.parseDayMonthYear(value)
+}
+
+func (l *clippingLocale) parseDayMonthYear(value string) (time.Time, error) {
+	match := dayMonthYearRegex.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("data não reconhecida: %q", value)
+	}
+
+	month, ok := l.months[strings.ToLower(match[2])]
+	if !ok {
+		return time.Time{}, fmt.Errorf("mês não reconhecido: %q", match[2])
+	}
+
+	day, _ := strconv.Atoi(match[1])
+	year, _ := strconv.Atoi(match[3])
+	hour, _ := strconv.Atoi(match[4])
+	minute, _ := strconv.Atoi(match[5])
+	second, _ := strconv.Atoi(match[6])
+
+	hour = to24Hour(hour, strings.ToLower(match[7]) == "p", match[7] != "")
+
+	return time.Date(year, month, day, hour, minute, second, 0, time.Local), nil
+}
+
+func parseJapaneseDate(value string) (time.Time, error) {
+	match := japaneseDateRegex.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("data não reconhecida: %q", value)
+	}
+
+	year, _ := strconv.Atoi(match[1])
+	month, _ := strconv.Atoi(match[2])
+	day, _ := strconv.Atoi(match[3])
+	hour, _ := strconv.Atoi(match[5])
+	minute, _ := strconv.Atoi(match[6])
+	second, _ := strconv.Atoi(match[7])
+
+	hour = to24Hour(hour, match[4] == "午後", match[4] != "")
+
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.Local), nil
+}
+
+func to24Hour(hour int, pm, twelveHour bool) int {
+	if !twelveHour {
+		return hour
+	}
+	if hour == 12 {
+		hour = 0
+	}
+	if pm {
+		hour += 12
+	}
+	return hour
+}
@@ -0,0 +1,202 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMetadata_Locales(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	date := func(hour, minute, second int) time.Time {
+		return time.Date(2024, time.May, 3, hour, minute, second, 0, time.Local)
+	}
+
+	tests := []struct {
+		name    string
+		line    string
+		kind    ClippingKind
+		page    *int
+		start   *int
+		end     *int
+		addedAt time.Time
+	}{
+		{
+			name:    "en 12 horas",
+			line:    "- Your Highlight on page 12 | location 180-183 | Added on Friday, May 3, 2024 10:15:22 PM",
+			kind:    KindHighlight,
+			page:    intPtr(12),
+			start:   intPtr(180),
+			end:     intPtr(183),
+			addedAt: date(22, 15, 22),
+		},
+		{
+			name:    "en 24 horas",
+			line:    "- Your Note on Location 183 | Added on Friday, 3 May 2024 22:15:22",
+			kind:    KindNote,
+			start:   intPtr(183),
+			end:     intPtr(183),
+			addedAt: date(22, 15, 22),
+		},
+		{
+			name:    "en sem horário",
+			line:    "- Your Bookmark on page 5 | Added on Friday, May 3, 2024",
+			kind:    KindBookmark,
+			page:    intPtr(5),
+			addedAt: date(0, 0, 0),
+		},
+		{
+			name:    "pt-BR",
+			line:    "- Seu destaque na página 10 | posição 123-125 | Adicionado: sexta-feira, 3 de maio de 2024 10:15:22",
+			kind:    KindHighlight,
+			page:    intPtr(10),
+			start:   intPtr(123),
+			end:     intPtr(125),
+			addedAt: date(10, 15, 22),
+		},
+		{
+			name:    "pt-BR sem horário",
+			line:    "- Seu destaque ou posição 123-125 | Adicionado: sexta-feira, 3 de maio de 2024",
+			kind:    KindHighlight,
+			start:   intPtr(123),
+			end:     intPtr(125),
+			addedAt: date(0, 0, 0),
+		},
+		{
+			name:    "pt-BR nota",
+			line:    "- Sua nota na posição 125 | Adicionado: sexta-feira, 3 de maio de 2024 10:15:22",
+			kind:    KindNote,
+			start:   intPtr(125),
+			end:     intPtr(125),
+			addedAt: date(10, 15, 22),
+		},
+		{
+			name:    "es p. m.",
+			line:    "- Tu subrayado en la página 4 | posición 51-52 | Añadido el viernes, 3 de mayo de 2024 1:15:22 p. m.",
+			kind:    KindHighlight,
+			page:    intPtr(4),
+			start:   intPtr(51),
+			end:     intPtr(52),
+			addedAt: date(13, 15, 22),
+		},
+		{
+			name:    "es a. m. à meia-noite",
+			line:    "- Tu nota en la posición 52 | Añadido el viernes, 3 de mayo de 2024 12:05:00 a. m.",
+			kind:    KindNote,
+			start:   intPtr(52),
+			end:     intPtr(52),
+			addedAt: date(0, 5, 0),
+		},
+		{
+			name:    "es sem horário",
+			line:    "- Tu marcador en la posición 51 | Añadido el viernes, 3 de mayo de 2024",
+			kind:    KindBookmark,
+			start:   intPtr(51),
+			end:     intPtr(51),
+			addedAt: date(0, 0, 0),
+		},
+		{
+			name:    "de",
+			line:    "- Ihre Markierung auf Seite 7 | Position 99-101 | Hinzugefügt am Freitag, 3. Mai 2024 10:15:22",
+			kind:    KindHighlight,
+			page:    intPtr(7),
+			start:   intPtr(99),
+			end:     intPtr(101),
+			addedAt: date(10, 15, 22),
+		},
+		{
+			name:    "de sem horário",
+			line:    "- Ihre Notiz bei Position 101 | Hinzugefügt am Freitag, 3. Mai 2024",
+			kind:    KindNote,
+			start:   intPtr(101),
+			end:     intPtr(101),
+			addedAt: date(0, 0, 0),
+		},
+		{
+			name:    "fr",
+			line:    "- Votre surlignement sur la page 3 | emplacement 40-41 | Ajouté le vendredi 3 mai 2024 10:15:22",
+			kind:    KindHighlight,
+			page:    intPtr(3),
+			start:   intPtr(40),
+			end:     intPtr(41),
+			addedAt: date(10, 15, 22),
+		},
+		{
+			name:    "fr sem horário",
+			line:    "- Votre signet à l'emplacement 41 | Ajouté le vendredi 3 mai 2024",
+			kind:    KindBookmark,
+			start:   intPtr(41),
+			end:     intPtr(41),
+			addedAt: date(0, 0, 0),
+		},
+		{
+			name:    "ja",
+			line:    "- 12ページ|位置No. 180-183のハイライト |作成日: 2024年5月3日金曜日 午後10:15:22",
+			kind:    KindHighlight,
+			page:    intPtr(12),
+			start:   intPtr(180),
+			end:     intPtr(183),
+			addedAt: date(22, 15, 22),
+		},
+		{
+			name:    "ja sem horário",
+			line:    "- 位置No. 183のメモ |作成日: 2024年5月3日",
+			kind:    KindNote,
+			start:   intPtr(183),
+			end:     intPtr(183),
+			addedAt: date(0, 0, 0),
+		},
+		{
+			name:    "abreviação de posição dos Kindles antigos",
+			line:    "- Highlight Loc. 1234-40 | Added on Friday, May 3, 2024, 10:15:22 AM",
+			kind:    KindHighlight,
+			start:   intPtr(1234),
+			end:     intPtr(1240),
+			addedAt: date(10, 15, 22),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clipping Clipping
+			if err := parseMetadata(tt.line, &clipping); err != nil {
+				t.Fatal(err)
+			}
+
+			if clipping.Kind != tt.kind {
+				t.Errorf("kind = %q, quer %q", clipping.Kind, tt.kind)
+			}
+			checkInt(t, "page", clipping.Page, tt.page)
+			checkInt(t, "location_start", clipping.LocationStart, tt.start)
+			checkInt(t, "location_end", clipping.LocationEnd, tt.end)
+			if !clipping.AddedAt.Equal(tt.addedAt) {
+				t.Errorf("added_at = %v, quer %v", clipping.AddedAt, tt.addedAt)
+			}
+		})
+	}
+}
+
+func TestParseMetadata_Invalid(t *testing.T) {
+	tests := []string{
+		"- Something else | Added on Friday, May 3, 2024",
+		"- Seu destaque na posição 10 | Adicionado: sexta-feira, 3 de floreal de 2024",
+		"- Your Highlight on location 10 | Added on yesterday",
+	}
+
+	for _, line := range tests {
+		var clipping Clipping
+		if err := parseMetadata(line, &clipping); err == nil {
+			t.Errorf("%q: esperava erro", line)
+		}
+	}
+}
+
+func checkInt(t *testing.T, name string, got, want *int) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, quer %v", name, got, want)
+	case *got != *want:
+		t.Errorf("%s = %d, quer %d", name, *got, *want)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"quote-api/backup"
+	"quote-api/database"
+	"quote-api/dedupe"
+	"quote-api/exporter"
+	"quote-api/handlers"
+	"quote-api/importer"
+	"quote-api/repository"
+	"quote-api/service"
+	"syscall"
+	"time"
+)
+
+func main() {
+	reset := flag.Bool("reset", false, "remove todas as tabelas e recria o schema")
+	seed := flag.Bool("seed", false, "insere dados de exemplo")
+	dsn := flag.String("db", database.DefaultDSN, "arquivo SQLite ou DSN do banco")
+	addr := flag.String("addr", ":8080", "endereço do servidor HTTP")
+	migrateTo := flag.Int("migrate-to", -1, "migra o schema até a versão informada e encerra")
+	rollback := flag.Int("rollback", 0, "reverte as N últimas migrations e encerra")
+	migrations := flag.Bool("migrations", false, "lista as migrations e encerra")
+	dailyTimezone := flag.String("daily-timezone", "Local", "fuso horário que define o dia da citação do dia (ex.: America/Sao_Paulo)")
+	dailyWindow := flag.Int("daily-window", 30, "dias em que uma citação do dia não se repete no mesmo escopo")
+	exportMarkdown := flag.String("export-markdown", "", "exporta um arquivo Markdown por livro para o diretório informado e encerra")
+	exportObsidian := flag.String("export-obsidian", "", "sincroniza as notas de livros e autores com a pasta do Obsidian informada e encerra")
+	exportAnki := flag.String("export-anki", "", "exporta as citações para o Anki no arquivo informado (.apkg ou .txt) e encerra")
+	ankiDeck := flag.String("anki-deck", exporter.DefaultAnkiDeck, "baralho raiz da exportação para o Anki")
+	ankiCloze := flag.Bool("anki-cloze", false, "gera cartões de omissão (cloze) na exportação para o Anki")
+	ankiFavorites := flag.Bool("anki-favorites", false, "exporta para o Anki só as citações favoritas")
+	exportTemplate := flag.String("export-template", "", "template do nome dos arquivos dos livros exportados (padrão: "+
+		exporter.DefaultFileName+" no Markdown, "+exporter.DefaultObsidianFileName+" no Obsidian)")
+	exportBackup := flag.String("export-backup", "", "exporta toda a biblioteca para o arquivo (jsonl) ou diretório (csv) informado e encerra; - grava o JSONL na saída padrão")
+	importBackup := flag.String("import-backup", "", "importa um backup gerado por -export-backup e encerra; - lê o JSONL da entrada padrão")
+	backupFormat := flag.String("backup-format", string(backup.FormatJSONL), "formato do backup: jsonl ou csv")
+	dryRun := flag.Bool("dry-run", false, "com -import-backup ou -dedupe, só relata o que seria feito, sem gravar nada")
+	importClippings := flag.String("import-clippings", "", "importa o arquivo My Clippings.txt do Kindle informado e encerra")
+	dedupeQuotes := flag.Bool("dedupe", false, "remove as citações repetidas e as versões antigas de destaques estendidos e encerra")
+	flag.Parse()
+
+	dailyLocation, err := time.LoadLocation(*dailyTimezone)
+	if err != nil {
+		log.Fatal("Fuso horário inválido: ", err)
+	}
+
+	migrationOnly := *migrateTo >= 0 || *rollback != 0 || *migrations
+
+	store, err := database.Open(database.Config{
+		DSN:            *dsn,
+		SkipMigrations: migrationOnly || *reset,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	if migrationOnly {
+		if err := runMigrationCommand(store, *migrateTo, *rollback); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *reset {
+		if err := store.DropAllTables(); err != nil {
+			log.Fatal(err)
+		}
+		if err := store.Migrate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *seed {
+		if err := store.Seed(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	authorRepo := repository.NewAuthorRepository(store)
+	bookRepo := repository.NewBookRepository(store)
+	categoryRepo := repository.NewCategoryRepository(store)
+	quoteRepo := repository.NewQuoteRepository(store)
+	tagRepo := repository.NewTagRepository(store)
+	dailyQuoteRepo := repository.NewDailyQuoteRepository(store)
+
+	if *exportBackup != "" || *importBackup != "" {
+		if err := runBackupCommand(store, *exportBackup, *importBackup, backup.Format(*backupFormat), *dryRun); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *importClippings != "" {
+		if err := runImportCommand(store, *importClippings); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *dedupeQuotes {
+		if err := runDedupeCommand(store, *dryRun); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *exportAnki != "" {
+		summary, err := exporter.NewAnkiExporter(bookRepo, quoteRepo, exporter.AnkiOptions{
+			Deck:  *ankiDeck,
+			Cloze: *ankiCloze,
+			Query: repository.QuoteQuery{FavoritesOnly: *ankiFavorites},
+		}).ExportFile(*exportAnki)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Exportação para o Anki concluída: %d notas, %d cartões, %d baralhos", summary.Notes, summary.Cards, summary.Decks)
+		return
+	}
+
+	if *exportMarkdown != "" || *exportObsidian != "" {
+		if err := runExportCommand(bookRepo, quoteRepo, *exportMarkdown, *exportObsidian, *exportTemplate); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	router := handlers.NewRouter(
+		handlers.NewAuthorHandler(service.NewAuthorService(authorRepo, bookRepo)),
+		handlers.NewBookHandler(service.NewBookService(bookRepo, authorRepo, categoryRepo)),
+		handlers.NewCategoryHandler(service.NewCategoryService(categoryRepo, bookRepo)),
+		handlers.NewQuoteHandler(service.NewQuoteService(quoteRepo, bookRepo)),
+		handlers.NewTagHandler(service.NewTagService(tagRepo, quoteRepo)),
+		handlers.NewDailyQuoteHandler(service.NewDailyQuoteService(dailyQuoteRepo, quoteRepo, service.DailyQuoteConfig{
+			Location: dailyLocation,
+			Window:   *dailyWindow,
+		})),
+	)
+
+	server := handlers.NewServer(*addr, router)
+
+	go func() {
+		log.Println("Servidor ouvindo em", *addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Erro no servidor HTTP:", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Encerrando servidor...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("Erro ao encerrar servidor:", err)
+	}
+}
+
+// runMigrationCommand atende às flags -migrate-to, -rollback e -migrations
+func runMigrationCommand(store *database.Store, migrateTo, rollback int) error {
+	switch {
+	case migrateTo >= 0:
+		if err := store.MigrateTo(migrateTo); err != nil {
+			return err
+		}
+	case rollback != 0:
+		if err := store.Rollback(rollback); err != nil {
+			return err
+		}
+	}
+	return store.PrintMigrationStatus()
+}
+
+// runImportCommand atende à flag -import-clippings
+func runImportCommand(store *database.Store, path string) error {
+	clippingImporter := importer.NewImporter(store,
+		repository.NewAuthorRepository(store),
+		repository.NewBookRepository(store),
+		repository.NewQuoteRepository(store),
+		repository.NewAnnotationRepository(store),
+		repository.NewTagRepository(store),
+	)
+
+	summary, err := clippingImporter.ImportFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range summary.Entries {
+		if entry.Status == importer.StatusFailed {
+			log.Printf("entrada %d (%s): %s", entry.Index, entry.Title, entry.Reason)
+		}
+	}
+	log.Printf("Importação concluída: %d criadas, %d notas anexadas, %d duplicadas, %d mescladas, %d ignoradas, %d com falha; %d autores e %d livros novos",
+		summary.Created, summary.Attached, summary.Duplicates, summary.Merged, summary.Skipped, summary.Failed,
+		summary.AuthorsCreated, summary.BooksCreated)
+	return nil
+}
+
+// runDedupeCommand atende à flag -dedupe
+func runDedupeCommand(store *database.Store, dryRun bool) error {
+	deduplicator := dedupe.NewDeduplicator(store,
+		repository.NewQuoteRepository(store),
+		repository.NewAnnotationRepository(store),
+		repository.NewTagRepository(store),
+	)
+
+	report, err := deduplicator.Run(dryRun)
+	if err != nil {
+		return err
+	}
+
+	if report.DryRun {
+		log.Print("Simulação da deduplicação; nada foi gravado")
+	}
+	for _, merge := range report.Merges {
+		log.Printf("livro %d: citação %d removida, mantida %d (%s)", merge.BookID, merge.RemovedID, merge.KeptID, merge.Relation)
+	}
+	log.Printf("Deduplicação concluída: %d livros e %d citações verificados, %d citações removidas",
+		report.BooksScanned, report.QuotesScanned, len(report.Merges))
+	return nil
+}
+
+// runExportCommand atende às flags -export-markdown e -export-obsidian
+func runExportCommand(bookRepo repository.Books, quoteRepo repository.Quotes, markdownDir, obsidianDir, fileNameTemplate string) error {
+	var fileName *exporter.FileNameTemplate
+	if fileNameTemplate != "" {
+		var err error
+		if fileName, err = exporter.ParseFileName(fileNameTemplate); err != nil {
+			return err
+		}
+	}
+
+	if markdownDir != "" {
+		report, err := exporter.NewMarkdownExporter(bookRepo, quoteRepo, fileName).Export(markdownDir)
+		if err != nil {
+			return err
+		}
+		printExportReport(report)
+	}
+
+	if obsidianDir != "" {
+		report, err := exporter.NewObsidianExporter(bookRepo, quoteRepo, fileName).Export(obsidianDir)
+		if err != nil {
+			return err
+		}
+		printExportReport(report)
+	}
+
+	return nil
+}
+
+// runBackupCommand atende às flags -export-backup e -import-backup
+func runBackupCommand(store *database.Store, exportPath, importPath string, format backup.Format, dryRun bool) error {
+	if !format.Valid() {
+		return fmt.Errorf("formato de backup desconhecido: %q", format)
+	}
+	if exportPath != "" && importPath != "" {
+		return errors.New("use -export-backup ou -import-backup, não os dois")
+	}
+	if (exportPath == "-" || importPath == "-") && format != backup.FormatJSONL {
+		return errors.New("a saída e a entrada padrão só aceitam o formato jsonl")
+	}
+
+	authorRepo := repository.NewAuthorRepository(store)
+	bookRepo := repository.NewBookRepository(store)
+	categoryRepo := repository.NewCategoryRepository(store)
+	quoteRepo := repository.NewQuoteRepository(store)
+	tagRepo := repository.NewTagRepository(store)
+
+	if exportPath != "" {
+		backupExporter := backup.NewExporter(store, authorRepo, bookRepo, categoryRepo, quoteRepo, tagRepo)
+
+		var counts *backup.Counts
+		var err error
+		if exportPath == "-" {
+			counts, err = backupExporter.ExportJSONL(os.Stdout)
+		} else {
+			counts, err = backupExporter.Export(format, exportPath)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Backup exportado: %s", formatBackupCounts(*counts))
+		return nil
+	}
+
+	backupImporter := backup.NewImporter(store, authorRepo, bookRepo, categoryRepo, quoteRepo, tagRepo,
+		repository.NewAnnotationRepository(store))
+
+	var report *backup.ImportReport
+	var err error
+	if importPath == "-" {
+		report, err = backupImporter.ImportJSONL(os.Stdin, dryRun)
+	} else {
+		report, err = backupImporter.Import(format, importPath, dryRun)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.DryRun {
+		log.Print("Simulação da importação; nada foi gravado")
+	}
+	log.Printf("Novos: %s", formatBackupCounts(report.Created))
+	log.Printf("Já existentes: %s", formatBackupCounts(report.Matched))
+	return nil
+}
+
+func formatBackupCounts(counts backup.Counts) string {
+	return fmt.Sprintf("%d categorias, %d autores, %d tags, %d livros, %d citações, %d anotações",
+		counts.Categories, counts.Authors, counts.Tags, counts.Books, counts.Quotes, counts.Annotations)
+}
+
+// printExportReport resume uma exportação no log
+func printExportReport(report *exporter.Report) {
+	for _, file := range report.Files {
+		if file.Status != exporter.StatusUnchanged {
+			log.Printf("%-9s %s", file.Status, file.Path)
+		}
+	}
+	log.Printf("Exportação concluída: %d criados, %d atualizados, %d sem mudanças, %d removidos, %d mantidos",
+		report.Created, report.Updated, report.Unchanged, report.Removed, report.Kept)
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Annotation é uma nota pessoal do leitor presa a uma citação
+type Annotation struct {
+	ID        int64
+	QuoteID   int64
+	Text      string
+	Location  *int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
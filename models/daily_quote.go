@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// DailyQuote registra a citação do dia escolhida para um escopo. Day é a data
+// (2006-01-02) no fuso configurado e Scope identifica o recorte sorteado:
+// "all", "category:3", "author:2" ou "tag:5"
+type DailyQuote struct {
+	ID        int64
+	Day       string
+	Scope     string
+	QuoteID   int64
+	CreatedAt time.Time
+
+	Quote *Quote
+}
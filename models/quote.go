@@ -2,12 +2,44 @@ package models
 
 import "time"
 
+type QuoteKind string
+
+const (
+	QuoteKindHighlight QuoteKind = "highlight"
+	QuoteKindNote      QuoteKind = "note"
+	QuoteKindBookmark  QuoteKind = "bookmark"
+)
+
+// Limites da avaliação em estrelas de uma citação
+const (
+	MinQuoteRating = 1
+	MaxQuoteRating = 5
+)
+
 type Quote struct {
-	ID        int64
-	BookID    int64
-	Text      string
+	ID            int64
+	BookID        int64
+	Text          string
+	Kind          QuoteKind
+	Page          *int
+	LocationStart *int
+	LocationEnd   *int
+	Favorite      bool
+	// Rating vai de MinQuoteRating a MaxQuoteRating; nil quando não avaliada
+	Rating    *int
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	Book *Book
+	Book        *Book
+	Annotations []Annotation
+	Tags        []Tag
+}
+
+// QuoteSearchResult é uma citação encontrada pela busca textual. Snippet traz
+// o trecho do texto, escapado como HTML, com os termos destacados e Score a
+// relevância (maior é melhor)
+type QuoteSearchResult struct {
+	Quote   Quote
+	Snippet string
+	Score   float64
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Tag é um rótulo livre aplicado a citações, independente das categorias dos
+// livros. QuoteCount só é preenchido nas listagens de tags
+type Tag struct {
+	ID         int64
+	Name       string
+	QuoteCount int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TagKey é a forma do nome usada para comparar tags, gravada em tag.name_key:
+// "Ação", "AÇÃO" e "ação" são a mesma tag. O COLLATE NOCASE do SQLite só
+// ignora maiúsculas em letras ASCII
+func TagKey(name string) string {
+	// passar por ToUpper antes junta letras com a mesma maiúscula, como "ς" e "σ"
+	return strings.ToLower(strings.ToUpper(name))
+}
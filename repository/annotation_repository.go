@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"quote-api/database"
+	"quote-api/models"
+	"time"
+)
+
+type AnnotationRepository struct {
+	db DBTX
+}
+
+func NewAnnotationRepository(store *database.Store) *AnnotationRepository {
+	return &AnnotationRepository{db: store.DB()}
+}
+
+// WithTx retorna uma cópia do repositório que executa as queries na transação tx
+func (r *AnnotationRepository) WithTx(tx *sql.Tx) *AnnotationRepository {
+	return &AnnotationRepository{db: tx}
+}
+
+// FindByID busca anotação por ID
+func (r *AnnotationRepository) FindByID(id int64) (*models.Annotation, error) {
+	query := `
+        SELECT id, quote_id, text, location, created_at, updated_at
+        FROM annotation
+        WHERE id = ?
+    `
+
+	var annotation models.Annotation
+	err := r.db.QueryRow(query, id).Scan(
+		&annotation.ID, &annotation.QuoteID, &annotation.Text, &annotation.Location,
+		&annotation.CreatedAt, &annotation.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &annotation, nil
+}
+
+// FindByQuoteID lista as anotações de uma citação
+func (r *AnnotationRepository) FindByQuoteID(quoteID int64) ([]models.Annotation, error) {
+	annotations, err := r.FindByQuoteIDs([]int64{quoteID})
+	if err != nil {
+		return nil, err
+	}
+
+	return annotations[quoteID], nil
+}
+
+// FindByQuoteIDs carrega as anotações de várias citações com uma query a cada
+// maxInList citações
+func (r *AnnotationRepository) FindByQuoteIDs(quoteIDs []int64) (map[int64][]models.Annotation, error) {
+	result := make(map[int64][]models.Annotation)
+	if len(quoteIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+        SELECT id, quote_id, text, location, created_at, updated_at
+        FROM annotation
+        WHERE quote_id IN (%s)
+        ORDER BY quote_id ASC, created_at ASC, id ASC
+    `
+
+	err := queryInChunks(r.db, query, quoteIDs, func(rows *sql.Rows) error {
+		var annotation models.Annotation
+		if err := rows.Scan(
+			&annotation.ID, &annotation.QuoteID, &annotation.Text, &annotation.Location,
+			&annotation.CreatedAt, &annotation.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		result[annotation.QuoteID] = append(result[annotation.QuoteID], annotation)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Create cria nova anotação. Se CreatedAt vier preenchido, é preservado
+func (r *AnnotationRepository) Create(annotation models.Annotation) (*models.Annotation, error) {
+	query := `
+        INSERT INTO annotation (quote_id, text, location, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?)
+    `
+
+	now := time.Now()
+	createdAt := annotation.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	result, err := r.db.Exec(query, annotation.QuoteID, annotation.Text, annotation.Location, createdAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(id)
+}
+
+// MoveToQuote transfere as anotações de uma citação para outra
+func (r *AnnotationRepository) MoveToQuote(fromQuoteID, toQuoteID int64) error {
+	_, err := r.db.Exec(
+		"UPDATE annotation SET quote_id = ?, updated_at = ? WHERE quote_id = ?",
+		toQuoteID, time.Now(), fromQuoteID,
+	)
+	return err
+}
+
+// Delete remove uma anotação
+func (r *AnnotationRepository) Delete(id int64) error {
+	result, err := r.db.Exec("DELETE FROM annotation WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -8,11 +8,16 @@ import (
 )
 
 type AuthorRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAuthorRepository() *AuthorRepository {
-	return &AuthorRepository{db: database.DB}
+func NewAuthorRepository(store *database.Store) *AuthorRepository {
+	return &AuthorRepository{db: store.DB()}
+}
+
+// WithTx retorna uma cópia do repositório que executa as queries na transação tx
+func (r *AuthorRepository) WithTx(tx *sql.Tx) *AuthorRepository {
+	return &AuthorRepository{db: tx}
 }
 
 func (r *AuthorRepository) FindAll(limit, offset int) ([]models.Author, error) {
@@ -87,31 +92,44 @@ func (r *AuthorRepository) FindByName(name string) (*models.Author, error) {
 }
 
 func (r *AuthorRepository) FindByBookID(bookID int64) ([]models.Author, error) {
+	authors, err := r.FindByBookIDs([]int64{bookID})
+	if err != nil {
+		return nil, err
+	}
+
+	return authors[bookID], nil
+}
+
+// FindByBookIDs carrega os autores de vários livros com uma query a cada
+// maxInList livros, na ordem de book_author
+func (r *AuthorRepository) FindByBookIDs(bookIDs []int64) (map[int64][]models.Author, error) {
+	result := make(map[int64][]models.Author)
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
 	query := `
-        SELECT a.id, a.name, a.created_at, a.updated_at
+        SELECT ba.book_id, a.id, a.name, a.created_at, a.updated_at
         FROM author a
         INNER JOIN book_author ba ON a.id = ba.author_id
-        WHERE ba.book_id = ?
-        ORDER BY ba.order ASC
+        WHERE ba.book_id IN (%s)
+        ORDER BY ba.book_id ASC, ba."order" ASC
     `
 
-	rows, err := r.db.Query(query, bookID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var authors []models.Author
-	for rows.Next() {
+	err := queryInChunks(r.db, query, bookIDs, func(rows *sql.Rows) error {
+		var bookID int64
 		var author models.Author
-		err := rows.Scan(&author.ID, &author.Name, &author.CreatedAt, &author.UpdatedAt)
-		if err != nil {
-			return nil, err
+		if err := rows.Scan(&bookID, &author.ID, &author.Name, &author.CreatedAt, &author.UpdatedAt); err != nil {
+			return err
 		}
-		authors = append(authors, author)
+		result[bookID] = append(result[bookID], author)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return authors, nil
+	return result, nil
 }
 
 func (r *AuthorRepository) Create(author models.Author) (*models.Author, error) {
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"quote-api/models"
+	"strings"
+)
+
+// BookQuery descreve uma listagem de livros, sempre ordenada por título.
+// Os filtros preenchidos são combinados com AND
+type BookQuery struct {
+	AuthorID   int64
+	CategoryID int
+	// Search procura no título, como Search
+	Search string
+
+	// Cursor continua a listagem a partir de uma página anterior e, quando
+	// presente, substitui Offset
+	Cursor *Cursor
+
+	Limit  int
+	Offset int
+}
+
+// BookOrderTitle identifica nos cursores a única ordenação de livros
+const BookOrderTitle = "title"
+
+var bookSortKeys = []sortKey{{"b.title", false}, {"b.id", false}}
+
+func (q BookQuery) from(keys ...sortKey) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if q.AuthorID != 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM book_author ba WHERE ba.book_id = b.id AND ba.author_id = ?)")
+		args = append(args, q.AuthorID)
+	}
+	if q.CategoryID != 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM book_category bc WHERE bc.book_id = b.id AND bc.category_id = ?)")
+		args = append(args, q.CategoryID)
+	}
+	if q.Search != "" {
+		conditions = append(conditions, "b.title LIKE ?")
+		args = append(args, "%"+q.Search+"%")
+	}
+
+	if q.Cursor != nil && len(keys) > 0 {
+		condition, keyArgs := keysetCondition(keys, q.Cursor.Keys, q.Cursor.Before)
+		conditions = append(conditions, condition)
+		args = append(args, keyArgs...)
+	}
+
+	from := " FROM book b"
+	if len(conditions) > 0 {
+		from += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return from, args
+}
+
+// FindPageByQuery lista uma página de livros com os cursores das páginas
+// vizinhas. Sem Cursor, a página começa em Offset
+func (r *BookRepository) FindPageByQuery(query BookQuery) (Page[models.Book], error) {
+	if query.Cursor != nil && (query.Cursor.Order != BookOrderTitle || len(query.Cursor.Keys) != len(bookSortKeys)) {
+		return Page[models.Book]{}, ErrInvalidCursor
+	}
+
+	from, args := query.from(bookSortKeys...)
+
+	orderBy := keysetOrder(bookSortKeys, false)
+	offset := query.Offset
+	if query.Cursor != nil {
+		orderBy = keysetOrder(bookSortKeys, query.Cursor.Before)
+		offset = 0
+	}
+
+	statement := `
+        SELECT b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at, ` +
+		keyColumns(bookSortKeys) + from + `
+        ORDER BY ` + orderBy + `
+        LIMIT ? OFFSET ?
+    `
+
+	rows, err := r.db.Query(statement, append(args, fetchLimit(query.Limit), offset)...)
+	if err != nil {
+		return Page[models.Book]{}, err
+	}
+	defer rows.Close()
+
+	var books []models.Book
+	var rowKeys [][]interface{}
+	for rows.Next() {
+		var book models.Book
+		values, dest := keyDest(len(bookSortKeys))
+		err := rows.Scan(append([]interface{}{
+			&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
+			&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
+		}, dest...)...)
+		if err != nil {
+			return Page[models.Book]{}, err
+		}
+		books = append(books, book)
+		rowKeys = append(rowKeys, values)
+	}
+	if err := rows.Err(); err != nil {
+		return Page[models.Book]{}, err
+	}
+	rows.Close()
+
+	page := pageOf(BookOrderTitle, books, rowKeys, query.Cursor, query.Limit, offset, true)
+	if err := r.attachRelations(page.Items); err != nil {
+		return Page[models.Book]{}, err
+	}
+
+	return page, nil
+}
+
+// CountByQuery conta os livros que FindPageByQuery listaria sem paginação
+func (r *BookRepository) CountByQuery(query BookQuery) (int, error) {
+	query.Cursor = nil
+	from, args := query.from()
+
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&count)
+	return count, err
+}
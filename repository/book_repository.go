@@ -8,16 +8,25 @@ import (
 )
 
 type BookRepository struct {
-	db           *sql.DB
+	db           DBTX
 	authorRepo   *AuthorRepository
 	categoryRepo *CategoryRepository
 }
 
-func NewBookRepository() *BookRepository {
+func NewBookRepository(store *database.Store) *BookRepository {
 	return &BookRepository{
-		db:           database.DB,
-		authorRepo:   NewAuthorRepository(),
-		categoryRepo: NewCategoryRepository(),
+		db:           store.DB(),
+		authorRepo:   NewAuthorRepository(store),
+		categoryRepo: NewCategoryRepository(store),
+	}
+}
+
+// WithTx retorna uma cópia do repositório que executa as queries na transação tx
+func (r *BookRepository) WithTx(tx *sql.Tx) *BookRepository {
+	return &BookRepository{
+		db:           tx,
+		authorRepo:   r.authorRepo.WithTx(tx),
+		categoryRepo: r.categoryRepo.WithTx(tx),
 	}
 }
 
@@ -26,35 +35,11 @@ func (r *BookRepository) FindAll(limit, offset int) ([]models.Book, error) {
 	query := `
         SELECT id, title, isbn, published_year, publisher, pages, created_at, updated_at
         FROM book
-        ORDER BY title ASC
+        ORDER BY title ASC, id ASC
         LIMIT ? OFFSET ?
     `
 
-	rows, err := r.db.Query(query, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var books []models.Book
-	for rows.Next() {
-		var book models.Book
-		err := rows.Scan(
-			&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-			&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Carrega autores e categorias
-		book.Authors, _ = r.authorRepo.FindByBookID(book.ID)
-		book.Categories, _ = r.categoryRepo.FindByBookID(book.ID)
-
-		books = append(books, book)
-	}
-
-	return books, nil
+	return r.queryBooks(query, limit, offset)
 }
 
 // FindByID busca livro por ID com autores e categorias
@@ -65,24 +50,7 @@ func (r *BookRepository) FindByID(id int64) (*models.Book, error) {
         WHERE id = ?
     `
 
-	var book models.Book
-	err := r.db.QueryRow(query, id).Scan(
-		&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-		&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	// Carrega autores e categorias
-	book.Authors, _ = r.authorRepo.FindByBookID(book.ID)
-	book.Categories, _ = r.categoryRepo.FindByBookID(book.ID)
-
-	return &book, nil
+	return r.queryBook(query, id)
 }
 
 // FindByISBN busca livro por ISBN
@@ -93,23 +61,20 @@ func (r *BookRepository) FindByISBN(isbn string) (*models.Book, error) {
         WHERE isbn = ?
     `
 
-	var book models.Book
-	err := r.db.QueryRow(query, isbn).Scan(
-		&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-		&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
+	return r.queryBook(query, isbn)
+}
 
-	book.Authors, _ = r.authorRepo.FindByBookID(book.ID)
-	book.Categories, _ = r.categoryRepo.FindByBookID(book.ID)
+// FindByTitle busca livro pelo título, ignorando maiúsculas/minúsculas
+func (r *BookRepository) FindByTitle(title string) (*models.Book, error) {
+	query := `
+        SELECT id, title, isbn, published_year, publisher, pages, created_at, updated_at
+        FROM book
+        WHERE LOWER(title) = LOWER(?)
+        ORDER BY id ASC
+        LIMIT 1
+    `
 
-	return &book, nil
+	return r.queryBook(query, title)
 }
 
 // FindByAuthorID busca livros de um autor
@@ -119,34 +84,11 @@ func (r *BookRepository) FindByAuthorID(authorID int64, limit, offset int) ([]mo
         FROM book b
         INNER JOIN book_author ba ON b.id = ba.book_id
         WHERE ba.author_id = ?
-        ORDER BY b.title ASC
+        ORDER BY b.title ASC, b.id ASC
         LIMIT ? OFFSET ?
     `
 
-	rows, err := r.db.Query(query, authorID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var books []models.Book
-	for rows.Next() {
-		var book models.Book
-		err := rows.Scan(
-			&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-			&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		book.Authors, _ = r.authorRepo.FindByBookID(book.ID)
-		book.Categories, _ = r.categoryRepo.FindByBookID(book.ID)
-
-		books = append(books, book)
-	}
-
-	return books, nil
+	return r.queryBooks(query, authorID, limit, offset)
 }
 
 // FindByCategoryID busca livros de uma categoria
@@ -156,82 +98,58 @@ func (r *BookRepository) FindByCategoryID(categoryID int, limit, offset int) ([]
         FROM book b
         INNER JOIN book_category bc ON b.id = bc.book_id
         WHERE bc.category_id = ?
-        ORDER BY b.title ASC
+        ORDER BY b.title ASC, b.id ASC
         LIMIT ? OFFSET ?
     `
 
-	rows, err := r.db.Query(query, categoryID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var books []models.Book
-	for rows.Next() {
-		var book models.Book
-		err := rows.Scan(
-			&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-			&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		book.Authors, _ = r.authorRepo.FindByBookID(book.ID)
-		book.Categories, _ = r.categoryRepo.FindByBookID(book.ID)
-
-		books = append(books, book)
-	}
-
-	return books, nil
+	return r.queryBooks(query, categoryID, limit, offset)
 }
 
 // Create cria livro com autores e categorias (usa transação)
 func (r *BookRepository) Create(book models.Book, authorIDs []int64, categoryIDs []int) (*models.Book, error) {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
-
-	// 1. Insere livro
-	query := `
-        INSERT INTO book (title, isbn, published_year, publisher, pages, created_at, updated_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?)
-    `
-
-	now := time.Now()
-	result, err := tx.Exec(query, book.Title, book.ISBN, book.PublishedYear,
-		book.Publisher, book.Pages, now, now)
-	if err != nil {
-		return nil, err
-	}
-
-	bookID, _ := result.LastInsertId()
-
-	// 2. Associa autores (com ordem)
-	for i, authorID := range authorIDs {
-		_, err = tx.Exec(
-			"INSERT INTO book_author (book_id, author_id, order) VALUES (?, ?, ?)",
-			bookID, authorID, i+1,
-		)
+	var bookID int64
+
+	err := runInTx(r.db, func(tx DBTX) error {
+		// 1. Insere livro
+		query := `
+            INSERT INTO book (title, isbn, published_year, publisher, pages, created_at, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?)
+        `
+
+		now := time.Now()
+		result, err := tx.Exec(query, book.Title, book.ISBN, book.PublishedYear,
+			book.Publisher, book.Pages, now, now)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	}
 
-	// 3. Associa categorias
-	for _, categoryID := range categoryIDs {
-		_, err = tx.Exec(
-			"INSERT INTO book_category (book_id, category_id) VALUES (?, ?)",
-			bookID, categoryID,
-		)
-		if err != nil {
-			return nil, err
+		bookID, _ = result.LastInsertId()
+
+		// 2. Associa autores (com ordem)
+		for i, authorID := range authorIDs {
+			_, err = tx.Exec(
+				`INSERT INTO book_author (book_id, author_id, "order") VALUES (?, ?, ?)`,
+				bookID, authorID, i+1,
+			)
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	if err = tx.Commit(); err != nil {
+		// 3. Associa categorias
+		for _, categoryID := range categoryIDs {
+			_, err = tx.Exec(
+				"INSERT INTO book_category (book_id, category_id) VALUES (?, ?)",
+				bookID, categoryID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -263,58 +181,50 @@ func (r *BookRepository) Update(id int64, book models.Book) (*models.Book, error
 
 // UpdateAuthors atualiza autores de um livro (usa transação)
 func (r *BookRepository) UpdateAuthors(bookID int64, authorIDs []int64) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// 1. Remove todos os autores atuais
-	_, err = tx.Exec("DELETE FROM book_author WHERE book_id = ?", bookID)
-	if err != nil {
-		return err
-	}
-
-	// 2. Adiciona novos autores
-	for i, authorID := range authorIDs {
-		_, err = tx.Exec(
-			"INSERT INTO book_author (book_id, author_id, order) VALUES (?, ?, ?)",
-			bookID, authorID, i+1,
-		)
+	return runInTx(r.db, func(tx DBTX) error {
+		// 1. Remove todos os autores atuais
+		_, err := tx.Exec("DELETE FROM book_author WHERE book_id = ?", bookID)
 		if err != nil {
 			return err
 		}
-	}
 
-	return tx.Commit()
+		// 2. Adiciona novos autores
+		for i, authorID := range authorIDs {
+			_, err = tx.Exec(
+				`INSERT INTO book_author (book_id, author_id, "order") VALUES (?, ?, ?)`,
+				bookID, authorID, i+1,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 // UpdateCategories atualiza categorias de um livro (usa transação)
 func (r *BookRepository) UpdateCategories(bookID int64, categoryIDs []int) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// 1. Remove todas as categorias atuais
-	_, err = tx.Exec("DELETE FROM book_category WHERE book_id = ?", bookID)
-	if err != nil {
-		return err
-	}
-
-	// 2. Adiciona novas categorias
-	for _, categoryID := range categoryIDs {
-		_, err = tx.Exec(
-			"INSERT INTO book_category (book_id, category_id) VALUES (?, ?)",
-			bookID, categoryID,
-		)
+	return runInTx(r.db, func(tx DBTX) error {
+		// 1. Remove todas as categorias atuais
+		_, err := tx.Exec("DELETE FROM book_category WHERE book_id = ?", bookID)
 		if err != nil {
 			return err
 		}
-	}
 
-	return tx.Commit()
+		// 2. Adiciona novas categorias
+		for _, categoryID := range categoryIDs {
+			_, err = tx.Exec(
+				"INSERT INTO book_category (book_id, category_id) VALUES (?, ?)",
+				bookID, categoryID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 // Delete remove livro (CASCADE remove book_author e book_category)
@@ -345,11 +255,28 @@ func (r *BookRepository) Search(searchTerm string, limit, offset int) ([]models.
         SELECT id, title, isbn, published_year, publisher, pages, created_at, updated_at
         FROM book
         WHERE title LIKE ?
-        ORDER BY title ASC
+        ORDER BY title ASC, id ASC
         LIMIT ? OFFSET ?
     `
 
-	rows, err := r.db.Query(query, "%"+searchTerm+"%", limit, offset)
+	return r.queryBooks(query, "%"+searchTerm+"%", limit, offset)
+}
+
+// queryBook retorna o primeiro livro da query com autores e categorias, ou
+// nil se não houver
+func (r *BookRepository) queryBook(query string, args ...interface{}) (*models.Book, error) {
+	books, err := r.queryBooks(query, args...)
+	if err != nil || len(books) == 0 {
+		return nil, err
+	}
+
+	return &books[0], nil
+}
+
+// queryBooks executa uma query que seleciona as colunas de book e carrega
+// autores e categorias da página inteira com uma query para cada
+func (r *BookRepository) queryBooks(query string, args ...interface{}) ([]models.Book, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -365,12 +292,42 @@ func (r *BookRepository) Search(searchTerm string, limit, offset int) ([]models.
 		if err != nil {
 			return nil, err
 		}
+		books = append(books, book)
+	}
 
-		book.Authors, _ = r.authorRepo.FindByBookID(book.ID)
-		book.Categories, _ = r.categoryRepo.FindByBookID(book.ID)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
-		books = append(books, book)
+	if err := r.attachRelations(books); err != nil {
+		return nil, err
 	}
 
 	return books, nil
 }
+
+// attachRelations carrega autores e categorias de todos os livros de uma vez
+func (r *BookRepository) attachRelations(books []models.Book) error {
+	ids := make([]int64, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	authors, err := r.authorRepo.FindByBookIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	categories, err := r.categoryRepo.FindByBookIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range books {
+		books[i].Authors = authors[books[i].ID]
+		books[i].Categories = categories[books[i].ID]
+	}
+
+	return nil
+}
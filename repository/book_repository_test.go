@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"quote-api/database"
+	"quote-api/models"
+	"testing"
+)
+
+// seedBooks cria books livros, cada um com dois autores e duas categorias
+func seedBooks(tb testing.TB, store *database.Store, books int) {
+	const authors, categories = 500, 20
+
+	seedTx(tb, store, func(tx *sql.Tx) error {
+		err := execEach(tx, "INSERT INTO author (id, name) VALUES (?, ?)", authors, func(i int) []interface{} {
+			return []interface{}{i + 1, fmt.Sprintf("Autor %03d", i+1)}
+		})
+		if err != nil {
+			return err
+		}
+		err = execEach(tx, "INSERT INTO category (id, name) VALUES (?, ?)", categories, func(i int) []interface{} {
+			return []interface{}{i + 1, fmt.Sprintf("Categoria %02d", i+1)}
+		})
+		if err != nil {
+			return err
+		}
+		err = execEach(tx, "INSERT INTO book (id, title, published_year) VALUES (?, ?, ?)", books, func(i int) []interface{} {
+			return []interface{}{i + 1, fmt.Sprintf("Livro %05d", i+1), 1900 + i%120}
+		})
+		if err != nil {
+			return err
+		}
+		err = execEach(tx, `INSERT INTO book_author (book_id, author_id, "order") VALUES (?, ?, ?)`, books*2, func(i int) []interface{} {
+			return []interface{}{i/2 + 1, (i/2+i%2*7)%authors + 1, i%2 + 1}
+		})
+		if err != nil {
+			return err
+		}
+		return execEach(tx, "INSERT INTO book_category (book_id, category_id) VALUES (?, ?)", books*2, func(i int) []interface{} {
+			return []interface{}{i/2 + 1, (i/2+i%2*3)%categories + 1}
+		})
+	})
+}
+
+// BenchmarkBookRepository_FindByQuery mede uma página de FindPageByQuery e,
+// sobre a mesma página, a carga de autores e categorias em lote e livro a
+// livro, como era antes. Em lote o custo por livro (ns/book) quase não muda
+// com o tamanho da página; no N+1 são duas queries por livro
+func BenchmarkBookRepository_FindByQuery(b *testing.B) {
+	store := newTestStore(b)
+	seedBooks(b, store, 5000)
+	repo := NewBookRepository(store)
+
+	cases := []struct {
+		name  string
+		query BookQuery
+	}{
+		{"limit=20", BookQuery{Limit: 20}},
+		{"limit=100", BookQuery{Limit: 100}},
+		{"limit=500", BookQuery{Limit: 500}},
+		{"author/limit=20", BookQuery{AuthorID: 1, Limit: 20}},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			page, err := repo.FindPageByQuery(c.query)
+			if err != nil {
+				b.Fatal(err)
+			}
+			books := page.Items
+
+			b.Run("page", func(b *testing.B) {
+				benchmarkBooks(b, func() ([]models.Book, error) {
+					page, err := repo.FindPageByQuery(c.query)
+					return page.Items, err
+				})
+			})
+			b.Run("relations/batched", func(b *testing.B) {
+				benchmarkBooks(b, func() ([]models.Book, error) {
+					return books, repo.attachRelations(books)
+				})
+			})
+			b.Run("relations/n+1", func(b *testing.B) {
+				benchmarkBooks(b, func() ([]models.Book, error) {
+					return books, attachRelationsPerBook(repo, books)
+				})
+			})
+		})
+	}
+}
+
+// attachRelationsPerBook é a carga antiga, com uma query de autores e outra
+// de categorias para cada livro
+func attachRelationsPerBook(repo *BookRepository, books []models.Book) error {
+	for i := range books {
+		authors, err := repo.authorRepo.FindByBookID(books[i].ID)
+		if err != nil {
+			return err
+		}
+		categories, err := repo.categoryRepo.FindByBookID(books[i].ID)
+		if err != nil {
+			return err
+		}
+		books[i].Authors, books[i].Categories = authors, categories
+	}
+	return nil
+}
+
+// benchmarkBooks roda load b.N vezes e relata o custo por livro carregado
+func benchmarkBooks(b *testing.B, load func() ([]models.Book, error)) {
+	b.Helper()
+	books := 0
+	for i := 0; i < b.N; i++ {
+		items, err := load()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(items) == 0 || len(items[0].Authors) == 0 || len(items[0].Categories) == 0 {
+			b.Fatal("página sem livros, autores ou categorias")
+		}
+		books += len(items)
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(books), "ns/book")
+}
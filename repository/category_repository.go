@@ -8,11 +8,16 @@ import (
 )
 
 type CategoryRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewCategoryRepository() *CategoryRepository {
-	return &CategoryRepository{db: database.DB}
+func NewCategoryRepository(store *database.Store) *CategoryRepository {
+	return &CategoryRepository{db: store.DB()}
+}
+
+// WithTx retorna uma cópia do repositório que executa as queries na transação tx
+func (r *CategoryRepository) WithTx(tx *sql.Tx) *CategoryRepository {
+	return &CategoryRepository{db: tx}
 }
 
 // FindAll lista todas as categorias
@@ -91,31 +96,44 @@ func (r *CategoryRepository) FindByName(name string) (*models.Category, error) {
 
 // FindByBookID busca categorias de um livro específico
 func (r *CategoryRepository) FindByBookID(bookID int64) ([]models.Category, error) {
+	categories, err := r.FindByBookIDs([]int64{bookID})
+	if err != nil {
+		return nil, err
+	}
+
+	return categories[bookID], nil
+}
+
+// FindByBookIDs carrega as categorias de vários livros com uma query a cada
+// maxInList livros, ordenadas por nome
+func (r *CategoryRepository) FindByBookIDs(bookIDs []int64) (map[int64][]models.Category, error) {
+	result := make(map[int64][]models.Category)
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
 	query := `
-        SELECT c.id, c.name, c.created_at, c.updated_at
+        SELECT bc.book_id, c.id, c.name, c.created_at, c.updated_at
         FROM category c
         INNER JOIN book_category bc ON c.id = bc.category_id
-        WHERE bc.book_id = ?
-        ORDER BY c.name ASC
+        WHERE bc.book_id IN (%s)
+        ORDER BY bc.book_id ASC, c.name ASC
     `
 
-	rows, err := r.db.Query(query, bookID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var categories []models.Category
-	for rows.Next() {
+	err := queryInChunks(r.db, query, bookIDs, func(rows *sql.Rows) error {
+		var bookID int64
 		var category models.Category
-		err := rows.Scan(&category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt)
-		if err != nil {
-			return nil, err
+		if err := rows.Scan(&bookID, &category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt); err != nil {
+			return err
 		}
-		categories = append(categories, category)
+		result[bookID] = append(result[bookID], category)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return categories, nil
+	return result, nil
 }
 
 // Create cria nova categoria
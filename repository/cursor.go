@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"quote-api/apperrors"
+	"strings"
+)
+
+// Cursor marca a posição de uma página na paginação por cursor (keyset). Keys
+// guarda os valores das chaves de ordenação da linha de referência; a página
+// começa logo depois dela, ou termina logo antes quando Before está ligado
+type Cursor struct {
+	Order  string        `json:"o"`
+	Keys   []interface{} `json:"k"`
+	Before bool          `json:"b,omitempty"`
+}
+
+// ErrInvalidCursor indica um cursor corrompido ou de outra ordenação
+var ErrInvalidCursor = apperrors.Validation(apperrors.CodeInvalidCursor)
+
+// Encode gera o token opaco enviado aos clientes
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor lê um token gerado por Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var cursor Cursor
+	if err := decoder.Decode(&cursor); err != nil || len(cursor.Keys) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	// Números voltam como inteiros quando possível, para que o SQLite os
+	// compare como números e não como texto
+	for i, key := range cursor.Keys {
+		switch value := key.(type) {
+		case json.Number:
+			if n, err := value.Int64(); err == nil {
+				cursor.Keys[i] = n
+			} else if f, err := value.Float64(); err == nil {
+				cursor.Keys[i] = f
+			} else {
+				return nil, ErrInvalidCursor
+			}
+		case string, nil:
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &cursor, nil
+}
+
+// Page é uma página de uma listagem. Next e Prev apontam para as páginas
+// vizinhas e ficam nil quando elas não existem ou quando a ordenação não
+// permite cursor (aleatória ou por relevância)
+type Page[T any] struct {
+	Items   []T
+	Next    *Cursor
+	Prev    *Cursor
+	HasMore bool
+}
+
+// sortKey é uma expressão do ORDER BY usada também na condição do cursor
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+// keysetCondition monta a condição que seleciona as linhas depois (ou antes)
+// de keys na ordem dada: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... As
+// expressões vão entre parênteses porque IS tem precedência menor que = e >:
+// sem eles, "x IS NULL = ?" vira "x IS (NULL = ?)"
+func keysetCondition(keys []sortKey, values []interface{}, before bool) (string, []interface{}) {
+	var alternatives []string
+	var args []interface{}
+
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, "("+keys[j].expr+") = ?")
+			args = append(args, values[j])
+		}
+
+		op := ">"
+		if key.desc != before {
+			op = "<"
+		}
+		parts = append(parts, "("+key.expr+") "+op+" ?")
+		args = append(args, values[i])
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// keysetOrder monta o ORDER BY das chaves, invertido para buscar a página
+// anterior
+func keysetOrder(keys []sortKey, reverse bool) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		direction := "ASC"
+		if key.desc != reverse {
+			direction = "DESC"
+		}
+		parts[i] = key.expr + " " + direction
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keyColumns seleciona as chaves com + na frente, o que tira o tipo declarado
+// da coluna: o driver devolve o texto gravado em vez de convertê-lo em
+// time.Time, e o cursor compara exatamente o mesmo valor
+func keyColumns(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = "+(" + key.expr + ")"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keyDest prepara o destino do Scan das colunas de keyColumns
+func keyDest(n int) ([]interface{}, []interface{}) {
+	values := make([]interface{}, n)
+	dest := make([]interface{}, n)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	return values, dest
+}
+
+// fetchLimit lê uma linha além do limite para saber se há próxima página;
+// limit negativo continua sem limite
+func fetchLimit(limit int) int {
+	if limit < 0 {
+		return limit
+	}
+	return limit + 1
+}
+
+// pageOf recorta as linhas lidas com limit+1 e calcula os cursores. rowKeys
+// traz os valores das chaves de cada linha, na ordem de rows
+func pageOf[T any](order string, rows []T, rowKeys [][]interface{}, cursor *Cursor, limit int, offset int, keyset bool) Page[T] {
+	before := cursor != nil && cursor.Before
+
+	extra := limit >= 0 && len(rows) > limit
+	if extra {
+		rows, rowKeys = rows[:limit], rowKeys[:limit]
+	}
+
+	// A página anterior foi lida de trás para frente
+	if before {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+			rowKeys[i], rowKeys[j] = rowKeys[j], rowKeys[i]
+		}
+	}
+
+	page := Page[T]{Items: rows}
+
+	// Lendo para trás, a linha do cursor garante uma página seguinte; lendo
+	// para frente, a linha extra indica que há mais
+	hasNext := extra
+	hasPrev := cursor != nil || offset > 0
+	if before {
+		hasNext, hasPrev = true, extra
+	}
+	page.HasMore = hasNext
+
+	if !keyset || len(rows) == 0 {
+		return page
+	}
+
+	if hasNext {
+		page.Next = &Cursor{Order: order, Keys: rowKeys[len(rowKeys)-1]}
+	}
+	if hasPrev {
+		page.Prev = &Cursor{Order: order, Keys: rowKeys[0], Before: true}
+	}
+
+	return page
+}
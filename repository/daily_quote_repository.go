@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"database/sql"
+	"quote-api/database"
+	"quote-api/models"
+	"time"
+)
+
+type DailyQuoteRepository struct {
+	db DBTX
+}
+
+func NewDailyQuoteRepository(store *database.Store) *DailyQuoteRepository {
+	return &DailyQuoteRepository{db: store.DB()}
+}
+
+// WithTx retorna uma cópia do repositório que executa as queries na transação tx
+func (r *DailyQuoteRepository) WithTx(tx *sql.Tx) *DailyQuoteRepository {
+	return &DailyQuoteRepository{db: tx}
+}
+
+// FindByDay busca a citação do dia já escolhida para o escopo
+func (r *DailyQuoteRepository) FindByDay(scope, day string) (*models.DailyQuote, error) {
+	query := `
+        SELECT id, day, scope, quote_id, created_at
+        FROM daily_quote
+        WHERE scope = ? AND day = ?
+    `
+
+	var entry models.DailyQuote
+	err := r.db.QueryRow(query, scope, day).Scan(
+		&entry.ID, &entry.Day, &entry.Scope, &entry.QuoteID, &entry.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// FindHistory lista as citações do dia do escopo, da mais recente para a mais antiga
+func (r *DailyQuoteRepository) FindHistory(scope string, limit, offset int) ([]models.DailyQuote, error) {
+	query := `
+        SELECT id, day, scope, quote_id, created_at
+        FROM daily_quote
+        WHERE scope = ?
+        ORDER BY day DESC
+        LIMIT ? OFFSET ?
+    `
+
+	rows, err := r.db.Query(query, scope, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.DailyQuote
+	for rows.Next() {
+		var entry models.DailyQuote
+		err := rows.Scan(&entry.ID, &entry.Day, &entry.Scope, &entry.QuoteID, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// CountHistory conta os dias registrados para o escopo
+func (r *DailyQuoteRepository) CountHistory(scope string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM daily_quote WHERE scope = ?", scope).Scan(&count)
+	return count, err
+}
+
+// FindQuoteIDsSince lista as citações escolhidas para o escopo de since em diante
+func (r *DailyQuoteRepository) FindQuoteIDsSince(scope, since string) ([]int64, error) {
+	rows, err := r.db.Query(
+		"SELECT DISTINCT quote_id FROM daily_quote WHERE scope = ? AND day >= ? ORDER BY quote_id ASC",
+		scope, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Create registra a citação do dia. Se outro processo já registrou o mesmo
+// escopo e dia, mantém o registro existente e o retorna
+func (r *DailyQuoteRepository) Create(entry models.DailyQuote) (*models.DailyQuote, error) {
+	_, err := r.db.Exec(
+		"INSERT OR IGNORE INTO daily_quote (day, scope, quote_id, created_at) VALUES (?, ?, ?, ?)",
+		entry.Day, entry.Scope, entry.QuoteID, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByDay(entry.Scope, entry.Day)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DBTX é satisfeita tanto por *sql.DB quanto por *sql.Tx, permitindo que os
+// repositórios participem de uma transação aberta por quem os chama
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// runInTx executa fn dentro de uma transação. Se db já for uma transação,
+// fn roda nela e o commit fica a cargo de quem a abriu
+func runInTx(db DBTX, fn func(tx DBTX) error) error {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := sqlDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// inList monta os placeholders e os argumentos de um "IN (...)" com os valores
+func inList[T any](values []T) (string, []interface{}) {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), args
+}
+
+// escapeLike escapa os curingas de LIKE em value, para uso com ESCAPE '\'
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// maxInList é o máximo de valores em cada "IN (...)" de queryInChunks. O
+// SQLite recusa queries com mais de 32766 variáveis
+const maxInList = 1000
+
+// queryInChunks roda query, em que "%s" é a lista de um "IN (%s)", para
+// values divididos em partes de até maxInList, e chama scan para cada linha
+// de cada parte
+func queryInChunks[T any](db DBTX, query string, values []T, scan func(rows *sql.Rows) error) error {
+	for start := 0; start < len(values); start += maxInList {
+		placeholders, args := inList(values[start:min(start+maxInList, len(values))])
+		if err := queryEach(db, fmt.Sprintf(query, placeholders), args, scan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryEach(db DBTX, query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
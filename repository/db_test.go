@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"database/sql"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"quote-api/database"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// database.Open registra cada conexão no log padrão
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newTestStore abre um banco migrado em um diretório temporário do teste
+func newTestStore(tb testing.TB) *database.Store {
+	tb.Helper()
+
+	store, err := database.Open(database.Config{DSN: filepath.Join(tb.TempDir(), "quotes.db")})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { store.Close() })
+	return store
+}
+
+// seedTx grava os dados de teste em uma transação, o que deixa a carga de
+// muitas linhas ordens de grandeza mais rápida
+func seedTx(tb testing.TB, store *database.Store, fn func(tx *sql.Tx) error) {
+	tb.Helper()
+
+	tx, err := store.DB().Begin()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		tb.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// execEach executa query uma vez para cada i em [0, n), com os argumentos de args
+func execEach(tx *sql.Tx, query string, n int, args func(i int) []interface{}) error {
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := 0; i < n; i++ {
+		if _, err := stmt.Exec(args(i)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
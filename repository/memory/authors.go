@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"database/sql"
+	"quote-api/models"
+	"time"
+)
+
+type AuthorRepository struct {
+	store *Store
+}
+
+func (r *AuthorRepository) FindAll(limit, offset int) ([]models.Author, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return paginate(r.store.sortedAuthors(func(models.Author) bool { return true }), limit, offset), nil
+}
+
+func (r *AuthorRepository) FindByID(id int64) (*models.Author, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	author, ok := r.store.authors[id]
+	if !ok {
+		return nil, nil
+	}
+	return &author, nil
+}
+
+// FindByName compara sem diferenciar caixa; havendo homônimos, retorna o de
+// menor ID
+func (r *AuthorRepository) FindByName(name string) (*models.Author, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var found *models.Author
+	for _, author := range r.store.authors {
+		if lower(author.Name) == lower(name) && (found == nil || author.ID < found.ID) {
+			match := author
+			found = &match
+		}
+	}
+	return found, nil
+}
+
+func (r *AuthorRepository) FindByBookID(bookID int64) ([]models.Author, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.bookAuthorList(bookID), nil
+}
+
+func (r *AuthorRepository) Create(author models.Author) (*models.Author, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	r.store.lastAuthorID++
+	created := models.Author{ID: r.store.lastAuthorID, Name: author.Name, CreatedAt: now, UpdatedAt: now}
+	r.store.authors[created.ID] = created
+
+	return &created, nil
+}
+
+func (r *AuthorRepository) Update(id int64, author models.Author) (*models.Author, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.authors[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	existing.Name = author.Name
+	existing.UpdatedAt = time.Now()
+	r.store.authors[id] = existing
+
+	return &existing, nil
+}
+
+// Delete remove o autor e, como o CASCADE de book_author, seus vínculos com livros
+func (r *AuthorRepository) Delete(id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.authors[id]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.store.authors, id)
+	for bookID, authorIDs := range r.store.bookAuthors {
+		r.store.bookAuthors[bookID] = removeID(authorIDs, id)
+	}
+
+	return nil
+}
+
+func (r *AuthorRepository) Count() (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.authors), nil
+}
+
+func (r *AuthorRepository) Search(searchTerm string, limit, offset int) ([]models.Author, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	authors := r.store.sortedAuthors(func(author models.Author) bool {
+		return like(author.Name, searchTerm)
+	})
+	return paginate(authors, limit, offset), nil
+}
+
+// sortedAuthors filtra os autores e os ordena por nome
+func (s *Store) sortedAuthors(keep func(models.Author) bool) []models.Author {
+	var authors []models.Author
+	for _, author := range s.authors {
+		if keep(author) {
+			authors = append(authors, author)
+		}
+	}
+
+	sortByName(authors,
+		func(a models.Author) string { return a.Name },
+		func(a models.Author) int64 { return a.ID },
+	)
+	return authors
+}
+
+// bookAuthorList retorna os autores do livro na ordem de book_author
+func (s *Store) bookAuthorList(bookID int64) []models.Author {
+	var authors []models.Author
+	for _, authorID := range s.bookAuthors[bookID] {
+		authors = append(authors, s.authors[authorID])
+	}
+	return authors
+}
+
+func removeID[T comparable](ids []T, id T) []T {
+	kept := ids[:0]
+	for _, current := range ids {
+		if current != id {
+			kept = append(kept, current)
+		}
+	}
+	return kept
+}
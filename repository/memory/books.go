@@ -0,0 +1,325 @@
+package memory
+
+import (
+	"database/sql"
+	"quote-api/models"
+	"quote-api/repository"
+	"sort"
+	"time"
+)
+
+type BookRepository struct {
+	store *Store
+}
+
+func (r *BookRepository) FindAll(limit, offset int) ([]models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return paginate(r.store.sortedBooks(func(models.Book) bool { return true }), limit, offset), nil
+}
+
+func (r *BookRepository) FindByID(id int64) (*models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	book, ok := r.store.books[id]
+	if !ok {
+		return nil, nil
+	}
+	return r.store.loadBook(book), nil
+}
+
+func (r *BookRepository) FindByISBN(isbn string) (*models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.findBook(func(book models.Book) bool {
+		return book.ISBN != nil && *book.ISBN == isbn
+	}), nil
+}
+
+func (r *BookRepository) FindByTitle(title string) (*models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.findBook(func(book models.Book) bool {
+		return lower(book.Title) == lower(title)
+	}), nil
+}
+
+func (r *BookRepository) FindByAuthorID(authorID int64, limit, offset int) ([]models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := r.store.sortedBooks(func(book models.Book) bool {
+		return containsID(r.store.bookAuthors[book.ID], authorID)
+	})
+	return paginate(books, limit, offset), nil
+}
+
+func (r *BookRepository) FindByCategoryID(categoryID int, limit, offset int) ([]models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := r.store.sortedBooks(func(book models.Book) bool {
+		return containsID(r.store.bookCategories[book.ID], categoryID)
+	})
+	return paginate(books, limit, offset), nil
+}
+
+func (r *BookRepository) FindPageByQuery(query repository.BookQuery) (repository.Page[models.Book], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := r.store.sortedBooks(r.store.bookQueryFilter(query))
+	return bookSortKeys.page(books, query.Cursor, query.Limit, query.Offset)
+}
+
+// Create valida as mesmas constraints do schema (ISBN único, autores e
+// categorias existentes e sem repetição) antes de gravar qualquer coisa, o
+// que equivale ao rollback da transação do repositório SQLite
+func (r *BookRepository) Create(book models.Book, authorIDs []int64, categoryIDs []int) (*models.Book, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !r.store.validBook(book, 0) || !r.store.validAuthorIDs(authorIDs) || !r.store.validCategoryIDs(categoryIDs) {
+		return nil, ErrConstraint
+	}
+
+	now := time.Now()
+	r.store.lastBookID++
+
+	book.ID = r.store.lastBookID
+	book.CreatedAt = now
+	book.UpdatedAt = now
+	book.Authors = nil
+	book.Categories = nil
+	r.store.books[book.ID] = book
+
+	r.store.bookAuthors[book.ID] = append([]int64(nil), authorIDs...)
+	r.store.bookCategories[book.ID] = append([]int(nil), categoryIDs...)
+
+	return r.store.loadBook(book), nil
+}
+
+func (r *BookRepository) Update(id int64, book models.Book) (*models.Book, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.books[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if !r.store.validBook(book, id) {
+		return nil, ErrConstraint
+	}
+
+	existing.Title = book.Title
+	existing.ISBN = book.ISBN
+	existing.PublishedYear = book.PublishedYear
+	existing.Publisher = book.Publisher
+	existing.Pages = book.Pages
+	existing.UpdatedAt = time.Now()
+	r.store.books[id] = existing
+
+	return r.store.loadBook(existing), nil
+}
+
+func (r *BookRepository) UpdateAuthors(bookID int64, authorIDs []int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[bookID]; !ok && len(authorIDs) > 0 {
+		return ErrConstraint
+	}
+	if !r.store.validAuthorIDs(authorIDs) {
+		return ErrConstraint
+	}
+
+	r.store.bookAuthors[bookID] = append([]int64(nil), authorIDs...)
+	return nil
+}
+
+func (r *BookRepository) UpdateCategories(bookID int64, categoryIDs []int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[bookID]; !ok && len(categoryIDs) > 0 {
+		return ErrConstraint
+	}
+	if !r.store.validCategoryIDs(categoryIDs) {
+		return ErrConstraint
+	}
+
+	r.store.bookCategories[bookID] = append([]int(nil), categoryIDs...)
+	return nil
+}
+
+// Delete remove o livro e, como o CASCADE do schema, seus vínculos e citações
+func (r *BookRepository) Delete(id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[id]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.store.books, id)
+	delete(r.store.bookAuthors, id)
+	delete(r.store.bookCategories, id)
+	for quoteID, quote := range r.store.quotes {
+		if quote.BookID == id {
+			r.store.deleteQuote(quoteID)
+		}
+	}
+
+	return nil
+}
+
+func (r *BookRepository) Count() (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.books), nil
+}
+
+func (r *BookRepository) CountByQuery(query repository.BookQuery) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keep := r.store.bookQueryFilter(query)
+	count := 0
+	for _, book := range r.store.books {
+		if keep(book) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *BookRepository) Search(searchTerm string, limit, offset int) ([]models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := r.store.sortedBooks(func(book models.Book) bool {
+		return like(book.Title, searchTerm)
+	})
+	return paginate(books, limit, offset), nil
+}
+
+// sortedBooks filtra os livros, os ordena por título e carrega autores e categorias
+func (s *Store) sortedBooks(keep func(models.Book) bool) []models.Book {
+	var books []models.Book
+	for _, book := range s.books {
+		if keep(book) {
+			books = append(books, *s.loadBook(book))
+		}
+	}
+
+	sortByName(books,
+		func(b models.Book) string { return b.Title },
+		func(b models.Book) int64 { return b.ID },
+	)
+	return books
+}
+
+// bookSortKeys espelha a ordenação por título do repositório SQLite
+var bookSortKeys = sortKeys[models.Book]{
+	order: repository.BookOrderTitle,
+	desc:  []bool{false, false},
+	keys: func(b models.Book) []interface{} {
+		return []interface{}{b.Title, b.ID}
+	},
+}
+
+func (s *Store) bookQueryFilter(query repository.BookQuery) func(models.Book) bool {
+	return func(book models.Book) bool {
+		switch {
+		case query.AuthorID != 0 && !containsID(s.bookAuthors[book.ID], query.AuthorID),
+			query.CategoryID != 0 && !containsID(s.bookCategories[book.ID], query.CategoryID),
+			query.Search != "" && !like(book.Title, query.Search):
+			return false
+		}
+		return true
+	}
+}
+
+// findBook retorna o livro de menor ID que satisfaz match
+func (s *Store) findBook(match func(models.Book) bool) *models.Book {
+	ids := make([]int64, 0, len(s.books))
+	for id := range s.books {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if book := s.books[id]; match(book) {
+			return s.loadBook(book)
+		}
+	}
+	return nil
+}
+
+func (s *Store) loadBook(book models.Book) *models.Book {
+	book.Authors = s.bookAuthorList(book.ID)
+	book.Categories = s.bookCategoryList(book.ID)
+	return &book
+}
+
+// validBook confere o UNIQUE de isbn e os CHECKs da tabela book
+func (s *Store) validBook(book models.Book, exceptID int64) bool {
+	if book.PublishedYear < 0 || book.PublishedYear > 9999 || book.Pages < 0 {
+		return false
+	}
+	if book.ISBN == nil {
+		return true
+	}
+
+	for _, other := range s.books {
+		if other.ID != exceptID && other.ISBN != nil && *other.ISBN == *book.ISBN {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Store) validAuthorIDs(ids []int64) bool {
+	seen := make(map[int64]bool)
+	for _, id := range ids {
+		if _, ok := s.authors[id]; !ok || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+func (s *Store) validCategoryIDs(ids []int) bool {
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		if _, ok := s.categories[id]; !ok || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+func containsID[T comparable](ids []T, id T) bool {
+	for _, current := range ids {
+		if current == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny[T comparable](ids []T, wanted []T) bool {
+	for _, id := range wanted {
+		if containsID(ids, id) {
+			return true
+		}
+	}
+	return false
+}
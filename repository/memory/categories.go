@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"database/sql"
+	"quote-api/models"
+	"time"
+)
+
+type CategoryRepository struct {
+	store *Store
+}
+
+func (r *CategoryRepository) FindAll(limit, offset int) ([]models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return paginate(r.store.sortedCategories(func(models.Category) bool { return true }), limit, offset), nil
+}
+
+func (r *CategoryRepository) FindByID(id int) (*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	category, ok := r.store.categories[id]
+	if !ok {
+		return nil, nil
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepository) FindByName(name string) (*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var found *models.Category
+	for _, category := range r.store.categories {
+		if lower(category.Name) == lower(name) && (found == nil || category.ID < found.ID) {
+			match := category
+			found = &match
+		}
+	}
+	return found, nil
+}
+
+func (r *CategoryRepository) FindByBookID(bookID int64) ([]models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.bookCategoryList(bookID), nil
+}
+
+// Create respeita o UNIQUE de category.name, que diferencia maiúsculas
+func (r *CategoryRepository) Create(category models.Category) (*models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.categoryNameTaken(category.Name, 0) {
+		return nil, ErrConstraint
+	}
+
+	now := time.Now()
+	r.store.lastCategoryID++
+	created := models.Category{ID: r.store.lastCategoryID, Name: category.Name, CreatedAt: now, UpdatedAt: now}
+	r.store.categories[created.ID] = created
+
+	return &created, nil
+}
+
+func (r *CategoryRepository) Update(id int, category models.Category) (*models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.categories[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if r.store.categoryNameTaken(category.Name, id) {
+		return nil, ErrConstraint
+	}
+
+	existing.Name = category.Name
+	existing.UpdatedAt = time.Now()
+	r.store.categories[id] = existing
+
+	return &existing, nil
+}
+
+// Delete remove a categoria e, como o CASCADE de book_category, seus vínculos com livros
+func (r *CategoryRepository) Delete(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.categories[id]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.store.categories, id)
+	for bookID, categoryIDs := range r.store.bookCategories {
+		r.store.bookCategories[bookID] = removeID(categoryIDs, id)
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) Count() (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.categories), nil
+}
+
+func (r *CategoryRepository) Search(searchTerm string, limit, offset int) ([]models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	categories := r.store.sortedCategories(func(category models.Category) bool {
+		return like(category.Name, searchTerm)
+	})
+	return paginate(categories, limit, offset), nil
+}
+
+func (s *Store) categoryNameTaken(name string, exceptID int) bool {
+	for _, category := range s.categories {
+		if category.Name == name && category.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedCategories filtra as categorias e as ordena por nome
+func (s *Store) sortedCategories(keep func(models.Category) bool) []models.Category {
+	var categories []models.Category
+	for _, category := range s.categories {
+		if keep(category) {
+			categories = append(categories, category)
+		}
+	}
+
+	sortByName(categories,
+		func(c models.Category) string { return c.Name },
+		func(c models.Category) int64 { return int64(c.ID) },
+	)
+	return categories
+}
+
+// bookCategoryList retorna as categorias do livro ordenadas por nome
+func (s *Store) bookCategoryList(bookID int64) []models.Category {
+	linked := make(map[int]bool)
+	for _, categoryID := range s.bookCategories[bookID] {
+		linked[categoryID] = true
+	}
+
+	return s.sortedCategories(func(category models.Category) bool {
+		return linked[category.ID]
+	})
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"quote-api/models"
+	"sort"
+	"time"
+)
+
+// dailyKey imita o UNIQUE (scope, day) de daily_quote
+type dailyKey struct {
+	scope string
+	day   string
+}
+
+type DailyQuoteRepository struct {
+	store *Store
+}
+
+func (r *DailyQuoteRepository) FindByDay(scope, day string) (*models.DailyQuote, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entry, ok := r.store.dailyQuotes[dailyKey{scope, day}]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (r *DailyQuoteRepository) FindHistory(scope string, limit, offset int) ([]models.DailyQuote, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return paginate(r.store.dailyHistory(scope), limit, offset), nil
+}
+
+func (r *DailyQuoteRepository) CountHistory(scope string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.dailyHistory(scope)), nil
+}
+
+func (r *DailyQuoteRepository) FindQuoteIDsSince(scope, since string) ([]int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var ids []int64
+	for _, entry := range r.store.dailyHistory(scope) {
+		if entry.Day >= since && !containsID(ids, entry.QuoteID) {
+			ids = append(ids, entry.QuoteID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// Create mantém o registro existente do escopo e dia, como o INSERT OR IGNORE
+func (r *DailyQuoteRepository) Create(entry models.DailyQuote) (*models.DailyQuote, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.quotes[entry.QuoteID]; !ok {
+		return nil, ErrConstraint
+	}
+
+	key := dailyKey{entry.Scope, entry.Day}
+	if existing, ok := r.store.dailyQuotes[key]; ok {
+		return &existing, nil
+	}
+
+	r.store.lastDailyID++
+	entry.ID = r.store.lastDailyID
+	entry.CreatedAt = time.Now()
+	entry.Quote = nil
+	r.store.dailyQuotes[key] = entry
+
+	return &entry, nil
+}
+
+// dailyHistory lista os registros do escopo do dia mais recente para o mais antigo
+func (s *Store) dailyHistory(scope string) []models.DailyQuote {
+	var entries []models.DailyQuote
+	for key, entry := range s.dailyQuotes {
+		if key.scope == scope {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Day > entries[j].Day })
+	return entries
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"quote-api/repository"
+	"strings"
+	"time"
+)
+
+// keyTimeFormat tem largura fixa e fica em UTC, para que a ordem do texto
+// seja a ordem do tempo
+const keyTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+func timeKey(t time.Time) string {
+	return t.UTC().Format(keyTimeFormat)
+}
+
+// sortKeys descreve a ordenação das listagens paginadas por cursor: keys
+// extrai os valores (int64 ou string) e desc indica as chaves decrescentes
+type sortKeys[T any] struct {
+	order string
+	keys  func(T) []interface{}
+	desc  []bool
+}
+
+func (k sortKeys[T]) less(a, b T) bool {
+	return k.compare(k.keys(a), k.keys(b)) < 0
+}
+
+func (k sortKeys[T]) compare(a, b []interface{}) int {
+	for i := range a {
+		c := compareKey(a[i], b[i])
+		if k.desc[i] {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareKey(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+	}
+	return 0
+}
+
+// page recorta items, já ordenados por k, como o keyset do SQLite: depois do
+// cursor, antes dele (Before) ou a partir de offset
+func (k sortKeys[T]) page(items []T, cursor *repository.Cursor, limit, offset int) (repository.Page[T], error) {
+	if cursor != nil && (cursor.Order != k.order || len(cursor.Keys) != len(k.desc)) {
+		return repository.Page[T]{}, repository.ErrInvalidCursor
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	start, end := min(offset, len(items)), len(items)
+
+	if cursor != nil {
+		// Primeira posição depois do cursor, ou a do próprio cursor com Before
+		position := len(items)
+		for i, item := range items {
+			c := k.compare(k.keys(item), cursor.Keys)
+			if c > 0 || (cursor.Before && c == 0) {
+				position = i
+				break
+			}
+		}
+
+		start = position
+		if cursor.Before {
+			end = position
+			start = 0
+			if limit >= 0 {
+				start = max(0, end-limit)
+			}
+		}
+	}
+
+	if limit >= 0 && end-start > limit {
+		end = start + limit
+	}
+
+	page := repository.Page[T]{HasMore: end < len(items)}
+	if start == end {
+		return page, nil
+	}
+
+	page.Items = items[start:end]
+	if end < len(items) {
+		page.Next = &repository.Cursor{Order: k.order, Keys: k.keys(items[end-1])}
+	}
+	if start > 0 {
+		page.Prev = &repository.Cursor{Order: k.order, Keys: k.keys(items[start]), Before: true}
+	}
+	return page, nil
+}
@@ -0,0 +1,484 @@
+package memory
+
+import (
+	"database/sql"
+	"math/rand"
+	"quote-api/models"
+	"quote-api/repository"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+type QuoteRepository struct {
+	store *Store
+}
+
+func (r *QuoteRepository) FindAll(limit, offset int) ([]models.Quote, error) {
+	return r.find(func(models.Quote) bool { return true }, repository.QuoteOrderCreatedAt, limit, offset), nil
+}
+
+func (r *QuoteRepository) FindByID(id int64) (*models.Quote, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	quote, ok := r.store.quotes[id]
+	if !ok {
+		return nil, nil
+	}
+	return r.store.loadQuote(quote), nil
+}
+
+func (r *QuoteRepository) FindByBookID(bookID int64, limit, offset int) ([]models.Quote, error) {
+	return r.find(func(quote models.Quote) bool {
+		return quote.BookID == bookID
+	}, repository.QuoteOrderCreatedAt, limit, offset), nil
+}
+
+func (r *QuoteRepository) FindByAuthorID(authorID int64, limit, offset int) ([]models.Quote, error) {
+	return r.find(func(quote models.Quote) bool {
+		return r.store.quoteHasAuthor(quote, authorID)
+	}, repository.QuoteOrderCreatedAt, limit, offset), nil
+}
+
+func (r *QuoteRepository) FindByCategoryID(categoryID int, limit, offset int) ([]models.Quote, error) {
+	return r.find(func(quote models.Quote) bool {
+		return r.store.quoteHasCategory(quote, categoryID)
+	}, repository.QuoteOrderCreatedAt, limit, offset), nil
+}
+
+func (r *QuoteRepository) FindByQuery(query repository.QuoteQuery) ([]models.Quote, error) {
+	return r.find(r.queryFilter(query), queryOrder(query), query.Limit, query.Offset), nil
+}
+
+// FindPageByQuery pagina como o repositório SQLite; a ordenação aleatória
+// não aceita cursor e suas páginas não trazem cursores
+func (r *QuoteRepository) FindPageByQuery(query repository.QuoteQuery) (repository.Page[models.Quote], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	order := queryOrder(query)
+	quotes := r.store.sortedQuotes(r.queryFilter(query), order)
+
+	if order == repository.QuoteOrderRandom {
+		if query.Cursor != nil {
+			return repository.Page[models.Quote]{}, repository.ErrInvalidCursor
+		}
+		page := paginate(quotes, query.Limit, query.Offset)
+		return repository.Page[models.Quote]{
+			Items:   page,
+			HasMore: max(query.Offset, 0)+len(page) < len(quotes),
+		}, nil
+	}
+
+	return quoteSortKeys(order).page(quotes, query.Cursor, query.Limit, query.Offset)
+}
+
+func (r *QuoteRepository) FindRandom(weighting repository.RandomWeighting) (*models.Quote, error) {
+	quotes, err := r.FindRandomByQuery(repository.QuoteQuery{}, 1, weighting)
+	if err != nil || len(quotes) == 0 {
+		return nil, err
+	}
+	return &quotes[0], nil
+}
+
+// FindRandomByQuery sorteia entre as citações filtradas com
+// repository.PickRandom, que o repositório SQLite usa quando os filtros são
+// seletivos; a distribuição é a mesma
+func (r *QuoteRepository) FindRandomByQuery(query repository.QuoteQuery, n int, weighting repository.RandomWeighting) ([]models.Quote, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	quotes := r.store.sortedQuotes(r.queryFilter(query), repository.QuoteOrderCreatedAt)
+
+	var weight func(models.Quote) int
+	if weighting == repository.RandomFavorites {
+		weight = repository.QuoteWeight
+	}
+
+	return repository.PickRandom(quotes, n, weight), nil
+}
+
+// Create aplica os defaults e os CHECKs da tabela quote e exige que o livro exista
+func (r *QuoteRepository) Create(quote models.Quote) (*models.Quote, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if quote.Kind == "" {
+		quote.Kind = models.QuoteKindHighlight
+	}
+	if _, ok := r.store.books[quote.BookID]; !ok || !validQuote(quote) {
+		return nil, ErrConstraint
+	}
+
+	now := time.Now()
+	if quote.CreatedAt.IsZero() {
+		quote.CreatedAt = now
+	}
+
+	r.store.lastQuoteID++
+	quote.ID = r.store.lastQuoteID
+	quote.UpdatedAt = now
+	quote.Rating = copyInt(quote.Rating)
+	quote.Book = nil
+	quote.Annotations = nil
+	quote.Tags = nil
+	r.store.quotes[quote.ID] = quote
+
+	return r.store.loadQuote(quote), nil
+}
+
+// Update altera apenas o texto, como no repositório SQLite
+func (r *QuoteRepository) Update(id int64, quote models.Quote) (*models.Quote, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.quotes[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	existing.Text = quote.Text
+	if !validQuote(existing) {
+		return nil, ErrConstraint
+	}
+
+	existing.UpdatedAt = time.Now()
+	r.store.quotes[id] = existing
+
+	return r.store.loadQuote(existing), nil
+}
+
+func (r *QuoteRepository) SetFavorite(id int64, favorite bool) error {
+	return r.set(id, func(quote *models.Quote) { quote.Favorite = favorite })
+}
+
+func (r *QuoteRepository) SetRating(id int64, rating *int) error {
+	return r.set(id, func(quote *models.Quote) { quote.Rating = copyInt(rating) })
+}
+
+// set aplica change à citação id, respeitando os CHECKs da tabela quote
+func (r *QuoteRepository) set(id int64, change func(quote *models.Quote)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	quote, ok := r.store.quotes[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	change(&quote)
+	if !validQuote(quote) {
+		return ErrConstraint
+	}
+
+	quote.UpdatedAt = time.Now()
+	r.store.quotes[id] = quote
+	return nil
+}
+
+func (r *QuoteRepository) Delete(id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.quotes[id]; !ok {
+		return sql.ErrNoRows
+	}
+
+	r.store.deleteQuote(id)
+	return nil
+}
+
+func (r *QuoteRepository) Count() (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.quotes), nil
+}
+
+func (r *QuoteRepository) CountByBookID(bookID int64) (int, error) {
+	return r.count(func(quote models.Quote) bool { return quote.BookID == bookID }), nil
+}
+
+func (r *QuoteRepository) CountByAuthorID(authorID int64) (int, error) {
+	return r.count(func(quote models.Quote) bool { return r.store.quoteHasAuthor(quote, authorID) }), nil
+}
+
+func (r *QuoteRepository) CountByCategoryID(categoryID int) (int, error) {
+	return r.count(func(quote models.Quote) bool { return r.store.quoteHasCategory(quote, categoryID) }), nil
+}
+
+func (r *QuoteRepository) CountByQuery(query repository.QuoteQuery) (int, error) {
+	return r.count(r.queryFilter(query)), nil
+}
+
+func (r *QuoteRepository) Search(searchTerm string, limit, offset int) ([]models.Quote, error) {
+	return r.searchQuotes(searchTerm, repository.SearchScopeText, limit, offset)
+}
+
+func (r *QuoteRepository) SearchInBookAndAuthor(searchTerm string, limit, offset int) ([]models.Quote, error) {
+	return r.searchQuotes(searchTerm, repository.SearchScopeAll, limit, offset)
+}
+
+// SearchFullText segue a busca do repositório SQLite sem o índice FTS5: todos
+// os termos precisam aparecer, sem ranking, das citações mais recentes para
+// as mais antigas
+func (r *QuoteRepository) SearchFullText(term string, scope repository.SearchScope, limit, offset int) ([]models.QuoteSearchResult, int, error) {
+	terms := repository.ParseSearchTerms(term)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matches := r.store.sortedQuotes(func(quote models.Quote) bool {
+		return r.store.quoteMatches(quote, terms, scope)
+	}, repository.QuoteOrderCreatedAt)
+
+	var results []models.QuoteSearchResult
+	for _, quote := range paginate(matches, limit, offset) {
+		results = append(results, models.QuoteSearchResult{
+			Quote:   quote,
+			Snippet: repository.HighlightTerms(quote.Text, terms),
+		})
+	}
+
+	return results, len(matches), nil
+}
+
+func (r *QuoteRepository) Exists(id int64) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.quotes[id]
+	return ok, nil
+}
+
+func (r *QuoteRepository) BookExists(bookID int64) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.books[bookID]
+	return ok, nil
+}
+
+func (r *QuoteRepository) find(keep func(models.Quote) bool, order repository.QuoteOrder, limit, offset int) []models.Quote {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return paginate(r.store.sortedQuotes(keep, order), limit, offset)
+}
+
+func (r *QuoteRepository) count(keep func(models.Quote) bool) int {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, quote := range r.store.quotes {
+		if keep(quote) {
+			count++
+		}
+	}
+	return count
+}
+
+// deleteQuote remove a citação e, como o CASCADE, as suas tags e os dias em
+// que foi a citação do dia
+func (s *Store) deleteQuote(id int64) {
+	delete(s.quotes, id)
+	delete(s.quoteTags, id)
+	for key, entry := range s.dailyQuotes {
+		if entry.QuoteID == id {
+			delete(s.dailyQuotes, key)
+		}
+	}
+}
+
+// queryFilter traduz os filtros de QuoteQuery; o texto segue a busca sem FTS5
+func (r *QuoteRepository) queryFilter(query repository.QuoteQuery) func(models.Quote) bool {
+	terms := repository.ParseSearchTerms(query.Text)
+	scope := query.TextScope
+	if scope == "" {
+		scope = repository.SearchScopeText
+	}
+
+	return func(quote models.Quote) bool {
+		book := r.store.books[quote.BookID]
+		length := utf8.RuneCountInString(quote.Text)
+
+		switch {
+		case len(query.IDs) > 0 && !containsID(query.IDs, quote.ID),
+			containsID(query.ExcludeIDs, quote.ID),
+			len(query.BookIDs) > 0 && !containsID(query.BookIDs, quote.BookID),
+			len(query.AuthorIDs) > 0 && !containsAny(r.store.bookAuthors[quote.BookID], query.AuthorIDs),
+			len(query.CategoryIDs) > 0 && !containsAny(r.store.bookCategories[quote.BookID], query.CategoryIDs),
+			len(query.TagIDs) > 0 && !containsAny(r.store.quoteTags[quote.ID], query.TagIDs),
+			len(query.TagNames) > 0 && !r.store.quoteHasTagName(quote.ID, query.TagNames),
+			query.CreatedFrom != nil && quote.CreatedAt.Before(*query.CreatedFrom),
+			query.CreatedTo != nil && !quote.CreatedAt.Before(*query.CreatedTo),
+			query.MinLength != nil && length < *query.MinLength,
+			query.MaxLength != nil && length > *query.MaxLength,
+			query.PublishedFrom != nil && book.PublishedYear < *query.PublishedFrom,
+			query.PublishedTo != nil && book.PublishedYear > *query.PublishedTo,
+			query.FavoritesOnly && !quote.Favorite,
+			query.MinRating != nil && (quote.Rating == nil || *quote.Rating < *query.MinRating):
+			return false
+		}
+
+		return r.store.quoteMatches(quote, terms, scope)
+	}
+}
+
+// queryOrder aplica a ordenação padrão de QuoteQuery; sem ranking, relevância
+// equivale a data
+func queryOrder(query repository.QuoteQuery) repository.QuoteOrder {
+	if query.Order == "" || query.Order == repository.QuoteOrderRelevance {
+		return repository.QuoteOrderCreatedAt
+	}
+	return query.Order
+}
+
+func (r *QuoteRepository) searchQuotes(term string, scope repository.SearchScope, limit, offset int) ([]models.Quote, error) {
+	results, _, err := r.SearchFullText(term, scope, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotes []models.Quote
+	for _, result := range results {
+		quotes = append(quotes, result.Quote)
+	}
+	return quotes, nil
+}
+
+// quoteSortKeys espelha QuoteOrder.sortKeys do repositório SQLite
+func quoteSortKeys(order repository.QuoteOrder) sortKeys[models.Quote] {
+	keys := sortKeys[models.Quote]{order: string(order)}
+
+	switch order {
+	case repository.QuoteOrderUpdatedAt:
+		keys.desc = []bool{true, true}
+		keys.keys = func(q models.Quote) []interface{} {
+			return []interface{}{timeKey(q.UpdatedAt), q.ID}
+		}
+	case repository.QuoteOrderBookTitle:
+		keys.desc = []bool{false, false, true, true}
+		keys.keys = func(q models.Quote) []interface{} {
+			return []interface{}{q.Book.Title, q.BookID, timeKey(q.CreatedAt), q.ID}
+		}
+	case repository.QuoteOrderLocation:
+		keys.desc = []bool{false, false, false, false, false}
+		keys.keys = func(q models.Quote) []interface{} {
+			missing, location := int64(1), int64(0)
+			if q.LocationStart != nil {
+				missing, location = 0, int64(*q.LocationStart)
+			}
+			return []interface{}{q.Book.Title, q.BookID, missing, location, q.ID}
+		}
+	default:
+		keys.desc = []bool{true, true}
+		keys.keys = func(q models.Quote) []interface{} {
+			return []interface{}{timeKey(q.CreatedAt), q.ID}
+		}
+	}
+
+	return keys
+}
+
+// sortedQuotes filtra as citações e as ordena como repository.QuoteOrder
+// define no SQL
+func (s *Store) sortedQuotes(keep func(models.Quote) bool, order repository.QuoteOrder) []models.Quote {
+	var quotes []models.Quote
+	for _, quote := range s.quotes {
+		if keep(quote) {
+			quotes = append(quotes, *s.loadQuote(quote))
+		}
+	}
+
+	if order == repository.QuoteOrderRandom {
+		rand.Shuffle(len(quotes), func(i, j int) { quotes[i], quotes[j] = quotes[j], quotes[i] })
+		return quotes
+	}
+
+	keys := quoteSortKeys(order)
+	sort.Slice(quotes, func(i, j int) bool { return keys.less(quotes[i], quotes[j]) })
+	return quotes
+}
+
+// loadQuote monta a citação com as tags e o livro com seus autores e categorias
+func (s *Store) loadQuote(quote models.Quote) *models.Quote {
+	quote.Book = s.loadBook(s.books[quote.BookID])
+	quote.Tags = s.quoteTagList(quote.ID)
+	return &quote
+}
+
+// quoteMatches exige que cada termo apareça no texto ou, em SearchScopeAll,
+// também no título ou no nome de um dos autores
+func (s *Store) quoteMatches(quote models.Quote, terms []repository.SearchTerm, scope repository.SearchScope) bool {
+	fields := []string{quote.Text}
+	if scope != repository.SearchScopeText {
+		fields = append(fields, s.books[quote.BookID].Title)
+		for _, author := range s.bookAuthorList(quote.BookID) {
+			fields = append(fields, author.Name)
+		}
+	}
+
+	for _, term := range terms {
+		if !anyLike(fields, term.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Store) quoteHasAuthor(quote models.Quote, authorID int64) bool {
+	return containsID(s.bookAuthors[quote.BookID], authorID)
+}
+
+// quoteHasTagName compara os nomes por models.TagKey, como tag.name_key
+func (s *Store) quoteHasTagName(quoteID int64, names []string) bool {
+	for _, tagID := range s.quoteTags[quoteID] {
+		for _, name := range names {
+			if models.TagKey(s.tags[tagID].Name) == models.TagKey(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Store) quoteHasCategory(quote models.Quote, categoryID int) bool {
+	return containsID(s.bookCategories[quote.BookID], categoryID)
+}
+
+// validQuote confere os CHECKs da tabela quote
+func validQuote(quote models.Quote) bool {
+	switch quote.Kind {
+	case models.QuoteKindHighlight, models.QuoteKindNote, models.QuoteKindBookmark:
+	default:
+		return false
+	}
+	if quote.Rating != nil && (*quote.Rating < models.MinQuoteRating || *quote.Rating > models.MaxQuoteRating) {
+		return false
+	}
+	return len(quote.Text) >= 1
+}
+
+// copyInt evita que o store compartilhe ponteiros com quem o chama
+func copyInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	v := *value
+	return &v
+}
+
+func anyLike(fields []string, term string) bool {
+	for _, field := range fields {
+		if like(field, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+// Package memory implementa os repositórios em memória, para testes de
+// services e demonstrações sem SQLite. As regras de ordenação, paginação,
+// constraints e CASCADE acompanham as do schema em database/migration.go
+package memory
+
+import (
+	"fmt"
+	"quote-api/apperrors"
+	"quote-api/models"
+	"quote-api/repository"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrConstraint equivale às violações de constraint do SQLite (UNIQUE,
+// FOREIGN KEY, CHECK) e, como elas, é tratado como conflito
+var ErrConstraint = fmt.Errorf("constraint failed: %w", apperrors.ErrConflict)
+
+// Store guarda as tabelas compartilhadas pelos repositórios em memória. Os
+// repositórios de um mesmo Store enxergam os mesmos dados, como se usassem o
+// mesmo banco
+type Store struct {
+	mu sync.RWMutex
+
+	// books e quotes guardam só as colunas das tabelas; autores, categorias
+	// e livro são montados a cada leitura, como nos JOINs do SQLite
+	authors        map[int64]models.Author
+	books          map[int64]models.Book
+	categories     map[int]models.Category
+	quotes         map[int64]models.Quote
+	tags           map[int64]models.Tag
+	bookAuthors    map[int64][]int64
+	bookCategories map[int64][]int
+	quoteTags      map[int64][]int64
+	dailyQuotes    map[dailyKey]models.DailyQuote
+
+	lastAuthorID   int64
+	lastBookID     int64
+	lastCategoryID int
+	lastQuoteID    int64
+	lastTagID      int64
+	lastDailyID    int64
+}
+
+func NewStore() *Store {
+	return &Store{
+		authors:        make(map[int64]models.Author),
+		books:          make(map[int64]models.Book),
+		categories:     make(map[int]models.Category),
+		quotes:         make(map[int64]models.Quote),
+		tags:           make(map[int64]models.Tag),
+		bookAuthors:    make(map[int64][]int64),
+		bookCategories: make(map[int64][]int),
+		quoteTags:      make(map[int64][]int64),
+		dailyQuotes:    make(map[dailyKey]models.DailyQuote),
+	}
+}
+
+func (s *Store) Authors() *AuthorRepository {
+	return &AuthorRepository{store: s}
+}
+
+func (s *Store) Books() *BookRepository {
+	return &BookRepository{store: s}
+}
+
+func (s *Store) Categories() *CategoryRepository {
+	return &CategoryRepository{store: s}
+}
+
+func (s *Store) Quotes() *QuoteRepository {
+	return &QuoteRepository{store: s}
+}
+
+func (s *Store) Tags() *TagRepository {
+	return &TagRepository{store: s}
+}
+
+func (s *Store) DailyQuotes() *DailyQuoteRepository {
+	return &DailyQuoteRepository{store: s}
+}
+
+var (
+	_ repository.Authors     = (*AuthorRepository)(nil)
+	_ repository.Books       = (*BookRepository)(nil)
+	_ repository.Categories  = (*CategoryRepository)(nil)
+	_ repository.Quotes      = (*QuoteRepository)(nil)
+	_ repository.Tags        = (*TagRepository)(nil)
+	_ repository.DailyQuotes = (*DailyQuoteRepository)(nil)
+)
+
+// paginate aplica LIMIT/OFFSET como o SQLite: limit negativo não limita e
+// uma página vazia é nil
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return nil
+	}
+
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+// lower imita o LOWER do SQLite, que só converte letras ASCII
+func lower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, s)
+}
+
+// like imita `value LIKE '%term%'`, que também ignora caixa só em ASCII
+func like(value, term string) bool {
+	return strings.Contains(lower(value), lower(term))
+}
+
+func sortByName[T any](items []T, name func(T) string, id func(T) int64) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if name(items[i]) != name(items[j]) {
+			return name(items[i]) < name(items[j])
+		}
+		return id(items[i]) < id(items[j])
+	})
+}
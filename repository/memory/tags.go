@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"database/sql"
+	"quote-api/models"
+	"time"
+)
+
+type TagRepository struct {
+	store *Store
+}
+
+func (r *TagRepository) FindAll(limit, offset int) ([]models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return paginate(r.store.sortedTags(func(models.Tag) bool { return true }), limit, offset), nil
+}
+
+func (r *TagRepository) FindByID(id int64) (*models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tag, ok := r.store.tags[id]
+	if !ok {
+		return nil, nil
+	}
+	return r.store.countedTag(tag), nil
+}
+
+// FindByName compara os nomes por models.TagKey, como tag.name_key
+func (r *TagRepository) FindByName(name string) (*models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, tag := range r.store.tags {
+		if models.TagKey(tag.Name) == models.TagKey(name) {
+			return r.store.countedTag(tag), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *TagRepository) FindByQuoteID(quoteID int64) ([]models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.quoteTagList(quoteID), nil
+}
+
+// Create respeita o UNIQUE de tag.name_key, que ignora maiúsculas
+func (r *TagRepository) Create(tag models.Tag) (*models.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if tag.Name == "" || r.store.tagNameTaken(tag.Name, 0) {
+		return nil, ErrConstraint
+	}
+
+	now := time.Now()
+	r.store.lastTagID++
+	created := models.Tag{ID: r.store.lastTagID, Name: tag.Name, CreatedAt: now, UpdatedAt: now}
+	r.store.tags[created.ID] = created
+
+	return &created, nil
+}
+
+func (r *TagRepository) Update(id int64, tag models.Tag) (*models.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.tags[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if tag.Name == "" || r.store.tagNameTaken(tag.Name, id) {
+		return nil, ErrConstraint
+	}
+
+	existing.Name = tag.Name
+	existing.UpdatedAt = time.Now()
+	r.store.tags[id] = existing
+
+	return r.store.countedTag(existing), nil
+}
+
+// Delete remove a tag e, como o CASCADE de quote_tag, seus vínculos com citações
+func (r *TagRepository) Delete(id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tags[id]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.store.tags, id)
+	for quoteID, tagIDs := range r.store.quoteTags {
+		r.store.quoteTags[quoteID] = removeID(tagIDs, id)
+	}
+
+	return nil
+}
+
+func (r *TagRepository) Count() (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.tags), nil
+}
+
+func (r *TagRepository) CountSearch(searchTerm string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, tag := range r.store.tags {
+		if like(tag.Name, searchTerm) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *TagRepository) Search(searchTerm string, limit, offset int) ([]models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tags := r.store.sortedTags(func(tag models.Tag) bool {
+		return like(tag.Name, searchTerm)
+	})
+	return paginate(tags, limit, offset), nil
+}
+
+// AddToQuote exige que a citação e a tag existam, como as FOREIGN KEYs de
+// quote_tag; aplicar de novo não tem efeito
+func (r *TagRepository) AddToQuote(quoteID, tagID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.quotes[quoteID]; !ok {
+		return ErrConstraint
+	}
+	if _, ok := r.store.tags[tagID]; !ok {
+		return ErrConstraint
+	}
+
+	if !containsID(r.store.quoteTags[quoteID], tagID) {
+		r.store.quoteTags[quoteID] = append(r.store.quoteTags[quoteID], tagID)
+	}
+	return nil
+}
+
+func (r *TagRepository) RemoveFromQuote(quoteID, tagID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !containsID(r.store.quoteTags[quoteID], tagID) {
+		return sql.ErrNoRows
+	}
+
+	r.store.quoteTags[quoteID] = removeID(r.store.quoteTags[quoteID], tagID)
+	return nil
+}
+
+func (s *Store) tagNameTaken(name string, exceptID int64) bool {
+	for _, tag := range s.tags {
+		if models.TagKey(tag.Name) == models.TagKey(name) && tag.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// countedTag preenche QuoteCount, como a subquery do repositório SQLite
+func (s *Store) countedTag(tag models.Tag) *models.Tag {
+	tag.QuoteCount = 0
+	for _, tagIDs := range s.quoteTags {
+		if containsID(tagIDs, tag.ID) {
+			tag.QuoteCount++
+		}
+	}
+	return &tag
+}
+
+// sortedTags filtra as tags, as ordena por nome sem diferenciar maiúsculas e
+// preenche QuoteCount
+func (s *Store) sortedTags(keep func(models.Tag) bool) []models.Tag {
+	var tags []models.Tag
+	for _, tag := range s.tags {
+		if keep(tag) {
+			tags = append(tags, *s.countedTag(tag))
+		}
+	}
+
+	sortByName(tags,
+		func(t models.Tag) string { return lower(t.Name) },
+		func(t models.Tag) int64 { return t.ID },
+	)
+	return tags
+}
+
+// quoteTagList retorna as tags da citação ordenadas por nome, sem QuoteCount
+func (s *Store) quoteTagList(quoteID int64) []models.Tag {
+	var tags []models.Tag
+	for _, tagID := range s.quoteTags[quoteID] {
+		tags = append(tags, s.tags[tagID])
+	}
+
+	sortByName(tags,
+		func(t models.Tag) string { return lower(t.Name) },
+		func(t models.Tag) int64 { return t.ID },
+	)
+	return tags
+}
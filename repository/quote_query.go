@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"quote-api/models"
+	"strings"
+	"time"
+)
+
+// QuoteQuery descreve uma listagem de citações. Os filtros preenchidos são
+// combinados com AND; cada lista de IDs casa com qualquer um dos seus IDs
+type QuoteQuery struct {
+	// IDs restringe a listagem a essas citações; ExcludeIDs as deixa de fora
+	IDs        []int64
+	ExcludeIDs []int64
+
+	BookIDs     []int64
+	AuthorIDs   []int64
+	CategoryIDs []int
+	TagIDs      []int64
+	// TagNames casa com o nome das tags ignorando maiúsculas/minúsculas
+	// (models.TagKey)
+	TagNames []string
+
+	// CreatedFrom é inclusivo e CreatedTo, exclusivo
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	// Text usa a mesma sintaxe de SearchFullText; TextScope vazio busca só no
+	// texto da citação
+	Text      string
+	TextScope SearchScope
+
+	// Tamanho do texto em caracteres, inclusivo
+	MinLength *int
+	MaxLength *int
+
+	// Ano de publicação do livro, inclusivo
+	PublishedFrom *int
+	PublishedTo   *int
+
+	// FavoritesOnly deixa só as favoritas; MinRating exclui também as sem avaliação
+	FavoritesOnly bool
+	MinRating     *int
+
+	// Order vazio ordena por relevância quando há Text e por data nos demais casos
+	Order QuoteOrder
+
+	// Cursor continua a listagem a partir de uma página anterior e, quando
+	// presente, substitui Offset. As ordenações random e relevance não aceitam
+	// cursor
+	Cursor *Cursor
+
+	Limit  int
+	Offset int
+}
+
+// QuoteOrder define a ordenação das listagens de citações
+type QuoteOrder string
+
+const (
+	QuoteOrderCreatedAt QuoteOrder = "created_at"
+	QuoteOrderUpdatedAt QuoteOrder = "updated_at"
+	QuoteOrderBookTitle QuoteOrder = "book_title"
+	QuoteOrderLocation  QuoteOrder = "location"
+	QuoteOrderRandom    QuoteOrder = "random"
+	// QuoteOrderRelevance só vale com Text e o índice de busca; fora disso
+	// equivale a QuoteOrderCreatedAt
+	QuoteOrderRelevance QuoteOrder = "relevance"
+)
+
+// Valid indica se o é uma ordenação conhecida
+func (o QuoteOrder) Valid() bool {
+	switch o {
+	case QuoteOrderCreatedAt, QuoteOrderUpdatedAt, QuoteOrderBookTitle,
+		QuoteOrderLocation, QuoteOrderRandom, QuoteOrderRelevance:
+		return true
+	}
+	return false
+}
+
+// sortKeys retorna as chaves da ordenação, terminadas pelo id para que cada
+// linha tenha uma posição única, ou nil quando a ordenação não admite cursor.
+// Por posição, as citações ficam agrupadas por livro e em ordem de leitura;
+// as sem posição vão ao final
+func (o QuoteOrder) sortKeys() []sortKey {
+	switch o {
+	case QuoteOrderUpdatedAt:
+		return []sortKey{{"q.updated_at", true}, {"q.id", true}}
+	case QuoteOrderBookTitle:
+		return []sortKey{{"b.title", false}, {"q.book_id", false}, {"q.created_at", true}, {"q.id", true}}
+	case QuoteOrderLocation:
+		return []sortKey{{"b.title", false}, {"q.book_id", false}, {"q.location_start IS NULL", false},
+			{"COALESCE(q.location_start, 0)", false}, {"q.id", false}}
+	case QuoteOrderRandom, QuoteOrderRelevance:
+		return nil
+	}
+	return []sortKey{{"q.created_at", true}, {"q.id", true}}
+}
+
+// orderClause retorna o ORDER BY correspondente
+func (o QuoteOrder) orderClause() string {
+	switch o {
+	case QuoteOrderRandom:
+		return "RANDOM()"
+	case QuoteOrderRelevance:
+		return searchRank + ", q.id ASC"
+	}
+	return keysetOrder(o.sortKeys(), false)
+}
+
+// order resolve a ordenação padrão e descarta a relevância quando não há
+// ranking disponível
+func (q QuoteQuery) order(indexed bool) QuoteOrder {
+	order := q.Order
+	if order == "" && q.Text != "" {
+		order = QuoteOrderRelevance
+	}
+	if order == QuoteOrderRelevance && (!indexed || len(ParseSearchTerms(q.Text)) == 0) {
+		order = QuoteOrderCreatedAt
+	}
+	if order == "" {
+		order = QuoteOrderCreatedAt
+	}
+	return order
+}
+
+func (q QuoteQuery) scope() SearchScope {
+	if q.TextScope == "" {
+		return SearchScopeText
+	}
+	return q.TextScope
+}
+
+// from compila os filtros em FROM ... WHERE ..., compartilhado pela listagem
+// e pela contagem. Com o índice de busca, o texto é filtrado pelo FTS5; sem
+// ele, por LIKE em cada termo. Com keys, inclui também a condição do Cursor
+func (q QuoteQuery) from(indexed bool, keys ...sortKey) (string, []interface{}) {
+	var b strings.Builder
+	var conditions []string
+	var args []interface{}
+
+	b.WriteString(" FROM quote q INNER JOIN book b ON q.book_id = b.id")
+
+	if terms := ParseSearchTerms(q.Text); len(terms) > 0 {
+		if indexed {
+			b.WriteString(" INNER JOIN quote_fts ON quote_fts.rowid = q.id")
+			conditions = append(conditions, "quote_fts MATCH ?")
+			args = append(args, ftsQuery(terms, q.scope()))
+		} else {
+			likeConditions, likeArgs := likeClauses(terms, q.scope())
+			conditions = append(conditions, likeConditions...)
+			args = append(args, likeArgs...)
+		}
+	}
+
+	if len(q.IDs) > 0 {
+		placeholders, ids := inList(q.IDs)
+		conditions = append(conditions, "q.id IN ("+placeholders+")")
+		args = append(args, ids...)
+	}
+	if len(q.ExcludeIDs) > 0 {
+		placeholders, ids := inList(q.ExcludeIDs)
+		conditions = append(conditions, "q.id NOT IN ("+placeholders+")")
+		args = append(args, ids...)
+	}
+
+	if len(q.BookIDs) > 0 {
+		placeholders, ids := inList(q.BookIDs)
+		conditions = append(conditions, "q.book_id IN ("+placeholders+")")
+		args = append(args, ids...)
+	}
+
+	if len(q.AuthorIDs) > 0 {
+		placeholders, ids := inList(q.AuthorIDs)
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM book_author ba WHERE ba.book_id = q.book_id AND ba.author_id IN ("+placeholders+"))")
+		args = append(args, ids...)
+	}
+
+	if len(q.CategoryIDs) > 0 {
+		placeholders, ids := inList(q.CategoryIDs)
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM book_category bc WHERE bc.book_id = q.book_id AND bc.category_id IN ("+placeholders+"))")
+		args = append(args, ids...)
+	}
+
+	if len(q.TagIDs) > 0 {
+		placeholders, ids := inList(q.TagIDs)
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM quote_tag qt WHERE qt.quote_id = q.id AND qt.tag_id IN ("+placeholders+"))")
+		args = append(args, ids...)
+	}
+
+	if len(q.TagNames) > 0 {
+		keys := make([]string, len(q.TagNames))
+		for i, name := range q.TagNames {
+			keys[i] = models.TagKey(name)
+		}
+		placeholders, values := inList(keys)
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM quote_tag qt INNER JOIN tag t ON t.id = qt.tag_id
+            WHERE qt.quote_id = q.id AND t.name_key IN (`+placeholders+`))`)
+		args = append(args, values...)
+	}
+
+	// julianday normaliza o fuso gravado junto com as datas
+	if q.CreatedFrom != nil {
+		conditions = append(conditions, "julianday(q.created_at) >= julianday(?)")
+		args = append(args, q.CreatedFrom.UTC())
+	}
+	if q.CreatedTo != nil {
+		conditions = append(conditions, "julianday(q.created_at) < julianday(?)")
+		args = append(args, q.CreatedTo.UTC())
+	}
+
+	if q.MinLength != nil {
+		conditions = append(conditions, "length(q.text) >= ?")
+		args = append(args, *q.MinLength)
+	}
+	if q.MaxLength != nil {
+		conditions = append(conditions, "length(q.text) <= ?")
+		args = append(args, *q.MaxLength)
+	}
+
+	if q.PublishedFrom != nil {
+		conditions = append(conditions, "b.published_year >= ?")
+		args = append(args, *q.PublishedFrom)
+	}
+	if q.PublishedTo != nil {
+		conditions = append(conditions, "b.published_year <= ?")
+		args = append(args, *q.PublishedTo)
+	}
+
+	if q.FavoritesOnly {
+		conditions = append(conditions, "q.favorite = 1")
+	}
+	if q.MinRating != nil {
+		conditions = append(conditions, "q.rating >= ?")
+		args = append(args, *q.MinRating)
+	}
+
+	if q.Cursor != nil && len(keys) > 0 {
+		condition, keyArgs := keysetCondition(keys, q.Cursor.Keys, q.Cursor.Before)
+		conditions = append(conditions, condition)
+		args = append(args, keyArgs...)
+	}
+
+	if len(conditions) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	return b.String(), args
+}
+
+// likeClauses exige que cada termo apareça no texto ou, em SearchScopeAll,
+// também no título ou no nome de um dos autores. "%" e "_" nos termos valem
+// como texto, não como curingas
+func likeClauses(terms []SearchTerm, scope SearchScope) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term.Text) + "%"
+		if scope == SearchScopeText {
+			conditions = append(conditions, `q.text LIKE ? ESCAPE '\'`)
+			args = append(args, pattern)
+			continue
+		}
+
+		conditions = append(conditions, `(q.text LIKE ? ESCAPE '\' OR b.title LIKE ? ESCAPE '\' OR EXISTS (
+            SELECT 1 FROM book_author ba
+            INNER JOIN author a ON a.id = ba.author_id
+            WHERE ba.book_id = b.id AND a.name LIKE ? ESCAPE '\'
+        ))`)
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	return conditions, args
+}
+
+// FindByQuery lista as citações que atendem a query em uma única consulta
+func (r *QuoteRepository) FindByQuery(query QuoteQuery) ([]models.Quote, error) {
+	page, err := r.FindPageByQuery(query)
+	return page.Items, err
+}
+
+// FindPageByQuery lista uma página de citações com os cursores das páginas
+// vizinhas. Sem Cursor, a página começa em Offset
+func (r *QuoteRepository) FindPageByQuery(query QuoteQuery) (Page[models.Quote], error) {
+	indexed, err := r.indexedFor(query)
+	if err != nil {
+		return Page[models.Quote]{}, err
+	}
+
+	order := query.order(indexed)
+	keys := order.sortKeys()
+	if query.Cursor != nil && (query.Cursor.Order != string(order) || len(query.Cursor.Keys) != len(keys)) {
+		return Page[models.Quote]{}, ErrInvalidCursor
+	}
+
+	from, args := query.from(indexed, keys...)
+
+	columns := ""
+	if keys != nil {
+		columns = ", " + keyColumns(keys)
+	}
+
+	orderBy := order.orderClause()
+	offset := query.Offset
+	if query.Cursor != nil {
+		orderBy = keysetOrder(keys, query.Cursor.Before)
+		offset = 0
+	}
+
+	statement := `
+        SELECT ` + quoteColumns + columns + from + `
+        ORDER BY ` + orderBy + `
+        LIMIT ? OFFSET ?
+    `
+
+	rows, err := r.db.Query(statement, append(args, fetchLimit(query.Limit), offset)...)
+	if err != nil {
+		return Page[models.Quote]{}, err
+	}
+	defer rows.Close()
+
+	var quotes []models.Quote
+	var rowKeys [][]interface{}
+	for rows.Next() {
+		values, dest := keyDest(len(keys))
+		quote, err := scanQuote(rows, dest...)
+		if err != nil {
+			return Page[models.Quote]{}, err
+		}
+		quotes = append(quotes, quote)
+		rowKeys = append(rowKeys, values)
+	}
+	if err := rows.Err(); err != nil {
+		return Page[models.Quote]{}, err
+	}
+	rows.Close()
+
+	page := pageOf(string(order), quotes, rowKeys, query.Cursor, query.Limit, offset, keys != nil)
+	if err := r.attachRelations(page.Items); err != nil {
+		return Page[models.Quote]{}, err
+	}
+
+	return page, nil
+}
+
+// CountByQuery conta as citações que FindByQuery listaria sem paginação
+func (r *QuoteRepository) CountByQuery(query QuoteQuery) (int, error) {
+	indexed, err := r.indexedFor(query)
+	if err != nil {
+		return 0, err
+	}
+
+	from, args := query.from(indexed)
+
+	var count int
+	err = r.db.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&count)
+	return count, err
+}
+
+// indexedFor só consulta o schema quando a query filtra por texto
+func (r *QuoteRepository) indexedFor(query QuoteQuery) (bool, error) {
+	if query.Text == "" {
+		return false, nil
+	}
+	return r.hasSearchIndex()
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"math"
+	"math/rand"
+	"quote-api/models"
+	"sort"
+)
+
+// RandomWeighting define como FindRandom sorteia as citações
+type RandomWeighting string
+
+const (
+	// RandomUniform dá a mesma chance a todas as citações
+	RandomUniform RandomWeighting = "uniform"
+	// RandomFavorites sorteia proporcionalmente a QuoteWeight, favorecendo as
+	// favoritas e as mais bem avaliadas
+	RandomFavorites RandomWeighting = "favorites"
+)
+
+// Valid indica se w é um modo de sorteio conhecido
+func (w RandomWeighting) Valid() bool {
+	return w == RandomUniform || w == RandomFavorites
+}
+
+// favoriteWeight multiplica o peso das citações favoritas
+const favoriteWeight = 4
+
+// maxQuoteWeight é o maior QuoteWeight possível
+const maxQuoteWeight = (1 + models.MaxQuoteRating) * favoriteWeight
+
+// quoteWeightExpr calcula QuoteWeight em SQL
+const quoteWeightExpr = "(1 + COALESCE(q.rating, 0)) * (1 + 3 * q.favorite)"
+
+// QuoteWeight é o peso da citação no sorteio RandomFavorites: 1 mais a
+// avaliação, multiplicado por favoriteWeight se ela for favorita. Uma favorita
+// com 5 estrelas sai 24 vezes mais que uma citação sem avaliação
+func QuoteWeight(quote models.Quote) int {
+	weight := 1
+	if quote.Rating != nil {
+		weight += *quote.Rating
+	}
+	if quote.Favorite {
+		weight *= favoriteWeight
+	}
+	return weight
+}
+
+// Limites da amostragem por faixa de IDs: depois de sampleRounds rodadas sem
+// encontrar citações suficientes, os IDs que atendem aos filtros são lidos de
+// uma vez
+const (
+	sampleRounds   = 4
+	sampleMaxBatch = 1000
+)
+
+// FindRandom sorteia uma citação; retorna nil se não houver nenhuma
+func (r *QuoteRepository) FindRandom(weighting RandomWeighting) (*models.Quote, error) {
+	quotes, err := r.FindRandomByQuery(QuoteQuery{}, 1, weighting)
+	if err != nil || len(quotes) == 0 {
+		return nil, err
+	}
+	return &quotes[0], nil
+}
+
+// FindRandomByQuery sorteia até n citações distintas entre as que atendem aos
+// filtros de query; a ordenação e a paginação de query são ignoradas.
+//
+// Em vez de ordenar a tabela inteira por RANDOM(), sorteia IDs entre o menor e
+// o maior ID de quote e busca os sorteados pela chave primária, junto com os
+// filtros; IDs removidos ou fora dos filtros são descartados e a rodada seguinte
+// sorteia mais IDs, conforme a taxa de acerto. Com RandomFavorites, cada
+// citação encontrada é aceita com probabilidade QuoteWeight / maxQuoteWeight.
+// Se os filtros forem seletivos demais para isso, lê só os IDs que os atendem
+func (r *QuoteRepository) FindRandomByQuery(query QuoteQuery, n int, weighting RandomWeighting) ([]models.Quote, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	indexed, err := r.indexedFor(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	if len(query.IDs) == 0 {
+		if ids, err = r.probeRandomIDs(query, indexed, n, weighting); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ids) < n {
+		rest, err := r.scanRandomIDs(query, indexed, ids, n-len(ids), weighting)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, rest...)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	quotes, err := r.FindByQuery(QuoteQuery{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	// FindByQuery ordena por data; a resposta segue a ordem do sorteio
+	position := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	sort.Slice(quotes, func(i, j int) bool {
+		return position[quotes[i].ID] < position[quotes[j].ID]
+	})
+
+	return quotes, nil
+}
+
+// probeRandomIDs sorteia IDs na faixa de IDs de quote, em rodadas
+func (r *QuoteRepository) probeRandomIDs(query QuoteQuery, indexed bool, n int, weighting RandomWeighting) ([]int64, error) {
+	// cada subquery lê só uma ponta da chave primária; MIN e MAX no mesmo
+	// SELECT fariam o SQLite percorrer a tabela inteira
+	bounds := `
+        SELECT COALESCE((SELECT MIN(id) FROM quote), 0),
+               COALESCE((SELECT MAX(id) FROM quote), -1)
+    `
+
+	var minID, maxID int64
+	err := r.db.QueryRow(bounds).Scan(&minID, &maxID)
+	if err != nil {
+		return nil, err
+	}
+
+	span := maxID - minID + 1
+	// excluded guarda os IDs que não existem, não atendem aos filtros ou já
+	// foram escolhidos. Os rejeitados pelo peso podem ser sorteados de novo
+	excluded := make(map[int64]bool)
+	var picked []int64
+	tries := 0
+
+	for round := 0; round < sampleRounds && len(picked) < n && int64(len(excluded)) < span; round++ {
+		// o tamanho da rodada segue a taxa de acerto das anteriores; na
+		// primeira, RandomFavorites conta com a rejeição das citações de peso 1
+		missing := n - len(picked)
+		batch := missing * 2
+		switch {
+		case len(picked) == 0 && tries == 0 && weighting == RandomFavorites:
+			batch = missing * 2 * maxQuoteWeight
+		case len(picked) > 0:
+			batch = missing * tries / len(picked) * 2
+		case tries > 0:
+			batch = tries * 4
+		}
+		batch = min(batch, sampleMaxBatch)
+
+		candidates := make([]int64, 0, batch)
+		for len(candidates) < batch {
+			if id := minID + rand.Int63n(span); !excluded[id] {
+				candidates = append(candidates, id)
+			}
+		}
+		tries += len(candidates)
+
+		probe := query
+		probe.IDs = candidates
+		weights, err := r.randomWeights(probe, indexed)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range candidates {
+			if excluded[id] {
+				continue
+			}
+
+			weight, ok := weights[id]
+			if !ok {
+				excluded[id] = true
+				continue
+			}
+
+			if weighting == RandomFavorites && rand.Intn(maxQuoteWeight) >= weight {
+				continue
+			}
+
+			excluded[id] = true
+			picked = append(picked, id)
+			if len(picked) == n {
+				break
+			}
+		}
+	}
+
+	return picked, nil
+}
+
+// scanRandomIDs lê os IDs e pesos de todas as citações que atendem aos filtros,
+// exceto as já escolhidas, e sorteia n entre elas
+func (r *QuoteRepository) scanRandomIDs(query QuoteQuery, indexed bool, picked []int64, n int, weighting RandomWeighting) ([]int64, error) {
+	scan := query
+	scan.ExcludeIDs = append(append([]int64(nil), query.ExcludeIDs...), picked...)
+
+	weights, err := r.randomWeights(scan, indexed)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var weight func(int64) int
+	if weighting == RandomFavorites {
+		weight = func(id int64) int { return weights[id] }
+	}
+
+	return PickRandom(ids, n, weight), nil
+}
+
+// randomWeights retorna o QuoteWeight de cada citação que atende a query
+func (r *QuoteRepository) randomWeights(query QuoteQuery, indexed bool) (map[int64]int, error) {
+	from, args := query.from(indexed)
+
+	rows, err := r.db.Query("SELECT q.id, "+quoteWeightExpr+from, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var weight int
+		if err := rows.Scan(&id, &weight); err != nil {
+			return nil, err
+		}
+		weights[id] = weight
+	}
+
+	return weights, rows.Err()
+}
+
+// PickRandom sorteia até n itens distintos de items, na ordem do sorteio. Sem
+// weight, todos têm a mesma chance; com weight, cada sorteio é proporcional ao
+// peso dos itens que restam (amostragem de Efraimidis-Spirakis)
+func PickRandom[T any](items []T, n int, weight func(T) int) []T {
+	n = min(n, len(items))
+	if n <= 0 {
+		return nil
+	}
+
+	if weight == nil {
+		result := make([]T, n)
+		for i, index := range rand.Perm(len(items))[:n] {
+			result[i] = items[index]
+		}
+		return result
+	}
+
+	keys := make([]float64, len(items))
+	order := make([]int, len(items))
+	for i, item := range items {
+		keys[i] = math.Pow(rand.Float64(), 1/float64(weight(item)))
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return keys[order[i]] > keys[order[j]] })
+
+	result := make([]T, n)
+	for i, index := range order[:n] {
+		result[i] = items[index]
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"quote-api/database"
+	"testing"
+)
+
+// seedQuotes cria quotes citações em 1000 livros. Uma em cada dez é favorita,
+// uma em cada três tem avaliação e uma em cada cem tem a tag "rara"
+func seedQuotes(tb testing.TB, store *database.Store, quotes int) {
+	const books = 1000
+
+	seedTx(tb, store, func(tx *sql.Tx) error {
+		err := execEach(tx, "INSERT INTO book (id, title, published_year) VALUES (?, ?, ?)", books, func(i int) []interface{} {
+			return []interface{}{i + 1, fmt.Sprintf("Livro %04d", i+1), 1900 + i%120}
+		})
+		if err != nil {
+			return err
+		}
+
+		err = execEach(tx, "INSERT INTO quote (id, book_id, text, favorite, rating) VALUES (?, ?, ?, ?, ?)", quotes, func(i int) []interface{} {
+			var rating interface{}
+			if i%3 == 0 {
+				rating = i%5 + 1
+			}
+			return []interface{}{i + 1, i%books + 1, fmt.Sprintf("Citação número %d", i+1), i%10 == 0, rating}
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("INSERT INTO tag (id, name, name_key) VALUES (1, 'rara', 'rara')"); err != nil {
+			return err
+		}
+		return execEach(tx, "INSERT INTO quote_tag (quote_id, tag_id) VALUES (?, 1)", quotes/100, func(i int) []interface{} {
+			return []interface{}{i*100 + 1}
+		})
+	})
+}
+
+// BenchmarkQuoteRepository_FindRandomByQuery sorteia citações em um banco com
+// cerca de um milhão delas (50 mil com -short), sem filtros e com filtros de
+// seletividade diferente. Os filtros amplos são resolvidos pela amostragem de
+// IDs; os seletivos, como um livro ou uma tag rara, leem os IDs que os atendem
+func BenchmarkQuoteRepository_FindRandomByQuery(b *testing.B) {
+	quotes := 1_000_000
+	if testing.Short() {
+		quotes = 50_000
+	}
+
+	store := newTestStore(b)
+	seedQuotes(b, store, quotes)
+	repo := NewQuoteRepository(store)
+	minRating := 4
+
+	cases := []struct {
+		name      string
+		query     QuoteQuery
+		n         int
+		weighting RandomWeighting
+	}{
+		{"uniform", QuoteQuery{}, 1, RandomUniform},
+		{"favorites", QuoteQuery{}, 1, RandomFavorites},
+		{"uniform/n=20", QuoteQuery{}, 20, RandomUniform},
+		{"favorites/n=20", QuoteQuery{}, 20, RandomFavorites},
+		{"filter/favorites-only", QuoteQuery{FavoritesOnly: true}, 1, RandomUniform},
+		{"filter/min-rating", QuoteQuery{MinRating: &minRating}, 1, RandomFavorites},
+		{"filter/book", QuoteQuery{BookIDs: []int64{42}}, 1, RandomUniform},
+		{"filter/tag", QuoteQuery{TagNames: []string{"Rara"}}, 1, RandomUniform},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				found, err := repo.FindRandomByQuery(c.query, c.n, c.weighting)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(found) != c.n {
+					b.Fatalf("sorteou %d citações, esperava %d", len(found), c.n)
+				}
+			}
+		})
+	}
+}
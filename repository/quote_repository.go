@@ -8,18 +8,43 @@ import (
 )
 
 type QuoteRepository struct {
-	db *sql.DB
+	db             DBTX
+	annotationRepo *AnnotationRepository
+	authorRepo     *AuthorRepository
+	categoryRepo   *CategoryRepository
+	tagRepo        *TagRepository
 }
 
-func NewQuoteRepository() *QuoteRepository {
-	return &QuoteRepository{db: database.DB}
+func NewQuoteRepository(store *database.Store) *QuoteRepository {
+	return &QuoteRepository{
+		db:             store.DB(),
+		annotationRepo: NewAnnotationRepository(store),
+		authorRepo:     NewAuthorRepository(store),
+		categoryRepo:   NewCategoryRepository(store),
+		tagRepo:        NewTagRepository(store),
+	}
+}
+
+// WithTx retorna uma cópia do repositório que executa as queries na transação tx
+func (r *QuoteRepository) WithTx(tx *sql.Tx) *QuoteRepository {
+	return &QuoteRepository{
+		db:             tx,
+		annotationRepo: r.annotationRepo.WithTx(tx),
+		authorRepo:     r.authorRepo.WithTx(tx),
+		categoryRepo:   r.categoryRepo.WithTx(tx),
+		tagRepo:        r.tagRepo.WithTx(tx),
+	}
 }
 
+// quoteColumns são as colunas de citação e livro lidas por scanQuote
+const quoteColumns = `
+            q.id, q.book_id, q.text, q.kind, q.page, q.location_start, q.location_end,
+            q.favorite, q.rating, q.created_at, q.updated_at,
+            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at`
+
 func (r *QuoteRepository) FindAll(limit, offset int) ([]models.Quote, error) {
 	query := `
-        SELECT 
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
+        SELECT ` + quoteColumns + `
         FROM quote q
         INNER JOIN book b ON q.book_id = b.id
         ORDER BY q.created_at DESC
@@ -37,47 +62,39 @@ func (r *QuoteRepository) FindAll(limit, offset int) ([]models.Quote, error) {
 
 func (r *QuoteRepository) FindByID(id int64) (*models.Quote, error) {
 	query := `
-        SELECT 
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
+        SELECT ` + quoteColumns + `
         FROM quote q
         INNER JOIN book b ON q.book_id = b.id
         WHERE q.id = ?
+        LIMIT 1
     `
 
-	var quote models.Quote
-	var book models.Book
+	return r.queryQuote(query, id)
+}
 
-	err := r.db.QueryRow(query, id).Scan(
-		&quote.ID, &quote.BookID, &quote.Text, &quote.CreatedAt, &quote.UpdatedAt,
-		&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-		&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-	)
+func (r *QuoteRepository) FindByBookID(bookID int64, limit, offset int) ([]models.Quote, error) {
+	return r.FindByQuery(QuoteQuery{BookIDs: []int64{bookID}, Limit: limit, Offset: offset})
+}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
+func (r *QuoteRepository) FindByAuthorID(authorID int64, limit, offset int) ([]models.Quote, error) {
+	return r.FindByQuery(QuoteQuery{AuthorIDs: []int64{authorID}, Limit: limit, Offset: offset})
+}
 
-	quote.Book = &book
-	return &quote, nil
+func (r *QuoteRepository) FindByCategoryID(categoryID int, limit, offset int) ([]models.Quote, error) {
+	return r.FindByQuery(QuoteQuery{CategoryIDs: []int{categoryID}, Limit: limit, Offset: offset})
 }
 
-func (r *QuoteRepository) FindByBookID(bookID int64, limit, offset int) ([]models.Quote, error) {
+// FindAllByBookID lista todas as citações de um livro em ordem de leitura
+func (r *QuoteRepository) FindAllByBookID(bookID int64) ([]models.Quote, error) {
 	query := `
-        SELECT 
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
+        SELECT ` + quoteColumns + `
         FROM quote q
         INNER JOIN book b ON q.book_id = b.id
         WHERE q.book_id = ?
-        ORDER BY q.created_at DESC
-        LIMIT ? OFFSET ?
+        ORDER BY q.location_start IS NULL, q.location_start ASC, q.id ASC
     `
 
-	rows, err := r.db.Query(query, bookID, limit, offset)
+	rows, err := r.db.Query(query, bookID)
 	if err != nil {
 		return nil, err
 	}
@@ -86,42 +103,31 @@ func (r *QuoteRepository) FindByBookID(bookID int64, limit, offset int) ([]model
 	return r.scanQuotes(rows)
 }
 
-func (r *QuoteRepository) FindByAuthorID(authorID int64, limit, offset int) ([]models.Quote, error) {
+// FindOverlapping busca citações do mesmo livro e tipo que podem repetir quote:
+// as de intervalo sobreposto ou, se quote não tiver posição, as sem posição
+func (r *QuoteRepository) FindOverlapping(quote models.Quote) ([]models.Quote, error) {
 	query := `
-        SELECT DISTINCT
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
+        SELECT ` + quoteColumns + `
         FROM quote q
         INNER JOIN book b ON q.book_id = b.id
-        INNER JOIN book_author ba ON b.id = ba.book_id
-        WHERE ba.author_id = ?
-        ORDER BY q.created_at DESC
-        LIMIT ? OFFSET ?
+        WHERE q.book_id = ? AND q.kind = ?
     `
+	args := []interface{}{quote.BookID, quote.Kind}
 
-	rows, err := r.db.Query(query, authorID, limit, offset)
-	if err != nil {
-		return nil, err
+	if quote.LocationStart != nil {
+		end := *quote.LocationStart
+		if quote.LocationEnd != nil {
+			end = *quote.LocationEnd
+		}
+		query += " AND q.location_start <= ? AND COALESCE(q.location_end, q.location_start) >= ?"
+		args = append(args, end, *quote.LocationStart)
+	} else {
+		query += " AND q.location_start IS NULL"
 	}
-	defer rows.Close()
 
-	return r.scanQuotes(rows)
-}
+	query += " ORDER BY q.created_at DESC, q.id DESC"
 
-func (r *QuoteRepository) FindByCategoryID(categoryID int, limit, offset int) ([]models.Quote, error) {
-	query := `
-        SELECT DISTINCT
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
-        FROM quote q
-        INNER JOIN book b ON q.book_id = b.id
-        INNER JOIN book_category bc ON b.id = bc.book_id
-        WHERE bc.category_id = ?
-        ORDER BY q.created_at DESC
-        LIMIT ? OFFSET ?
-    `
-
-	rows, err := r.db.Query(query, categoryID, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -130,77 +136,40 @@ func (r *QuoteRepository) FindByCategoryID(categoryID int, limit, offset int) ([
 	return r.scanQuotes(rows)
 }
 
-func (r *QuoteRepository) FindByFilters(bookID *int64, authorID *int64, categoryID *int, limit, offset int) ([]models.Quote, error) {
-	query := `
-        SELECT DISTINCT
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
-        FROM quote q
-        INNER JOIN book b ON q.book_id = b.id
-    `
-
-	var conditions []string
-	var args []interface{}
-
-	if authorID != nil {
-		query += " INNER JOIN book_author ba ON b.id = ba.book_id"
-		conditions = append(conditions, "ba.author_id = ?")
-		args = append(args, *authorID)
-	}
-
-	if categoryID != nil {
-		query += " INNER JOIN book_category bc ON b.id = bc.book_id"
-		conditions = append(conditions, "bc.category_id = ?")
-		args = append(args, *categoryID)
-	}
-
-	if bookID != nil {
-		conditions = append(conditions, "q.book_id = ?")
-		args = append(args, *bookID)
-	}
-
-	if len(conditions) > 0 {
-		query += " WHERE "
-		for i, cond := range conditions {
-			if i > 0 {
-				query += " AND "
-			}
-			query += cond
-		}
-	}
-
-	query += " ORDER BY q.created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
-
-	rows, err := r.db.Query(query, args...)
+// FindBookIDs lista os livros que têm ao menos uma citação
+func (r *QuoteRepository) FindBookIDs() ([]int64, error) {
+	rows, err := r.db.Query("SELECT DISTINCT book_id FROM quote ORDER BY book_id ASC")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return r.scanQuotes(rows)
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
 }
 
-func (r *QuoteRepository) FindRandom() (*models.Quote, error) {
+// FindHighlightAt busca o destaque do livro cujo intervalo de posições contém
+// location. Havendo mais de um, prefere o intervalo mais curto e o mais recente
+func (r *QuoteRepository) FindHighlightAt(bookID int64, location int) (*models.Quote, error) {
 	query := `
-        SELECT 
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
-        FROM quote q
-        INNER JOIN book b ON q.book_id = b.id
-        ORDER BY RANDOM()
+        SELECT id
+        FROM quote
+        WHERE book_id = ? AND kind = ?
+          AND location_start <= ? AND location_end >= ?
+        ORDER BY (location_end - location_start) ASC, created_at DESC, id DESC
         LIMIT 1
     `
 
-	var quote models.Quote
-	var book models.Book
-
-	err := r.db.QueryRow(query).Scan(
-		&quote.ID, &quote.BookID, &quote.Text, &quote.CreatedAt, &quote.UpdatedAt,
-		&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-		&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-	)
-
+	var id int64
+	err := r.db.QueryRow(query, bookID, models.QuoteKindHighlight, location, location).Scan(&id)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -208,18 +177,28 @@ func (r *QuoteRepository) FindRandom() (*models.Quote, error) {
 		return nil, err
 	}
 
-	quote.Book = &book
-	return &quote, nil
+	return r.FindByID(id)
 }
 
 func (r *QuoteRepository) Create(quote models.Quote) (*models.Quote, error) {
 	query := `
-        INSERT INTO quote (book_id, text, created_at, updated_at)
-        VALUES (?, ?, ?, ?)
+        INSERT INTO quote (book_id, text, kind, page, location_start, location_end, favorite, rating, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 
 	now := time.Now()
-	result, err := r.db.Exec(query, quote.BookID, quote.Text, now, now)
+	createdAt := quote.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	kind := quote.Kind
+	if kind == "" {
+		kind = models.QuoteKindHighlight
+	}
+
+	result, err := r.db.Exec(query, quote.BookID, quote.Text, kind, quote.Page,
+		quote.LocationStart, quote.LocationEnd, quote.Favorite, quote.Rating, createdAt, now)
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +232,77 @@ func (r *QuoteRepository) Update(id int64, quote models.Quote) (*models.Quote, e
 	return r.FindByID(id)
 }
 
+// Supersede substitui texto, posição e data de uma citação pelos de uma versão
+// mais recente do mesmo destaque
+func (r *QuoteRepository) Supersede(id int64, quote models.Quote) (*models.Quote, error) {
+	query := `
+        UPDATE quote
+        SET text = ?, page = ?, location_start = ?, location_end = ?, created_at = ?, updated_at = ?
+        WHERE id = ?
+    `
+
+	now := time.Now()
+	createdAt := quote.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	result, err := r.db.Exec(query, quote.Text, quote.Page, quote.LocationStart,
+		quote.LocationEnd, createdAt, now, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return r.FindByID(id)
+}
+
+// SetFavorite marca ou desmarca a citação como favorita
+func (r *QuoteRepository) SetFavorite(id int64, favorite bool) error {
+	return r.setColumn(id, "favorite", favorite)
+}
+
+// SetRating grava a avaliação da citação; nil remove a avaliação
+func (r *QuoteRepository) SetRating(id int64, rating *int) error {
+	return r.setColumn(id, "rating", rating)
+}
+
+// MergeMarks leva para toID a marcação de favorita e a maior avaliação de
+// fromID, antes que fromID seja removida como repetida
+func (r *QuoteRepository) MergeMarks(fromID, toID int64) error {
+	query := `
+        UPDATE quote
+        SET favorite = MAX(favorite, (SELECT favorite FROM quote WHERE id = ?)),
+            rating = NULLIF(MAX(COALESCE(rating, 0), COALESCE((SELECT rating FROM quote WHERE id = ?), 0)), 0)
+        WHERE id = ?
+    `
+
+	_, err := r.db.Exec(query, fromID, fromID, toID)
+	return err
+}
+
+// setColumn atualiza uma coluna da citação e o seu updated_at. column nunca
+// vem de entrada do usuário
+func (r *QuoteRepository) setColumn(id int64, column string, value interface{}) error {
+	query := "UPDATE quote SET " + column + " = ?, updated_at = ? WHERE id = ?"
+
+	result, err := r.db.Exec(query, value, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 // Delete remove uma citação
 func (r *QuoteRepository) Delete(id int64) error {
 	query := "DELETE FROM quote WHERE id = ?"
@@ -277,82 +327,25 @@ func (r *QuoteRepository) Count() (int, error) {
 }
 
 func (r *QuoteRepository) CountByBookID(bookID int64) (int, error) {
-	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM quote WHERE book_id = ?", bookID).Scan(&count)
-	return count, err
+	return r.CountByQuery(QuoteQuery{BookIDs: []int64{bookID}})
 }
 
 func (r *QuoteRepository) CountByAuthorID(authorID int64) (int, error) {
-	query := `
-        SELECT COUNT(DISTINCT q.id)
-        FROM quote q
-        INNER JOIN book b ON q.book_id = b.id
-        INNER JOIN book_author ba ON b.id = ba.book_id
-        WHERE ba.author_id = ?
-    `
-
-	var count int
-	err := r.db.QueryRow(query, authorID).Scan(&count)
-	return count, err
+	return r.CountByQuery(QuoteQuery{AuthorIDs: []int64{authorID}})
 }
 
 func (r *QuoteRepository) CountByCategoryID(categoryID int) (int, error) {
-	query := `
-        SELECT COUNT(DISTINCT q.id)
-        FROM quote q
-        INNER JOIN book b ON q.book_id = b.id
-        INNER JOIN book_category bc ON b.id = bc.book_id
-        WHERE bc.category_id = ?
-    `
-
-	var count int
-	err := r.db.QueryRow(query, categoryID).Scan(&count)
-	return count, err
+	return r.CountByQuery(QuoteQuery{CategoryIDs: []int{categoryID}})
 }
 
+// Search busca no texto das citações; veja SearchFullText
 func (r *QuoteRepository) Search(searchTerm string, limit, offset int) ([]models.Quote, error) {
-	query := `
-        SELECT 
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
-        FROM quote q
-        INNER JOIN book b ON q.book_id = b.id
-        WHERE q.text LIKE ?
-        ORDER BY q.created_at DESC
-        LIMIT ? OFFSET ?
-    `
-
-	rows, err := r.db.Query(query, "%"+searchTerm+"%", limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	return r.scanQuotes(rows)
+	return r.searchQuotes(searchTerm, SearchScopeText, limit, offset)
 }
 
+// SearchInBookAndAuthor busca no texto, no título do livro e no nome dos autores
 func (r *QuoteRepository) SearchInBookAndAuthor(searchTerm string, limit, offset int) ([]models.Quote, error) {
-	query := `
-        SELECT DISTINCT
-            q.id, q.book_id, q.text, q.created_at, q.updated_at,
-            b.id, b.title, b.isbn, b.published_year, b.publisher, b.pages, b.created_at, b.updated_at
-        FROM quote q
-        INNER JOIN book b ON q.book_id = b.id
-        LEFT JOIN book_author ba ON b.id = ba.book_id
-        LEFT JOIN author a ON ba.author_id = a.id
-        WHERE q.text LIKE ? OR b.title LIKE ? OR a.name LIKE ?
-        ORDER BY q.created_at DESC
-        LIMIT ? OFFSET ?
-    `
-
-	searchPattern := "%" + searchTerm + "%"
-	rows, err := r.db.Query(query, searchPattern, searchPattern, searchPattern, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	return r.scanQuotes(rows)
+	return r.searchQuotes(searchTerm, SearchScopeAll, limit, offset)
 }
 
 func (r *QuoteRepository) Exists(id int64) (bool, error) {
@@ -369,23 +362,31 @@ func (r *QuoteRepository) BookExists(bookID int64) (bool, error) {
 	return exists, err
 }
 
+// queryQuote executa uma query sobre quoteColumns e retorna a primeira
+// citação, ou nil se não houver nenhuma
+func (r *QuoteRepository) queryQuote(query string, args ...interface{}) (*models.Quote, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes, err := r.scanQuotes(rows)
+	if err != nil || len(quotes) == 0 {
+		return nil, err
+	}
+
+	return &quotes[0], nil
+}
+
 func (r *QuoteRepository) scanQuotes(rows *sql.Rows) ([]models.Quote, error) {
 	var quotes []models.Quote
 
 	for rows.Next() {
-		var quote models.Quote
-		var book models.Book
-
-		err := rows.Scan(
-			&quote.ID, &quote.BookID, &quote.Text, &quote.CreatedAt, &quote.UpdatedAt,
-			&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
-			&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
-		)
+		quote, err := scanQuote(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		quote.Book = &book
 		quotes = append(quotes, quote)
 	}
 
@@ -393,5 +394,77 @@ func (r *QuoteRepository) scanQuotes(rows *sql.Rows) ([]models.Quote, error) {
 		return nil, err
 	}
 
+	if err := r.attachRelations(quotes); err != nil {
+		return nil, err
+	}
+
 	return quotes, nil
 }
+
+// scanQuote lê as colunas de citação e livro usadas nos SELECTs deste
+// repositório; extra recebe colunas adicionais selecionadas depois delas
+func scanQuote(rows *sql.Rows, extra ...interface{}) (models.Quote, error) {
+	var quote models.Quote
+	var book models.Book
+
+	dest := []interface{}{
+		&quote.ID, &quote.BookID, &quote.Text, &quote.Kind, &quote.Page,
+		&quote.LocationStart, &quote.LocationEnd, &quote.Favorite, &quote.Rating,
+		&quote.CreatedAt, &quote.UpdatedAt,
+		&book.ID, &book.Title, &book.ISBN, &book.PublishedYear,
+		&book.Publisher, &book.Pages, &book.CreatedAt, &book.UpdatedAt,
+	}
+
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return quote, err
+	}
+
+	quote.Book = &book
+	return quote, nil
+}
+
+// attachRelations carrega as anotações e tags das citações e os autores e categorias
+// dos seus livros para a página inteira, com uma query para cada
+func (r *QuoteRepository) attachRelations(quotes []models.Quote) error {
+	ids := make([]int64, len(quotes))
+	var bookIDs []int64
+	seen := make(map[int64]bool)
+	for i, quote := range quotes {
+		ids[i] = quote.ID
+		if !seen[quote.BookID] {
+			seen[quote.BookID] = true
+			bookIDs = append(bookIDs, quote.BookID)
+		}
+	}
+
+	annotations, err := r.annotationRepo.FindByQuoteIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	tags, err := r.tagRepo.FindByQuoteIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	authors, err := r.authorRepo.FindByBookIDs(bookIDs)
+	if err != nil {
+		return err
+	}
+
+	categories, err := r.categoryRepo.FindByBookIDs(bookIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range quotes {
+		quotes[i].Annotations = annotations[quotes[i].ID]
+		quotes[i].Tags = tags[quotes[i].ID]
+		if book := quotes[i].Book; book != nil {
+			book.Authors = authors[book.ID]
+			book.Categories = categories[book.ID]
+		}
+	}
+
+	return nil
+}
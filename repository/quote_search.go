@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"html"
+	"quote-api/models"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SearchScope define em quais campos a busca textual procura
+type SearchScope string
+
+const (
+	SearchScopeText SearchScope = "text"
+	SearchScopeAll  SearchScope = "all"
+)
+
+const (
+	snippetOpen  = "<mark>"
+	snippetClose = "</mark>"
+	snippetWords = 16
+	// O snippet do FTS5 marca os termos com caracteres de controle, que não
+	// aparecem em citações; markSnippet escapa o texto e só então os troca
+	// por snippetOpen e snippetClose
+	ftsOpen  = "\x02"
+	ftsClose = "\x03"
+)
+
+// Pesos do bm25 para as colunas text, title e authors de quote_fts
+const searchRank = "bm25(quote_fts, 10.0, 3.0, 2.0)"
+
+// SearchFullText busca citações pelo índice quote_fts, ordenadas por relevância.
+// term aceita palavras soltas (todas precisam aparecer), frases entre aspas e
+// prefixos terminados em *, como em `"mind killer" fea*`. Sem o índice (build
+// sem FTS5), cai para LIKE, sem ranking
+func (r *QuoteRepository) SearchFullText(term string, scope SearchScope, limit, offset int) ([]models.QuoteSearchResult, int, error) {
+	terms := ParseSearchTerms(term)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	indexed, err := r.hasSearchIndex()
+	if err != nil {
+		return nil, 0, err
+	}
+	if !indexed {
+		return r.searchLike(term, terms, scope, limit, offset)
+	}
+
+	match := ftsQuery(terms, scope)
+
+	var total int
+	err = r.db.QueryRow("SELECT COUNT(*) FROM quote_fts WHERE quote_fts MATCH ?", match).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT ` + quoteColumns + `,
+            snippet(quote_fts, 0, ?, ?, '…', ?), ` + searchRank + `
+        FROM quote_fts
+        INNER JOIN quote q ON q.id = quote_fts.rowid
+        INNER JOIN book b ON q.book_id = b.id
+        WHERE quote_fts MATCH ?
+        ORDER BY ` + searchRank + `, q.id
+        LIMIT ? OFFSET ?
+    `
+
+	rows, err := r.db.Query(query, ftsOpen, ftsClose, snippetWords, match, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var results []models.QuoteSearchResult
+	for rows.Next() {
+		var result models.QuoteSearchResult
+		var rank float64
+
+		result.Quote, err = scanQuote(rows, &result.Snippet, &rank)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		result.Snippet = markSnippet(result.Snippet)
+		// bm25 é negativo e menor para os melhores resultados
+		result.Score = -rank
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachSearchRelations(results); err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+func (r *QuoteRepository) hasSearchIndex() (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'quote_fts')"
+	err := r.db.QueryRow(query).Scan(&exists)
+	return exists, err
+}
+
+// searchQuotes adapta SearchFullText para as buscas que só retornam citações
+func (r *QuoteRepository) searchQuotes(term string, scope SearchScope, limit, offset int) ([]models.Quote, error) {
+	results, _, err := r.SearchFullText(term, scope, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]models.Quote, len(results))
+	for i, result := range results {
+		quotes[i] = result.Quote
+	}
+	return quotes, nil
+}
+
+// searchLike é a busca usada quando o índice FTS5 não existe
+func (r *QuoteRepository) searchLike(term string, terms []SearchTerm, scope SearchScope, limit, offset int) ([]models.QuoteSearchResult, int, error) {
+	query := QuoteQuery{
+		Text:      term,
+		TextScope: scope,
+		Order:     QuoteOrderCreatedAt,
+		Limit:     limit,
+		Offset:    offset,
+	}
+
+	total, err := r.CountByQuery(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	quotes, err := r.FindByQuery(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]models.QuoteSearchResult, len(quotes))
+	for i, quote := range quotes {
+		results[i] = models.QuoteSearchResult{Quote: quote, Snippet: HighlightTerms(quote.Text, terms)}
+	}
+
+	return results, total, nil
+}
+
+func (r *QuoteRepository) attachSearchRelations(results []models.QuoteSearchResult) error {
+	quotes := make([]models.Quote, len(results))
+	for i, result := range results {
+		quotes[i] = result.Quote
+	}
+
+	if err := r.attachRelations(quotes); err != nil {
+		return err
+	}
+
+	for i := range results {
+		results[i].Quote = quotes[i]
+	}
+	return nil
+}
+
+// SearchTerm é uma palavra ou frase da busca; Prefix indica um termo
+// terminado em *, que casa com qualquer palavra iniciada por Text
+type SearchTerm struct {
+	Text   string
+	Prefix bool
+}
+
+var searchTermRegex = regexp.MustCompile(`"([^"]*)"(\*?)|(\S+)`)
+
+// ParseSearchTerms separa frases entre aspas e palavras soltas. Termos sem
+// letras nem dígitos são descartados, pois não casam com nenhum token
+func ParseSearchTerms(input string) []SearchTerm {
+	var terms []SearchTerm
+
+	for _, match := range searchTermRegex.FindAllStringSubmatch(input, -1) {
+		term := SearchTerm{Text: match[1], Prefix: match[2] == "*"}
+		if match[3] != "" {
+			term.Text = strings.TrimSuffix(match[3], "*")
+			term.Prefix = strings.HasSuffix(match[3], "*")
+		}
+
+		term.Text = strings.Join(strings.Fields(strings.ReplaceAll(term.Text, `"`, " ")), " ")
+		if strings.IndexFunc(term.Text, isWordRune) >= 0 {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// ftsQuery monta a expressão MATCH do FTS5 com cada termo entre aspas, o que
+// evita que operadores digitados pelo usuário quebrem a sintaxe
+func ftsQuery(terms []SearchTerm, scope SearchScope) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = `"` + term.Text + `"`
+		if term.Prefix {
+			parts[i] += "*"
+		}
+	}
+
+	query := strings.Join(parts, " ")
+	if scope == SearchScopeText {
+		query = "text : (" + query + ")"
+	}
+	return query
+}
+
+// HighlightTerms marca os termos no texto, imitando o snippet do FTS5. O texto
+// é escapado como HTML; só as marcações ficam sem escape
+func HighlightTerms(text string, terms []SearchTerm) string {
+	if len(terms) == 0 {
+		return html.EscapeString(text)
+	}
+
+	patterns := make([]string, len(terms))
+	for i, term := range terms {
+		patterns[i] = regexp.QuoteMeta(term.Text)
+		if term.Prefix {
+			patterns[i] += `[\pL\pN]*`
+		}
+	}
+
+	re, err := regexp.Compile(`(?i)` + strings.Join(patterns, "|"))
+	if err != nil {
+		return html.EscapeString(text)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:match[0]]))
+		b.WriteString(snippetOpen + html.EscapeString(text[match[0]:match[1]]) + snippetClose)
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// markSnippet escapa o snippet do FTS5 como HTML e troca os marcadores dos
+// termos por snippetOpen e snippetClose
+func markSnippet(snippet string) string {
+	return strings.NewReplacer(ftsOpen, snippetOpen, ftsClose, snippetClose).Replace(html.EscapeString(snippet))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"quote-api/models"
+	"strings"
+	"testing"
+)
+
+func TestHighlightTerms(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms string
+		want  string
+	}{
+		{"Mind killer", "mind", "<mark>Mind</mark> killer"},
+		{"crafts and craft", "craf*", "<mark>crafts</mark> and <mark>craft</mark>"},
+		{`<img src=x onerror="alert(1)"> fear`, "fear", `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>fear</mark>`},
+		{"A & B <b>", "b", "A &amp; <mark>B</mark> &lt;<mark>b</mark>&gt;"},
+		{"A & B", "&", "A &amp; B"},
+		{"<script>", "script", "&lt;<mark>script</mark>&gt;"},
+	}
+
+	for _, tt := range tests {
+		if got := HighlightTerms(tt.text, ParseSearchTerms(tt.terms)); got != tt.want {
+			t.Errorf("HighlightTerms(%q, %q) = %q, quer %q", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
+
+// TestSearchFullText_Escaping roda com e sem a tag sqlite_fts5: o snippet vem
+// do FTS5 ou de HighlightTerms, e em ambos o texto precisa chegar escapado
+func TestSearchFullText_Escaping(t *testing.T) {
+	store := newTestStore(t)
+	bookRepo := NewBookRepository(store)
+	quoteRepo := NewQuoteRepository(store)
+
+	book, err := bookRepo.Create(models.Book{Title: "Livro"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := `Medo <script>alert("x")</script> mata a mente`
+	if _, err := quoteRepo.Create(models.Quote{BookID: book.ID, Text: text}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, _, err := quoteRepo.SearchFullText("mata", SearchScopeText, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d resultados, quer 1", len(results))
+	}
+	snippet := results[0].Snippet
+	if strings.Contains(snippet, "<script>") || !strings.Contains(snippet, "&lt;script&gt;") {
+		t.Errorf("snippet sem escape: %q", snippet)
+	}
+	if !strings.Contains(snippet, "<mark>mata</mark>") {
+		t.Errorf("snippet sem o termo marcado: %q", snippet)
+	}
+}
+
+func TestFindByQuery_LikeWildcards(t *testing.T) {
+	store := newTestStore(t)
+	bookRepo := NewBookRepository(store)
+	quoteRepo := NewQuoteRepository(store)
+
+	book, err := bookRepo.Create(models.Book{Title: "Livro"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"Desconto de 100% hoje", "Desconto de 1000 hoje", "arquivo_final", "arquivos finais"} {
+		if _, err := quoteRepo.Create(models.Quote{BookID: book.ID, Text: text}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Sem o índice FTS5 o filtro é o LIKE; com ele, os termos viram tokens e
+	// o resultado precisa ser o mesmo
+	tests := map[string]string{
+		"100%":          "Desconto de 100% hoje",
+		"arquivo_final": "arquivo_final",
+	}
+	for term, want := range tests {
+		quotes, err := quoteRepo.FindByQuery(QuoteQuery{Text: term, Order: QuoteOrderCreatedAt, Limit: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(quotes) != 1 || quotes[0].Text != want {
+			var texts []string
+			for _, quote := range quotes {
+				texts = append(texts, quote.Text)
+			}
+			t.Errorf("%q encontrou %q, quer só %q", term, texts, want)
+		}
+	}
+}
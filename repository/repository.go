@@ -0,0 +1,108 @@
+package repository
+
+import "quote-api/models"
+
+// Authors, Books, Categories, Quotes e Tags descrevem o que os services usam de
+// cada repositório. As implementações SQLite deste pacote e as em memória de
+// repository/memory seguem as mesmas regras de ordenação e paginação
+
+type Authors interface {
+	FindAll(limit, offset int) ([]models.Author, error)
+	FindByID(id int64) (*models.Author, error)
+	FindByName(name string) (*models.Author, error)
+	FindByBookID(bookID int64) ([]models.Author, error)
+	Create(author models.Author) (*models.Author, error)
+	Update(id int64, author models.Author) (*models.Author, error)
+	Delete(id int64) error
+	Count() (int, error)
+	Search(searchTerm string, limit, offset int) ([]models.Author, error)
+}
+
+type Books interface {
+	FindAll(limit, offset int) ([]models.Book, error)
+	FindByID(id int64) (*models.Book, error)
+	FindByISBN(isbn string) (*models.Book, error)
+	FindByTitle(title string) (*models.Book, error)
+	FindByAuthorID(authorID int64, limit, offset int) ([]models.Book, error)
+	FindByCategoryID(categoryID int, limit, offset int) ([]models.Book, error)
+	FindPageByQuery(query BookQuery) (Page[models.Book], error)
+	Create(book models.Book, authorIDs []int64, categoryIDs []int) (*models.Book, error)
+	Update(id int64, book models.Book) (*models.Book, error)
+	UpdateAuthors(bookID int64, authorIDs []int64) error
+	UpdateCategories(bookID int64, categoryIDs []int) error
+	Delete(id int64) error
+	Count() (int, error)
+	CountByQuery(query BookQuery) (int, error)
+	Search(searchTerm string, limit, offset int) ([]models.Book, error)
+}
+
+type Categories interface {
+	FindAll(limit, offset int) ([]models.Category, error)
+	FindByID(id int) (*models.Category, error)
+	FindByName(name string) (*models.Category, error)
+	FindByBookID(bookID int64) ([]models.Category, error)
+	Create(category models.Category) (*models.Category, error)
+	Update(id int, category models.Category) (*models.Category, error)
+	Delete(id int) error
+	Count() (int, error)
+	Search(searchTerm string, limit, offset int) ([]models.Category, error)
+}
+
+type Quotes interface {
+	FindAll(limit, offset int) ([]models.Quote, error)
+	FindByID(id int64) (*models.Quote, error)
+	FindByBookID(bookID int64, limit, offset int) ([]models.Quote, error)
+	FindByAuthorID(authorID int64, limit, offset int) ([]models.Quote, error)
+	FindByCategoryID(categoryID int, limit, offset int) ([]models.Quote, error)
+	FindByQuery(query QuoteQuery) ([]models.Quote, error)
+	FindPageByQuery(query QuoteQuery) (Page[models.Quote], error)
+	FindRandom(weighting RandomWeighting) (*models.Quote, error)
+	FindRandomByQuery(query QuoteQuery, n int, weighting RandomWeighting) ([]models.Quote, error)
+	Create(quote models.Quote) (*models.Quote, error)
+	Update(id int64, quote models.Quote) (*models.Quote, error)
+	SetFavorite(id int64, favorite bool) error
+	SetRating(id int64, rating *int) error
+	Delete(id int64) error
+	Count() (int, error)
+	CountByBookID(bookID int64) (int, error)
+	CountByAuthorID(authorID int64) (int, error)
+	CountByCategoryID(categoryID int) (int, error)
+	CountByQuery(query QuoteQuery) (int, error)
+	Search(searchTerm string, limit, offset int) ([]models.Quote, error)
+	SearchInBookAndAuthor(searchTerm string, limit, offset int) ([]models.Quote, error)
+	SearchFullText(term string, scope SearchScope, limit, offset int) ([]models.QuoteSearchResult, int, error)
+	Exists(id int64) (bool, error)
+	BookExists(bookID int64) (bool, error)
+}
+
+type Tags interface {
+	FindAll(limit, offset int) ([]models.Tag, error)
+	FindByID(id int64) (*models.Tag, error)
+	FindByName(name string) (*models.Tag, error)
+	FindByQuoteID(quoteID int64) ([]models.Tag, error)
+	Create(tag models.Tag) (*models.Tag, error)
+	Update(id int64, tag models.Tag) (*models.Tag, error)
+	Delete(id int64) error
+	Count() (int, error)
+	CountSearch(searchTerm string) (int, error)
+	Search(searchTerm string, limit, offset int) ([]models.Tag, error)
+	AddToQuote(quoteID, tagID int64) error
+	RemoveFromQuote(quoteID, tagID int64) error
+}
+
+type DailyQuotes interface {
+	FindByDay(scope, day string) (*models.DailyQuote, error)
+	FindHistory(scope string, limit, offset int) ([]models.DailyQuote, error)
+	CountHistory(scope string) (int, error)
+	FindQuoteIDsSince(scope, since string) ([]int64, error)
+	Create(entry models.DailyQuote) (*models.DailyQuote, error)
+}
+
+var (
+	_ Authors     = (*AuthorRepository)(nil)
+	_ Books       = (*BookRepository)(nil)
+	_ Categories  = (*CategoryRepository)(nil)
+	_ Quotes      = (*QuoteRepository)(nil)
+	_ Tags        = (*TagRepository)(nil)
+	_ DailyQuotes = (*DailyQuoteRepository)(nil)
+)
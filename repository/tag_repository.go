@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"database/sql"
+	"quote-api/database"
+	"quote-api/models"
+	"time"
+)
+
+type TagRepository struct {
+	db DBTX
+}
+
+func NewTagRepository(store *database.Store) *TagRepository {
+	return &TagRepository{db: store.DB()}
+}
+
+// WithTx retorna uma cópia do repositório que executa as queries na transação tx
+func (r *TagRepository) WithTx(tx *sql.Tx) *TagRepository {
+	return &TagRepository{db: tx}
+}
+
+// tagColumns inclui a quantidade de citações de cada tag
+const tagColumns = `
+            t.id, t.name, t.created_at, t.updated_at,
+            (SELECT COUNT(*) FROM quote_tag qt WHERE qt.tag_id = t.id)`
+
+// FindAll lista as tags por nome, com a quantidade de citações de cada uma
+func (r *TagRepository) FindAll(limit, offset int) ([]models.Tag, error) {
+	query := `
+        SELECT` + tagColumns + `
+        FROM tag t
+        ORDER BY t.name ASC, t.id ASC
+        LIMIT ? OFFSET ?
+    `
+
+	return r.queryTags(query, limit, offset)
+}
+
+// FindByID busca tag por ID
+func (r *TagRepository) FindByID(id int64) (*models.Tag, error) {
+	query := `
+        SELECT` + tagColumns + `
+        FROM tag t
+        WHERE t.id = ?
+    `
+
+	return r.queryTag(query, id)
+}
+
+// FindByName busca tag pelo nome, ignorando maiúsculas/minúsculas
+// (models.TagKey)
+func (r *TagRepository) FindByName(name string) (*models.Tag, error) {
+	query := `
+        SELECT` + tagColumns + `
+        FROM tag t
+        WHERE t.name_key = ?
+    `
+
+	return r.queryTag(query, models.TagKey(name))
+}
+
+// FindByQuoteID lista as tags de uma citação
+func (r *TagRepository) FindByQuoteID(quoteID int64) ([]models.Tag, error) {
+	tags, err := r.FindByQuoteIDs([]int64{quoteID})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags[quoteID], nil
+}
+
+// FindByQuoteIDs carrega as tags de várias citações com uma query a cada
+// maxInList citações, ordenadas por nome
+func (r *TagRepository) FindByQuoteIDs(quoteIDs []int64) (map[int64][]models.Tag, error) {
+	result := make(map[int64][]models.Tag)
+	if len(quoteIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+        SELECT qt.quote_id, t.id, t.name, t.created_at, t.updated_at
+        FROM tag t
+        INNER JOIN quote_tag qt ON t.id = qt.tag_id
+        WHERE qt.quote_id IN (%s)
+        ORDER BY qt.quote_id ASC, t.name ASC
+    `
+
+	err := queryInChunks(r.db, query, quoteIDs, func(rows *sql.Rows) error {
+		var quoteID int64
+		var tag models.Tag
+		if err := rows.Scan(&quoteID, &tag.ID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+			return err
+		}
+		result[quoteID] = append(result[quoteID], tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Create cria nova tag
+func (r *TagRepository) Create(tag models.Tag) (*models.Tag, error) {
+	query := `
+        INSERT INTO tag (name, name_key, created_at, updated_at)
+        VALUES (?, ?, ?, ?)
+    `
+
+	now := time.Now()
+	result, err := r.db.Exec(query, tag.Name, models.TagKey(tag.Name), now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(id)
+}
+
+// Update renomeia a tag
+func (r *TagRepository) Update(id int64, tag models.Tag) (*models.Tag, error) {
+	query := `
+        UPDATE tag
+        SET name = ?, name_key = ?, updated_at = ?
+        WHERE id = ?
+    `
+
+	result, err := r.db.Exec(query, tag.Name, models.TagKey(tag.Name), time.Now(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return r.FindByID(id)
+}
+
+// Delete remove a tag (CASCADE remove quote_tag)
+func (r *TagRepository) Delete(id int64) error {
+	result, err := r.db.Exec("DELETE FROM tag WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Count conta total de tags
+func (r *TagRepository) Count() (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM tag").Scan(&count)
+	return count, err
+}
+
+// CountSearch conta as tags que Search encontraria sem paginação
+func (r *TagRepository) CountSearch(searchTerm string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM tag WHERE name LIKE ?", "%"+searchTerm+"%").Scan(&count)
+	return count, err
+}
+
+// Search busca tags por nome parcial
+func (r *TagRepository) Search(searchTerm string, limit, offset int) ([]models.Tag, error) {
+	query := `
+        SELECT` + tagColumns + `
+        FROM tag t
+        WHERE t.name LIKE ?
+        ORDER BY t.name ASC, t.id ASC
+        LIMIT ? OFFSET ?
+    `
+
+	return r.queryTags(query, "%"+searchTerm+"%", limit, offset)
+}
+
+// AddToQuote aplica a tag à citação; aplicar de novo não tem efeito
+func (r *TagRepository) AddToQuote(quoteID, tagID int64) error {
+	_, err := r.db.Exec(
+		"INSERT OR IGNORE INTO quote_tag (quote_id, tag_id, created_at) VALUES (?, ?, ?)",
+		quoteID, tagID, time.Now(),
+	)
+	return err
+}
+
+// RemoveFromQuote tira a tag da citação. Retorna sql.ErrNoRows se a citação
+// não tinha a tag
+func (r *TagRepository) RemoveFromQuote(quoteID, tagID int64) error {
+	result, err := r.db.Exec("DELETE FROM quote_tag WHERE quote_id = ? AND tag_id = ?", quoteID, tagID)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MoveToQuote copia as tags de uma citação para outra; as da origem saem
+// junto com ela pelo CASCADE
+func (r *TagRepository) MoveToQuote(fromQuoteID, toQuoteID int64) error {
+	_, err := r.db.Exec(`
+        INSERT OR IGNORE INTO quote_tag (quote_id, tag_id, created_at)
+        SELECT ?, tag_id, created_at FROM quote_tag WHERE quote_id = ?
+    `, toQuoteID, fromQuoteID)
+	return err
+}
+
+func (r *TagRepository) queryTag(query string, args ...interface{}) (*models.Tag, error) {
+	tags, err := r.queryTags(query, args...)
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+
+	return &tags[0], nil
+}
+
+func (r *TagRepository) queryTags(query string, args ...interface{}) ([]models.Tag, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt, &tag.QuoteCount)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
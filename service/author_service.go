@@ -1,18 +1,18 @@
 package service
 
 import (
-	"errors"
+	"quote-api/apperrors"
 	"quote-api/models"
 	"quote-api/repository"
 	"strings"
 )
 
 type AuthorService struct {
-	repo     *repository.AuthorRepository
-	bookRepo *repository.BookRepository
+	repo     repository.Authors
+	bookRepo repository.Books
 }
 
-func NewAuthorService(repo *repository.AuthorRepository, bookRepo *repository.BookRepository) *AuthorService {
+func NewAuthorService(repo repository.Authors, bookRepo repository.Books) *AuthorService {
 	return &AuthorService{
 		repo:     repo,
 		bookRepo: bookRepo,
@@ -43,7 +43,7 @@ func (s *AuthorService) GetAll(limit, offset int) ([]models.Author, int, error)
 
 func (s *AuthorService) GetByID(id int64) (*models.Author, error) {
 	if id <= 0 {
-		return nil, errors.New("ID inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	author, err := s.repo.FindByID(id)
@@ -51,7 +51,7 @@ func (s *AuthorService) GetByID(id int64) (*models.Author, error) {
 		return nil, err
 	}
 	if author == nil {
-		return nil, errors.New("autor não encontrado")
+		return nil, apperrors.NotFound(apperrors.CodeAuthorNotFound)
 	}
 
 	return author, nil
@@ -70,7 +70,7 @@ func (s *AuthorService) Create(author models.Author) (*models.Author, error) {
 		return nil, err
 	}
 	if existing != nil {
-		return nil, errors.New("já existe um autor com este nome")
+		return nil, apperrors.Conflict(apperrors.CodeAuthorNameTaken)
 	}
 
 	created, err := s.repo.Create(author)
@@ -83,7 +83,7 @@ func (s *AuthorService) Create(author models.Author) (*models.Author, error) {
 
 func (s *AuthorService) Update(id int64, author models.Author) (*models.Author, error) {
 	if id <= 0 {
-		return nil, errors.New("ID inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	if err := s.validateAuthor(author); err != nil {
@@ -97,7 +97,7 @@ func (s *AuthorService) Update(id int64, author models.Author) (*models.Author,
 		return nil, err
 	}
 	if existing == nil {
-		return nil, errors.New("autor não encontrado")
+		return nil, apperrors.NotFound(apperrors.CodeAuthorNotFound)
 	}
 
 	duplicate, err := s.repo.FindByName(author.Name)
@@ -105,7 +105,7 @@ func (s *AuthorService) Update(id int64, author models.Author) (*models.Author,
 		return nil, err
 	}
 	if duplicate != nil && duplicate.ID != id {
-		return nil, errors.New("já existe outro autor com este nome")
+		return nil, apperrors.Conflict(apperrors.CodeAuthorNameTaken)
 	}
 
 	updated, err := s.repo.Update(id, author)
@@ -118,7 +118,7 @@ func (s *AuthorService) Update(id int64, author models.Author) (*models.Author,
 
 func (s *AuthorService) Delete(id int64) error {
 	if id <= 0 {
-		return errors.New("ID inválido")
+		return apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	author, err := s.repo.FindByID(id)
@@ -126,7 +126,7 @@ func (s *AuthorService) Delete(id int64) error {
 		return err
 	}
 	if author == nil {
-		return errors.New("autor não encontrado")
+		return apperrors.NotFound(apperrors.CodeAuthorNotFound)
 	}
 
 	books, err := s.bookRepo.FindByAuthorID(id, 1, 0)
@@ -134,7 +134,7 @@ func (s *AuthorService) Delete(id int64) error {
 		return err
 	}
 	if len(books) > 0 {
-		return errors.New("não é possível deletar autor com livros associados")
+		return apperrors.Conflict(apperrors.CodeAuthorHasBooks)
 	}
 
 	err = s.repo.Delete(id)
@@ -173,7 +173,7 @@ func (s *AuthorService) Search(searchTerm string, limit, offset int) ([]models.A
 
 func (s *AuthorService) GetBooksByAuthor(authorID int64, limit, offset int) ([]models.Book, error) {
 	if authorID <= 0 {
-		return nil, errors.New("ID de autor inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidAuthorID)
 	}
 
 	author, err := s.repo.FindByID(authorID)
@@ -181,7 +181,7 @@ func (s *AuthorService) GetBooksByAuthor(authorID int64, limit, offset int) ([]m
 		return nil, err
 	}
 	if author == nil {
-		return nil, errors.New("autor não encontrado")
+		return nil, apperrors.NotFound(apperrors.CodeAuthorNotFound)
 	}
 
 	if limit <= 0 || limit > 100 {
@@ -200,17 +200,16 @@ func (s *AuthorService) GetBooksByAuthor(authorID int64, limit, offset int) ([]m
 }
 
 func (s *AuthorService) validateAuthor(author models.Author) error {
-	if strings.TrimSpace(author.Name) == "" {
-		return errors.New("nome do autor é obrigatório")
-	}
-
-	if len(author.Name) < 2 {
-		return errors.New("nome do autor deve ter pelo menos 2 caracteres")
-	}
+	var fields apperrors.Fields
 
-	if len(author.Name) > 200 {
-		return errors.New("nome do autor deve ter no máximo 200 caracteres")
+	switch name := strings.TrimSpace(author.Name); {
+	case name == "":
+		fields.Add("name", apperrors.CodeRequired)
+	case len(author.Name) < 2:
+		fields.AddLimit("name", apperrors.CodeTooShort, 2)
+	case len(author.Name) > 200:
+		fields.AddLimit("name", apperrors.CodeTooLong, 200)
 	}
 
-	return nil
+	return fields.Err()
 }
@@ -1,22 +1,22 @@
 package service
 
 import (
-	"errors"
+	"quote-api/apperrors"
 	"quote-api/models"
 	"quote-api/repository"
 	"strings"
 )
 
 type BookService struct {
-	repo         *repository.BookRepository
-	authorRepo   *repository.AuthorRepository
-	categoryRepo *repository.CategoryRepository
+	repo         repository.Books
+	authorRepo   repository.Authors
+	categoryRepo repository.Categories
 }
 
 func NewBookService(
-	repo *repository.BookRepository,
-	authorRepo *repository.AuthorRepository,
-	categoryRepo *repository.CategoryRepository,
+	repo repository.Books,
+	authorRepo repository.Authors,
+	categoryRepo repository.Categories,
 ) *BookService {
 	return &BookService{
 		repo:         repo,
@@ -49,7 +49,7 @@ func (s *BookService) GetAll(limit, offset int) ([]models.Book, int, error) {
 
 func (s *BookService) GetByID(id int64) (*models.Book, error) {
 	if id <= 0 {
-		return nil, errors.New("ID inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	book, err := s.repo.FindByID(id)
@@ -57,7 +57,7 @@ func (s *BookService) GetByID(id int64) (*models.Book, error) {
 		return nil, err
 	}
 	if book == nil {
-		return nil, errors.New("livro não encontrado")
+		return nil, apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	return book, nil
@@ -66,7 +66,7 @@ func (s *BookService) GetByID(id int64) (*models.Book, error) {
 func (s *BookService) GetByISBN(isbn string) (*models.Book, error) {
 	isbn = strings.TrimSpace(isbn)
 	if isbn == "" {
-		return nil, errors.New("ISBN inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidISBN)
 	}
 
 	book, err := s.repo.FindByISBN(isbn)
@@ -74,7 +74,7 @@ func (s *BookService) GetByISBN(isbn string) (*models.Book, error) {
 		return nil, err
 	}
 	if book == nil {
-		return nil, errors.New("livro não encontrado")
+		return nil, apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	return book, nil
@@ -87,7 +87,7 @@ func (s *BookService) Create(book models.Book, authorIDs []int64, categoryIDs []
 	}
 
 	if len(authorIDs) == 0 {
-		return nil, errors.New("livro deve ter pelo menos um autor")
+		return nil, apperrors.Validation(apperrors.CodeBookNeedsAuthor)
 	}
 
 	book.Title = strings.TrimSpace(book.Title)
@@ -102,7 +102,7 @@ func (s *BookService) Create(book models.Book, authorIDs []int64, categoryIDs []
 			return nil, err
 		}
 		if existing != nil {
-			return nil, errors.New("já existe um livro com este ISBN")
+			return nil, apperrors.Conflict(apperrors.CodeBookISBNTaken)
 		}
 	}
 
@@ -112,7 +112,7 @@ func (s *BookService) Create(book models.Book, authorIDs []int64, categoryIDs []
 			return nil, err
 		}
 		if author == nil {
-			return nil, errors.New("um ou mais autores não encontrados")
+			return nil, apperrors.Validation(apperrors.CodeAuthorsNotFound)
 		}
 	}
 
@@ -122,7 +122,7 @@ func (s *BookService) Create(book models.Book, authorIDs []int64, categoryIDs []
 			return nil, err
 		}
 		if category == nil {
-			return nil, errors.New("uma ou mais categorias não encontradas")
+			return nil, apperrors.Validation(apperrors.CodeCategoriesNotFound)
 		}
 	}
 
@@ -136,7 +136,7 @@ func (s *BookService) Create(book models.Book, authorIDs []int64, categoryIDs []
 
 func (s *BookService) Update(id int64, book models.Book) (*models.Book, error) {
 	if id <= 0 {
-		return nil, errors.New("ID inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	if err := s.validateBook(book); err != nil {
@@ -154,7 +154,7 @@ func (s *BookService) Update(id int64, book models.Book) (*models.Book, error) {
 		return nil, err
 	}
 	if existing == nil {
-		return nil, errors.New("livro não encontrado")
+		return nil, apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	if book.ISBN != nil && *book.ISBN != "" {
@@ -163,7 +163,7 @@ func (s *BookService) Update(id int64, book models.Book) (*models.Book, error) {
 			return nil, err
 		}
 		if duplicate != nil && duplicate.ID != id {
-			return nil, errors.New("já existe outro livro com este ISBN")
+			return nil, apperrors.Conflict(apperrors.CodeBookISBNTaken)
 		}
 	}
 
@@ -177,11 +177,11 @@ func (s *BookService) Update(id int64, book models.Book) (*models.Book, error) {
 
 func (s *BookService) UpdateAuthors(bookID int64, authorIDs []int64) error {
 	if bookID <= 0 {
-		return errors.New("ID de livro inválido")
+		return apperrors.Validation(apperrors.CodeInvalidBookID)
 	}
 
 	if len(authorIDs) == 0 {
-		return errors.New("livro deve ter pelo menos um autor")
+		return apperrors.Validation(apperrors.CodeBookNeedsAuthor)
 	}
 
 	// Verifica se o livro existe
@@ -190,7 +190,7 @@ func (s *BookService) UpdateAuthors(bookID int64, authorIDs []int64) error {
 		return err
 	}
 	if book == nil {
-		return errors.New("livro não encontrado")
+		return apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	for _, authorID := range authorIDs {
@@ -199,7 +199,7 @@ func (s *BookService) UpdateAuthors(bookID int64, authorIDs []int64) error {
 			return err
 		}
 		if author == nil {
-			return errors.New("um ou mais autores não encontrados")
+			return apperrors.Validation(apperrors.CodeAuthorsNotFound)
 		}
 	}
 
@@ -213,7 +213,7 @@ func (s *BookService) UpdateAuthors(bookID int64, authorIDs []int64) error {
 
 func (s *BookService) UpdateCategories(bookID int64, categoryIDs []int) error {
 	if bookID <= 0 {
-		return errors.New("ID de livro inválido")
+		return apperrors.Validation(apperrors.CodeInvalidBookID)
 	}
 
 	book, err := s.repo.FindByID(bookID)
@@ -221,7 +221,7 @@ func (s *BookService) UpdateCategories(bookID int64, categoryIDs []int) error {
 		return err
 	}
 	if book == nil {
-		return errors.New("livro não encontrado")
+		return apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	for _, categoryID := range categoryIDs {
@@ -230,7 +230,7 @@ func (s *BookService) UpdateCategories(bookID int64, categoryIDs []int) error {
 			return err
 		}
 		if category == nil {
-			return errors.New("uma ou mais categorias não encontradas")
+			return apperrors.Validation(apperrors.CodeCategoriesNotFound)
 		}
 	}
 
@@ -244,7 +244,7 @@ func (s *BookService) UpdateCategories(bookID int64, categoryIDs []int) error {
 
 func (s *BookService) Delete(id int64) error {
 	if id <= 0 {
-		return errors.New("ID inválido")
+		return apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	book, err := s.repo.FindByID(id)
@@ -252,7 +252,7 @@ func (s *BookService) Delete(id int64) error {
 		return err
 	}
 	if book == nil {
-		return errors.New("livro não encontrado")
+		return apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	err = s.repo.Delete(id)
@@ -264,109 +264,96 @@ func (s *BookService) Delete(id int64) error {
 }
 
 func (s *BookService) Search(searchTerm string, limit, offset int) ([]models.Book, int, error) {
-	if strings.TrimSpace(searchTerm) == "" {
-		return s.GetAll(limit, offset)
-	}
+	page, total, err := s.List(repository.BookQuery{Search: searchTerm, Limit: limit, Offset: offset})
+	return page.Items, total, err
+}
 
-	if limit <= 0 || limit > 100 {
-		limit = 100
-	}
-	if offset < 0 {
-		offset = 0
+func (s *BookService) GetByAuthor(authorID int64, limit, offset int) ([]models.Book, int, error) {
+	if authorID <= 0 {
+		return nil, 0, apperrors.Validation(apperrors.CodeInvalidAuthorID)
 	}
 
-	books, err := s.repo.Search(searchTerm, limit, offset)
-	if err != nil {
-		return nil, 0, err
-	}
+	page, total, err := s.List(repository.BookQuery{AuthorID: authorID, Limit: limit, Offset: offset})
+	return page.Items, total, err
+}
 
-	total, err := s.repo.Count()
-	if err != nil {
-		return nil, 0, err
+func (s *BookService) GetByCategory(categoryID int, limit, offset int) ([]models.Book, int, error) {
+	if categoryID <= 0 {
+		return nil, 0, apperrors.Validation(apperrors.CodeInvalidCategoryID)
 	}
 
-	return books, total, nil
+	page, total, err := s.List(repository.BookQuery{CategoryID: categoryID, Limit: limit, Offset: offset})
+	return page.Items, total, err
 }
 
-func (s *BookService) GetByAuthor(authorID int64, limit, offset int) ([]models.Book, int, error) {
-	if authorID <= 0 {
-		return nil, 0, errors.New("ID de autor inválido")
+// List lista uma página dos livros que atendem a query, com o total sem
+// paginação. O autor e a categoria filtrados precisam existir; com
+// query.Cursor, Offset é ignorado
+func (s *BookService) List(query repository.BookQuery) (repository.Page[models.Book], int, error) {
+	if query.AuthorID < 0 {
+		return repository.Page[models.Book]{}, 0, apperrors.Validation(apperrors.CodeInvalidAuthorID)
 	}
-
-	author, err := s.authorRepo.FindByID(authorID)
-	if err != nil {
-		return nil, 0, err
-	}
-	if author == nil {
-		return nil, 0, errors.New("autor não encontrado")
+	if query.CategoryID < 0 {
+		return repository.Page[models.Book]{}, 0, apperrors.Validation(apperrors.CodeInvalidCategoryID)
 	}
 
-	if limit <= 0 || limit > 100 {
-		limit = 100
-	}
-	if offset < 0 {
-		offset = 0
+	if query.AuthorID != 0 {
+		author, err := s.authorRepo.FindByID(query.AuthorID)
+		if err != nil {
+			return repository.Page[models.Book]{}, 0, err
+		}
+		if author == nil {
+			return repository.Page[models.Book]{}, 0, apperrors.NotFound(apperrors.CodeAuthorNotFound)
+		}
 	}
 
-	books, err := s.repo.FindByAuthorID(authorID, limit, offset)
-	if err != nil {
-		return nil, 0, err
+	if query.CategoryID != 0 {
+		category, err := s.categoryRepo.FindByID(query.CategoryID)
+		if err != nil {
+			return repository.Page[models.Book]{}, 0, err
+		}
+		if category == nil {
+			return repository.Page[models.Book]{}, 0, apperrors.NotFound(apperrors.CodeCategoryNotFound)
+		}
 	}
 
-	total := len(books)
-
-	return books, total, nil
-}
-
-func (s *BookService) GetByCategory(categoryID int, limit, offset int) ([]models.Book, int, error) {
-	if categoryID <= 0 {
-		return nil, 0, errors.New("ID de categoria inválido")
+	if query.Limit <= 0 || query.Limit > 100 {
+		query.Limit = 100
+	}
+	if query.Offset < 0 || query.Cursor != nil {
+		query.Offset = 0
 	}
+	query.Search = strings.TrimSpace(query.Search)
 
-	category, err := s.categoryRepo.FindByID(categoryID)
+	page, err := s.repo.FindPageByQuery(query)
 	if err != nil {
-		return nil, 0, err
-	}
-	if category == nil {
-		return nil, 0, errors.New("categoria não encontrada")
-	}
-	
-	if limit <= 0 || limit > 100 {
-		limit = 100
-	}
-	if offset < 0 {
-		offset = 0
+		return repository.Page[models.Book]{}, 0, err
 	}
 
-	books, err := s.repo.FindByCategoryID(categoryID, limit, offset)
+	total, err := s.repo.CountByQuery(query)
 	if err != nil {
-		return nil, 0, err
+		return repository.Page[models.Book]{}, 0, err
 	}
 
-	total := len(books)
-
-	return books, total, nil
+	return page, total, nil
 }
 
 func (s *BookService) validateBook(book models.Book) error {
-	if strings.TrimSpace(book.Title) == "" {
-		return errors.New("título do livro é obrigatório")
-	}
+	var fields apperrors.Fields
 
-	if len(book.Title) < 1 {
-		return errors.New("título do livro deve ter pelo menos 1 caractere")
-	}
-
-	if len(book.Title) > 500 {
-		return errors.New("título do livro deve ter no máximo 500 caracteres")
+	switch {
+	case strings.TrimSpace(book.Title) == "":
+		fields.Add("title", apperrors.CodeRequired)
+	case len(book.Title) > 500:
+		fields.AddLimit("title", apperrors.CodeTooLong, 500)
 	}
 
 	if book.PublishedYear < 0 || book.PublishedYear > 9999 {
-		return errors.New("ano de publicação inválido")
+		fields.Add("published_year", apperrors.CodeOutOfRange)
 	}
 
 	if book.Pages < 0 {
-		return errors.New("número de páginas não pode ser negativo")
+		fields.Add("pages", apperrors.CodeNegative)
 	}
 
 	if book.ISBN != nil && *book.ISBN != "" {
@@ -374,9 +361,9 @@ func (s *BookService) validateBook(book models.Book) error {
 		isbn = strings.ReplaceAll(isbn, " ", "")
 
 		if len(isbn) != 10 && len(isbn) != 13 {
-			return errors.New("ISBN deve ter 10 ou 13 dígitos")
+			fields.Add("isbn", apperrors.CodeInvalid)
 		}
 	}
 
-	return nil
+	return fields.Err()
 }
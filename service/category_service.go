@@ -1,22 +1,22 @@
 package service
 
 import (
-	"errors"
+	"quote-api/apperrors"
 	"quote-api/models"
 	"quote-api/repository"
 	"strings"
 )
 
 type CategoryService struct {
-	repo     repository.CategoryRepository
-	bookRepo repository.BookRepository
+	repo     repository.Categories
+	bookRepo repository.Books
 }
 
-func NewCategoryService(repo repository.CategoryRepository, bookRepo repository.BookRepository) *CategoryService {
+func NewCategoryService(repo repository.Categories, bookRepo repository.Books) *CategoryService {
 	return &CategoryService{repo: repo, bookRepo: bookRepo}
 }
 
-func (s *CategoryService) getAll(limit, offset int) ([]models.Category, int, error) {
+func (s *CategoryService) GetAll(limit, offset int) ([]models.Category, int, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 100
 	}
@@ -37,9 +37,9 @@ func (s *CategoryService) getAll(limit, offset int) ([]models.Category, int, err
 	return categories, total, nil
 }
 
-func (s *CategoryService) getById(id int) (*models.Category, error) {
+func (s *CategoryService) GetByID(id int) (*models.Category, error) {
 	if id <= 0 {
-		return nil, errors.New("Invalid ID")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	category, err := s.repo.FindByID(id)
@@ -48,7 +48,7 @@ func (s *CategoryService) getById(id int) (*models.Category, error) {
 	}
 
 	if category == nil {
-		return nil, errors.New("Category not found")
+		return nil, apperrors.NotFound(apperrors.CodeCategoryNotFound)
 	}
 
 	return category, nil
@@ -62,13 +62,12 @@ func (s *CategoryService) Create(category models.Category) (*models.Category, er
 
 	category.Name = strings.TrimSpace(category.Name)
 
-	existing, err := s.repo.FindByID(category.ID)
-
-	if err == nil {
+	existing, err := s.repo.FindByName(category.Name)
+	if err != nil {
 		return nil, err
 	}
 	if existing != nil {
-		return nil, errors.New("Category already exists")
+		return nil, apperrors.Conflict(apperrors.CodeCategoryNameTaken)
 	}
 
 	created, err := s.repo.Create(category)
@@ -81,7 +80,7 @@ func (s *CategoryService) Create(category models.Category) (*models.Category, er
 
 func (s *CategoryService) Update(id int, category models.Category) (*models.Category, error) {
 	if id <= 0 {
-		return nil, errors.New("Invalid ID")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	if err := s.validateCategory(category); err != nil {
@@ -96,7 +95,7 @@ func (s *CategoryService) Update(id int, category models.Category) (*models.Cate
 	}
 
 	if existing == nil {
-		return nil, errors.New("Category not found")
+		return nil, apperrors.NotFound(apperrors.CodeCategoryNotFound)
 	}
 
 	duplicate, err := s.repo.FindByName(category.Name)
@@ -105,7 +104,7 @@ func (s *CategoryService) Update(id int, category models.Category) (*models.Cate
 	}
 
 	if duplicate != nil && duplicate.ID != id {
-		return nil, errors.New("Category already exists")
+		return nil, apperrors.Conflict(apperrors.CodeCategoryNameTaken)
 	}
 
 	updated, err := s.repo.Update(id, category)
@@ -118,7 +117,7 @@ func (s *CategoryService) Update(id int, category models.Category) (*models.Cate
 
 func (s *CategoryService) Delete(id int) error {
 	if id <= 0 {
-		return errors.New("Invalid ID")
+		return apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	category, err := s.repo.FindByID(id)
@@ -127,7 +126,7 @@ func (s *CategoryService) Delete(id int) error {
 	}
 
 	if category == nil {
-		return errors.New("Category not found")
+		return apperrors.NotFound(apperrors.CodeCategoryNotFound)
 	}
 
 	books, err := s.bookRepo.FindByCategoryID(id, 1, 0)
@@ -135,7 +134,7 @@ func (s *CategoryService) Delete(id int) error {
 		return err
 	}
 	if len(books) > 0 {
-		return errors.New("Cannot delete category because there are still books in the category")
+		return apperrors.Conflict(apperrors.CodeCategoryHasBooks)
 	}
 
 	err = s.repo.Delete(id)
@@ -148,7 +147,7 @@ func (s *CategoryService) Delete(id int) error {
 
 func (s *CategoryService) Search(searchTerm string, limit, offset int) ([]models.Category, int, error) {
 	if strings.TrimSpace(searchTerm) == "" {
-		return s.getAll(limit, offset)
+		return s.GetAll(limit, offset)
 	}
 
 	if limit <= 0 || limit > 100 {
@@ -171,7 +170,7 @@ func (s *CategoryService) Search(searchTerm string, limit, offset int) ([]models
 
 func (s *CategoryService) GetBooksByCategory(categoryID int, limit, offset int) ([]models.Book, error) {
 	if categoryID <= 0 {
-		return nil, errors.New("ID de categoria inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidCategoryID)
 	}
 
 	category, err := s.repo.FindByID(categoryID)
@@ -179,7 +178,7 @@ func (s *CategoryService) GetBooksByCategory(categoryID int, limit, offset int)
 		return nil, err
 	}
 	if category == nil {
-		return nil, errors.New("categoria não encontrada")
+		return nil, apperrors.NotFound(apperrors.CodeCategoryNotFound)
 	}
 
 	if limit <= 0 || limit > 100 {
@@ -198,16 +197,16 @@ func (s *CategoryService) GetBooksByCategory(categoryID int, limit, offset int)
 }
 
 func (s *CategoryService) validateCategory(category models.Category) error {
-	if strings.TrimSpace(category.Name) == "" {
-		return errors.New("Category name is required")
-	}
+	var fields apperrors.Fields
 
-	if len(category.Name) < 2 {
-		return errors.New("Category name is too short")
+	switch name := strings.TrimSpace(category.Name); {
+	case name == "":
+		fields.Add("name", apperrors.CodeRequired)
+	case len(category.Name) < 2:
+		fields.AddLimit("name", apperrors.CodeTooShort, 2)
+	case len(category.Name) > 100:
+		fields.AddLimit("name", apperrors.CodeTooLong, 100)
 	}
 
-	if len(category.Name) > 100 {
-		return errors.New("Category name is too long. Limit is 100 characters")
-	}
-	return nil
+	return fields.Err()
 }
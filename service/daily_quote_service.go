@@ -0,0 +1,229 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"quote-api/apperrors"
+	"quote-api/models"
+	"quote-api/repository"
+	"strings"
+	"time"
+)
+
+// DailyQuoteConfig configura a citação do dia
+type DailyQuoteConfig struct {
+	// Location define em que momento o dia vira; nil usa UTC
+	Location *time.Location
+	// Window é quantos dias uma citação escolhida fica sem voltar no mesmo
+	// escopo; 0 permite repetir
+	Window int
+}
+
+// DailyScope restringe a citação do dia a uma categoria, um autor e/ou uma
+// tag; os campos preenchidos são combinados com AND. Cada escopo tem a sua
+// própria citação e o seu próprio histórico
+type DailyScope struct {
+	CategoryID int
+	AuthorID   int64
+	TagID      int64
+}
+
+// key identifica o escopo em daily_quote.scope
+func (s DailyScope) key() string {
+	var parts []string
+	if s.AuthorID != 0 {
+		parts = append(parts, fmt.Sprintf("author:%d", s.AuthorID))
+	}
+	if s.CategoryID != 0 {
+		parts = append(parts, fmt.Sprintf("category:%d", s.CategoryID))
+	}
+	if s.TagID != 0 {
+		parts = append(parts, fmt.Sprintf("tag:%d", s.TagID))
+	}
+	if len(parts) == 0 {
+		return "all"
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s DailyScope) query() repository.QuoteQuery {
+	var query repository.QuoteQuery
+	if s.AuthorID != 0 {
+		query.AuthorIDs = []int64{s.AuthorID}
+	}
+	if s.CategoryID != 0 {
+		query.CategoryIDs = []int{s.CategoryID}
+	}
+	if s.TagID != 0 {
+		query.TagIDs = []int64{s.TagID}
+	}
+	return query
+}
+
+func (s DailyScope) validate() error {
+	var fields apperrors.Fields
+	if s.CategoryID < 0 {
+		fields.Add("category_id", apperrors.CodeInvalid)
+	}
+	if s.AuthorID < 0 {
+		fields.Add("author_id", apperrors.CodeInvalid)
+	}
+	if s.TagID < 0 {
+		fields.Add("tag_id", apperrors.CodeInvalid)
+	}
+	return fields.Err()
+}
+
+// DailyQuoteService escolhe uma citação por dia e por escopo. A escolha é
+// gravada em daily_quote na primeira consulta do dia, então todas as
+// consultas seguintes, de qualquer processo, recebem a mesma citação
+type DailyQuoteService struct {
+	repo      repository.DailyQuotes
+	quoteRepo repository.Quotes
+	config    DailyQuoteConfig
+}
+
+func NewDailyQuoteService(repo repository.DailyQuotes, quoteRepo repository.Quotes, config DailyQuoteConfig) *DailyQuoteService {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	return &DailyQuoteService{repo: repo, quoteRepo: quoteRepo, config: config}
+}
+
+// Today retorna a citação do dia do escopo, escolhendo-a se ainda não houver
+func (s *DailyQuoteService) Today(scope DailyScope) (*models.DailyQuote, error) {
+	if err := scope.validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(s.config.Location)
+	day := now.Format(time.DateOnly)
+	key := scope.key()
+
+	entry, err := s.repo.FindByDay(key, day)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		quote, err := s.pick(scope, key, now)
+		if err != nil {
+			return nil, err
+		}
+
+		entry, err = s.repo.Create(models.DailyQuote{Day: day, Scope: key, QuoteID: quote.ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entry.Quote, err = s.quoteRepo.FindByID(entry.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Quote == nil {
+		return nil, apperrors.NotFound(apperrors.CodeNoQuotes)
+	}
+
+	return entry, nil
+}
+
+// History lista as citações do dia já escolhidas para o escopo, da mais recente
+// para a mais antiga
+func (s *DailyQuoteService) History(scope DailyScope, limit, offset int) ([]models.DailyQuote, int, error) {
+	if err := scope.validate(); err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	key := scope.key()
+	entries, err := s.repo.FindHistory(key, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountHistory(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(entries) == 0 {
+		return entries, total, nil
+	}
+
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.QuoteID
+	}
+
+	quotes, err := s.quoteRepo.FindByQuery(repository.QuoteQuery{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[int64]*models.Quote, len(quotes))
+	for i := range quotes {
+		byID[quotes[i].ID] = &quotes[i]
+	}
+	for i := range entries {
+		entries[i].Quote = byID[entries[i].QuoteID]
+	}
+
+	return entries, total, nil
+}
+
+// pick escolhe a citação do dia entre as do escopo, deixando de fora as
+// escolhidas dentro da janela. Se a janela excluir todas, volta a considerar
+// o escopo inteiro. A posição sorteada vem do hash do escopo e do dia, então
+// processos diferentes chegam à mesma citação
+func (s *DailyQuoteService) pick(scope DailyScope, key string, now time.Time) (*models.Quote, error) {
+	query := scope.query()
+
+	if s.config.Window > 0 {
+		since := now.AddDate(0, 0, -s.config.Window).Format(time.DateOnly)
+		recent, err := s.repo.FindQuoteIDsSince(key, since)
+		if err != nil {
+			return nil, err
+		}
+		query.ExcludeIDs = recent
+	}
+
+	total, err := s.quoteRepo.CountByQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if total == 0 && len(query.ExcludeIDs) > 0 {
+		query.ExcludeIDs = nil
+		if total, err = s.quoteRepo.CountByQuery(query); err != nil {
+			return nil, err
+		}
+	}
+
+	if total == 0 {
+		return nil, apperrors.NotFound(apperrors.CodeNoQuotes)
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(key + "|" + now.Format(time.DateOnly)))
+
+	query.Order = repository.QuoteOrderCreatedAt
+	query.Limit = 1
+	query.Offset = int(hash.Sum64() % uint64(total))
+
+	quotes, err := s.quoteRepo.FindByQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, apperrors.NotFound(apperrors.CodeNoQuotes)
+	}
+
+	return &quotes[0], nil
+}
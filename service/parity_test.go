@@ -0,0 +1,535 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"quote-api/apperrors"
+	"quote-api/database"
+	"quote-api/models"
+	"quote-api/repository"
+	"quote-api/repository/memory"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Os testes deste arquivo rodam o mesmo cenário nos services ligados ao SQLite
+// e ao repository/memory e exigem o mesmo resultado: ordenação, cursores,
+// totais e o que some em cada remoção
+
+func TestMain(m *testing.M) {
+	// database.Open registra cada conexão no log padrão
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// backend reúne os services ligados a uma implementação dos repositórios
+type backend struct {
+	authors    *AuthorService
+	books      *BookService
+	categories *CategoryService
+	quotes     *QuoteService
+	tags       *TagService
+}
+
+func sqliteBackend(t *testing.T) backend {
+	store, err := database.Open(database.Config{DSN: filepath.Join(t.TempDir(), "quotes.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	authorRepo := repository.NewAuthorRepository(store)
+	bookRepo := repository.NewBookRepository(store)
+	categoryRepo := repository.NewCategoryRepository(store)
+	quoteRepo := repository.NewQuoteRepository(store)
+	tagRepo := repository.NewTagRepository(store)
+
+	return backend{
+		authors:    NewAuthorService(authorRepo, bookRepo),
+		books:      NewBookService(bookRepo, authorRepo, categoryRepo),
+		categories: NewCategoryService(categoryRepo, bookRepo),
+		quotes:     NewQuoteService(quoteRepo, bookRepo),
+		tags:       NewTagService(tagRepo, quoteRepo),
+	}
+}
+
+func memoryBackend() backend {
+	store := memory.NewStore()
+
+	return backend{
+		authors:    NewAuthorService(store.Authors(), store.Books()),
+		books:      NewBookService(store.Books(), store.Authors(), store.Categories()),
+		categories: NewCategoryService(store.Categories(), store.Books()),
+		quotes:     NewQuoteService(store.Quotes(), store.Books()),
+		tags:       NewTagService(store.Tags(), store.Quotes()),
+	}
+}
+
+// checkParity roda scenario em uma biblioteca nova de cada backend e compara
+// o que ele retorna
+func checkParity[T any](t *testing.T, scenario func(t *testing.T, b backend) T) {
+	t.Helper()
+
+	sqlite := sqliteBackend(t)
+	seedLibrary(t, sqlite)
+	want := scenario(t, sqlite)
+
+	mem := memoryBackend()
+	seedLibrary(t, mem)
+	got := scenario(t, mem)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("memória difere do SQLite\nSQLite:  %v\nmemória: %v", want, got)
+	}
+}
+
+// seedLibrary grava a mesma biblioteca, na mesma ordem, em qualquer backend.
+// Os títulos misturam maiúsculas e acentos, há livros com e sem posição nas
+// citações e duas grafias da mesma tag
+func seedLibrary(t *testing.T, b backend) {
+	t.Helper()
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"Romance", "ensaio", "Poesia"} {
+		_, err := b.categories.Create(models.Category{Name: name})
+		must(err)
+	}
+	for _, name := range []string{"Machado de Assis", "clarice Lispector", "Érico Veríssimo", "Jorge Luis Borges"} {
+		_, err := b.authors.Create(models.Author{Name: name})
+		must(err)
+	}
+
+	books := []struct {
+		title      string
+		year       int
+		authors    []int64
+		categories []int
+	}{
+		{"Dom Casmurro", 1899, []int64{1}, []int{1}},
+		{"a hora da estrela", 1977, []int64{2}, []int{1}},
+		{"Água viva", 1973, []int64{2}, []int{1, 2}},
+		{"Ficções", 1944, []int64{4}, []int{1}},
+		{"O tempo e o vento", 1949, []int64{3}, nil},
+		{"Dom Casmurro", 1900, []int64{1, 4}, []int{3}},
+	}
+	for _, book := range books {
+		_, err := b.books.Create(models.Book{Title: book.title, PublishedYear: book.year}, book.authors, book.categories)
+		must(err)
+	}
+
+	location := func(n int) *int { return &n }
+	quotes := []models.Quote{
+		{BookID: 1, Text: "Capitu, olhos de ressaca", LocationStart: location(120), LocationEnd: location(125)},
+		{BookID: 1, Text: "A vida é cheia de obrigações", LocationStart: location(40)},
+		{BookID: 2, Text: "Tudo no mundo começou com um sim", Kind: models.QuoteKindNote},
+		{BookID: 3, Text: "Eu te digo: estou tentando captar a quarta dimensão"},
+		{BookID: 3, Text: "Liberdade é pouco. O que desejo ainda não tem nome", LocationStart: location(10)},
+		{BookID: 4, Text: "O universo, que outros chamam a Biblioteca", LocationStart: location(300)},
+		{BookID: 4, Text: "Uma tempestade de espelhos e labirintos"},
+		{BookID: 5, Text: "Era uma noite fria de tempestade", LocationStart: location(1)},
+		{BookID: 5, Text: "Noite de vento, noite dos mortos", LocationStart: location(1)},
+		{BookID: 6, Text: "Nem tudo é claro na vida", LocationStart: location(5)},
+		{BookID: 1, Text: "Marcação sem texto relevante", Kind: models.QuoteKindBookmark},
+		{BookID: 2, Text: "Não se preocupe em entender", LocationStart: location(77)},
+	}
+	for _, quote := range quotes {
+		_, err := b.quotes.Create(quote)
+		must(err)
+	}
+
+	// mexe em updated_at fora da ordem de criação
+	for _, id := range []int64{4, 1, 9} {
+		quote, err := b.quotes.GetByID(id)
+		must(err)
+		_, err = b.quotes.Update(id, *quote)
+		must(err)
+	}
+
+	for _, id := range []int64{1, 6, 8} {
+		_, err := b.quotes.SetFavorite(id, true)
+		must(err)
+	}
+	ratings := []struct {
+		quote  int64
+		rating int
+	}{
+		{1, 5}, {2, 3}, {7, 4}, {10, 1},
+	}
+	for _, r := range ratings {
+		_, err := b.quotes.SetRating(r.quote, r.rating)
+		must(err)
+	}
+
+	tags := []struct {
+		quote int64
+		name  string
+	}{
+		{1, "Ação"}, {7, "AÇÃO"}, {8, "ação"}, {1, "amor"}, {5, "Amor"}, {10, "vida"},
+	}
+	for _, tag := range tags {
+		_, err := b.tags.AddToQuote(tag.quote, tag.name)
+		must(err)
+	}
+}
+
+// quoteIDs resume uma lista de citações pelos IDs, na ordem recebida
+func quoteIDs(quotes []models.Quote) []int64 {
+	ids := make([]int64, len(quotes))
+	for i, quote := range quotes {
+		ids[i] = quote.ID
+	}
+	return ids
+}
+
+func bookIDs(books []models.Book) []int64 {
+	ids := make([]int64, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+	return ids
+}
+
+// quoteDetails descreve a citação com os campos que vêm de outras tabelas
+func quoteDetails(quote models.Quote) string {
+	var tags []string
+	for _, tag := range quote.Tags {
+		tags = append(tags, tag.Name)
+	}
+	title := ""
+	var authors []string
+	if quote.Book != nil {
+		title = quote.Book.Title
+		for _, author := range quote.Book.Authors {
+			authors = append(authors, author.Name)
+		}
+	}
+	rating := 0
+	if quote.Rating != nil {
+		rating = *quote.Rating
+	}
+	return fmt.Sprintf("%d %s [%s] [%s] favorite=%v rating=%d",
+		quote.ID, title, strings.Join(authors, ", "), strings.Join(tags, ", "), quote.Favorite, rating)
+}
+
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr.Code
+	}
+	return err.Error()
+}
+
+// keysetOrders são as ordenações de citações que aceitam cursor
+var keysetOrders = []repository.QuoteOrder{
+	repository.QuoteOrderCreatedAt,
+	repository.QuoteOrderUpdatedAt,
+	repository.QuoteOrderBookTitle,
+	repository.QuoteOrderLocation,
+}
+
+func TestParity_QuoteOrder(t *testing.T) {
+	checkParity(t, func(t *testing.T, b backend) map[repository.QuoteOrder][]string {
+		result := make(map[repository.QuoteOrder][]string)
+		for _, order := range append(keysetOrders, repository.QuoteOrderRelevance) {
+			page, total, err := b.quotes.List(repository.QuoteQuery{Order: order})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != len(page.Items) {
+				t.Errorf("%s: total %d, %d itens", order, total, len(page.Items))
+			}
+			for _, quote := range page.Items {
+				result[order] = append(result[order], quoteDetails(quote))
+			}
+		}
+		return result
+	})
+}
+
+// cursorWalk guarda as páginas de uma listagem percorrida com cursores
+type cursorWalk struct {
+	Forward  [][]int64
+	Backward [][]int64
+	HasMore  []bool
+}
+
+// walkQuotes percorre a listagem até o fim pelos cursores Next e volta até o
+// início pelos cursores Prev. Os cursores passam por Encode e DecodeCursor,
+// como nas requisições
+func walkQuotes(t *testing.T, b backend, query repository.QuoteQuery) cursorWalk {
+	t.Helper()
+
+	var walk cursorWalk
+	reencode := func(cursor *repository.Cursor) *repository.Cursor {
+		decoded, err := repository.DecodeCursor(cursor.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+
+	var last repository.Page[models.Quote]
+	for pages := 0; ; pages++ {
+		if pages > 20 {
+			t.Fatalf("%s: a paginação não termina", query.Order)
+		}
+		page, _, err := b.quotes.List(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		walk.Forward = append(walk.Forward, quoteIDs(page.Items))
+		walk.HasMore = append(walk.HasMore, page.HasMore)
+		last = page
+		if page.Next == nil {
+			break
+		}
+		query.Cursor = reencode(page.Next)
+	}
+
+	for page := last; page.Prev != nil; {
+		query.Cursor = reencode(page.Prev)
+		var err error
+		if page, _, err = b.quotes.List(query); err != nil {
+			t.Fatal(err)
+		}
+		walk.Backward = append(walk.Backward, quoteIDs(page.Items))
+		if len(walk.Backward) > 20 {
+			t.Fatalf("%s: a paginação para trás não termina", query.Order)
+		}
+	}
+
+	return walk
+}
+
+func TestParity_QuoteCursors(t *testing.T) {
+	checkParity(t, func(t *testing.T, b backend) map[repository.QuoteOrder]cursorWalk {
+		result := make(map[repository.QuoteOrder]cursorWalk)
+		for _, order := range keysetOrders {
+			walk := walkQuotes(t, b, repository.QuoteQuery{Order: order, Limit: 5})
+
+			all, _, err := b.quotes.List(repository.QuoteQuery{Order: order})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var forward []int64
+			for _, page := range walk.Forward {
+				forward = append(forward, page...)
+			}
+			if !reflect.DeepEqual(forward, quoteIDs(all.Items)) {
+				t.Errorf("%s: páginas %v, listagem completa %v", order, walk.Forward, quoteIDs(all.Items))
+			}
+			for i, page := range walk.Backward {
+				if want := walk.Forward[len(walk.Forward)-2-i]; !reflect.DeepEqual(page, want) {
+					t.Errorf("%s: página %v para trás, %v para frente", order, page, want)
+				}
+			}
+
+			result[order] = walk
+		}
+
+		result["filtered"] = walkQuotes(t, b, repository.QuoteQuery{
+			Order:   repository.QuoteOrderLocation,
+			BookIDs: []int64{1, 5},
+			Limit:   2,
+		})
+		return result
+	})
+}
+
+func TestParity_QuoteCounts(t *testing.T) {
+	minRating := 3
+	minLength := 30
+	queries := map[string]repository.QuoteQuery{
+		"all":        {},
+		"books":      {BookIDs: []int64{1, 4}},
+		"author":     {AuthorIDs: []int64{4}},
+		"category":   {CategoryIDs: []int{2}},
+		"tag ids":    {TagIDs: []int64{1}},
+		"tag names":  {TagNames: []string{"ação", "VIDA"}},
+		"favorites":  {FavoritesOnly: true},
+		"min rating": {MinRating: &minRating},
+		"min length": {MinLength: &minLength},
+		// com o índice FTS5, a ordem padrão de uma busca é a relevância, que a
+		// memória não calcula
+		"text":         {Text: "tempestade", TextScope: repository.SearchScopeText, Order: repository.QuoteOrderCreatedAt},
+		"text and tag": {Text: "tempestade", TagNames: []string{"Ação"}, Order: repository.QuoteOrderCreatedAt},
+		"text in book": {Text: "estrela", TextScope: repository.SearchScopeAll, Order: repository.QuoteOrderCreatedAt},
+		"exclude":      {BookIDs: []int64{1}, ExcludeIDs: []int64{2}},
+		"page":         {Limit: 3, Offset: 4},
+	}
+
+	checkParity(t, func(t *testing.T, b backend) map[string]string {
+		result := make(map[string]string)
+		for name, query := range queries {
+			page, total, err := b.quotes.List(query)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			result[name] = fmt.Sprintf("%v total=%d more=%v", quoteIDs(page.Items), total, page.HasMore)
+		}
+
+		for _, id := range []int64{1, 2, 5, 6} {
+			quotes, total, err := b.quotes.GetByBookID(id, 100, 0)
+			result[fmt.Sprintf("book %d", id)] = fmt.Sprintf("%v total=%d %s", quoteIDs(quotes), total, errorCode(err))
+		}
+		for _, id := range []int64{1, 2, 4} {
+			quotes, total, err := b.quotes.GetByAuthorID(id, 100, 0)
+			result[fmt.Sprintf("author %d", id)] = fmt.Sprintf("%v total=%d %s", quoteIDs(quotes), total, errorCode(err))
+		}
+		for _, id := range []int{1, 2, 3} {
+			quotes, total, err := b.quotes.GetByCategoryID(id, 100, 0)
+			result[fmt.Sprintf("category %d", id)] = fmt.Sprintf("%v total=%d %s", quoteIDs(quotes), total, errorCode(err))
+		}
+		return result
+	})
+}
+
+func TestParity_Books(t *testing.T) {
+	queries := map[string]repository.BookQuery{
+		"all":      {},
+		"author":   {AuthorID: 4},
+		"category": {CategoryID: 1},
+		"search":   {Search: "dom"},
+		"page":     {Limit: 2, Offset: 1},
+	}
+
+	checkParity(t, func(t *testing.T, b backend) map[string]string {
+		result := make(map[string]string)
+		for name, query := range queries {
+			page, total, err := b.books.List(query)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			result[name] = fmt.Sprintf("%v total=%d more=%v", bookIDs(page.Items), total, page.HasMore)
+		}
+
+		query := repository.BookQuery{Limit: 2}
+		for pages := 0; ; pages++ {
+			page, _, err := b.books.List(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result["cursor"] += fmt.Sprint(bookIDs(page.Items))
+			if page.Next == nil || pages > 10 {
+				break
+			}
+			if query.Cursor, err = repository.DecodeCursor(page.Next.Encode()); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		book, err := b.books.GetByID(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, author := range book.Authors {
+			result["authors of 6"] += author.Name + ";"
+		}
+		return result
+	})
+}
+
+func TestParity_Tags(t *testing.T) {
+	checkParity(t, func(t *testing.T, b backend) []string {
+		tags, total, err := b.tags.GetAll(100, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := []string{fmt.Sprintf("total=%d", total)}
+		for _, tag := range tags {
+			result = append(result, fmt.Sprintf("%d %s quotes=%d", tag.ID, tag.Name, tag.QuoteCount))
+		}
+
+		_, err = b.tags.Create(models.Tag{Name: "AMOR"})
+		result = append(result, "create AMOR: "+errorCode(err))
+		_, err = b.tags.Create(models.Tag{Name: "Ação e reação"})
+		result = append(result, "create Ação e reação: "+errorCode(err))
+		return result
+	})
+}
+
+// snapshot descreve o que resta da biblioteca depois de uma remoção
+func snapshot(t *testing.T, b backend) []string {
+	t.Helper()
+
+	quotes, quoteTotal, err := b.quotes.List(repository.QuoteQuery{Order: repository.QuoteOrderLocation})
+	if err != nil {
+		t.Fatal(err)
+	}
+	books, bookTotal, err := b.books.GetAll(100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authors, authorTotal, err := b.authors.GetAll(100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	categories, categoryTotal, err := b.categories.GetAll(100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, tagTotal, err := b.tags.GetAll(100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var state []string
+	for _, quote := range quotes.Items {
+		state = append(state, quoteDetails(quote))
+	}
+	state = append(state, fmt.Sprintf("quotes=%d books=%d %v", quoteTotal, bookTotal, bookIDs(books)))
+	state = append(state, fmt.Sprintf("authors=%d categories=%d tags=%d", authorTotal, categoryTotal, tagTotal))
+	for _, author := range authors {
+		state = append(state, "author "+author.Name)
+	}
+	for _, category := range categories {
+		state = append(state, "category "+category.Name)
+	}
+	for _, tag := range tags {
+		state = append(state, fmt.Sprintf("tag %s quotes=%d", tag.Name, tag.QuoteCount))
+	}
+	return state
+}
+
+func TestParity_DeleteCascade(t *testing.T) {
+	checkParity(t, func(t *testing.T, b backend) map[string][]string {
+		result := make(map[string][]string)
+		step := func(name string, err error) {
+			result[name] = append([]string{"error: " + errorCode(err)}, snapshot(t, b)...)
+		}
+
+		// autor e categoria com livros não podem ser removidos
+		step("author with books", b.authors.Delete(2))
+		step("category with books", b.categories.Delete(1))
+
+		// o livro leva as suas citações e os vínculos delas com as tags
+		step("book", b.books.Delete(1))
+		step("book again", b.books.Delete(1))
+
+		// sem livros, o autor e a categoria podem sair
+		step("author", b.authors.Delete(1))
+		step("category", b.categories.Delete(3))
+
+		step("quote", b.quotes.Delete(7))
+		step("tag", b.tags.Delete(2))
+		step("tag from quote", b.tags.RemoveFromQuote(8, 1))
+		step("missing tag on quote", b.tags.RemoveFromQuote(8, 1))
+
+		// livro de dois autores: remover um autor ainda esbarra no livro
+		step("author of shared book", b.authors.Delete(4))
+		return result
+	})
+}
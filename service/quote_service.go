@@ -1,18 +1,18 @@
 package service
 
 import (
-	"errors"
+	"quote-api/apperrors"
 	"quote-api/models"
 	"quote-api/repository"
 	"strings"
 )
 
 type QuoteService struct {
-	repo     *repository.QuoteRepository
-	bookRepo *repository.BookRepository
+	repo     repository.Quotes
+	bookRepo repository.Books
 }
 
-func NewQuoteService(repo *repository.QuoteRepository, bookRepo *repository.BookRepository) *QuoteService {
+func NewQuoteService(repo repository.Quotes, bookRepo repository.Books) *QuoteService {
 	return &QuoteService{
 		repo:     repo,
 		bookRepo: bookRepo,
@@ -43,7 +43,7 @@ func (s *QuoteService) GetAll(limit, offset int) ([]models.Quote, int, error) {
 
 func (s *QuoteService) GetByID(id int64) (*models.Quote, error) {
 	if id <= 0 {
-		return nil, errors.New("ID inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	quote, err := s.repo.FindByID(id)
@@ -51,7 +51,7 @@ func (s *QuoteService) GetByID(id int64) (*models.Quote, error) {
 		return nil, err
 	}
 	if quote == nil {
-		return nil, errors.New("citação não encontrada")
+		return nil, apperrors.NotFound(apperrors.CodeQuoteNotFound)
 	}
 
 	return quote, nil
@@ -59,7 +59,7 @@ func (s *QuoteService) GetByID(id int64) (*models.Quote, error) {
 
 func (s *QuoteService) GetByBookID(bookID int64, limit, offset int) ([]models.Quote, int, error) {
 	if bookID <= 0 {
-		return nil, 0, errors.New("ID de livro inválido")
+		return nil, 0, apperrors.Validation(apperrors.CodeInvalidBookID)
 	}
 
 	book, err := s.bookRepo.FindByID(bookID)
@@ -67,7 +67,7 @@ func (s *QuoteService) GetByBookID(bookID int64, limit, offset int) ([]models.Qu
 		return nil, 0, err
 	}
 	if book == nil {
-		return nil, 0, errors.New("livro não encontrado")
+		return nil, 0, apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	if limit <= 0 || limit > 100 {
@@ -92,7 +92,7 @@ func (s *QuoteService) GetByBookID(bookID int64, limit, offset int) ([]models.Qu
 
 func (s *QuoteService) GetByAuthorID(authorID int64, limit, offset int) ([]models.Quote, int, error) {
 	if authorID <= 0 {
-		return nil, 0, errors.New("ID de autor inválido")
+		return nil, 0, apperrors.Validation(apperrors.CodeInvalidAuthorID)
 	}
 
 	if limit <= 0 || limit > 100 {
@@ -117,7 +117,7 @@ func (s *QuoteService) GetByAuthorID(authorID int64, limit, offset int) ([]model
 
 func (s *QuoteService) GetByCategoryID(categoryID int, limit, offset int) ([]models.Quote, int, error) {
 	if categoryID <= 0 {
-		return nil, 0, errors.New("ID de categoria inválido")
+		return nil, 0, apperrors.Validation(apperrors.CodeInvalidCategoryID)
 	}
 
 	// Validação de paginação
@@ -141,38 +141,69 @@ func (s *QuoteService) GetByCategoryID(categoryID int, limit, offset int) ([]mod
 	return quotes, total, nil
 }
 
-func (s *QuoteService) GetByFilters(bookID *int64, authorID *int64, categoryID *int, limit, offset int) ([]models.Quote, int, error) {
+// List lista uma página das citações que atendem a query, com o total sem
+// paginação. Com query.Cursor, Offset é ignorado
+func (s *QuoteService) List(query repository.QuoteQuery) (repository.Page[models.Quote], int, error) {
+	if err := validateQuoteQuery(query); err != nil {
+		return repository.Page[models.Quote]{}, 0, err
+	}
 
-	if limit <= 0 || limit > 100 {
-		limit = 100
+	if query.Limit <= 0 || query.Limit > 100 {
+		query.Limit = 100
 	}
-	if offset < 0 {
-		offset = 0
+	if query.Offset < 0 || query.Cursor != nil {
+		query.Offset = 0
 	}
+	query.Text = strings.TrimSpace(query.Text)
 
-	quotes, err := s.repo.FindByFilters(bookID, authorID, categoryID, limit, offset)
+	page, err := s.repo.FindPageByQuery(query)
 	if err != nil {
-		return nil, 0, err
+		return repository.Page[models.Quote]{}, 0, err
 	}
 
-	total, err := s.repo.Count()
+	total, err := s.repo.CountByQuery(query)
 	if err != nil {
-		return nil, 0, err
+		return repository.Page[models.Quote]{}, 0, err
 	}
 
-	return quotes, total, nil
+	return page, total, nil
 }
 
-func (s *QuoteService) GetRandom() (*models.Quote, error) {
-	quote, err := s.repo.FindRandom()
+// maxRandomCount limita quantas citações GetRandom sorteia de uma vez
+const maxRandomCount = 100
+
+// GetRandom sorteia count citações distintas entre as que atendem aos filtros
+// de query; weighting vazio sorteia de maneira uniforme. Retorna menos de count
+// se não houver citações suficientes
+func (s *QuoteService) GetRandom(query repository.QuoteQuery, count int, weighting repository.RandomWeighting) ([]models.Quote, error) {
+	if weighting == "" {
+		weighting = repository.RandomUniform
+	}
+
+	if err := validateQuoteQuery(query); err != nil {
+		return nil, err
+	}
+
+	var fields apperrors.Fields
+	if !weighting.Valid() {
+		fields.Add("mode", apperrors.CodeInvalid)
+	}
+	if count < 1 || count > maxRandomCount {
+		fields.Add("count", apperrors.CodeOutOfRange)
+	}
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+
+	quotes, err := s.repo.FindRandomByQuery(query, count, weighting)
 	if err != nil {
 		return nil, err
 	}
-	if quote == nil {
-		return nil, errors.New("nenhuma citação disponível")
+	if len(quotes) == 0 {
+		return nil, apperrors.NotFound(apperrors.CodeNoQuotes)
 	}
 
-	return quote, nil
+	return quotes, nil
 }
 
 func (s *QuoteService) Create(quote models.Quote) (*models.Quote, error) {
@@ -188,7 +219,7 @@ func (s *QuoteService) Create(quote models.Quote) (*models.Quote, error) {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.New("livro não encontrado")
+		return nil, apperrors.NotFound(apperrors.CodeBookNotFound)
 	}
 
 	created, err := s.repo.Create(quote)
@@ -201,7 +232,7 @@ func (s *QuoteService) Create(quote models.Quote) (*models.Quote, error) {
 
 func (s *QuoteService) Update(id int64, quote models.Quote) (*models.Quote, error) {
 	if id <= 0 {
-		return nil, errors.New("ID inválido")
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	if err := s.validateQuote(quote); err != nil {
@@ -215,7 +246,7 @@ func (s *QuoteService) Update(id int64, quote models.Quote) (*models.Quote, erro
 		return nil, err
 	}
 	if existing == nil {
-		return nil, errors.New("citação não encontrada")
+		return nil, apperrors.NotFound(apperrors.CodeQuoteNotFound)
 	}
 
 	updated, err := s.repo.Update(id, quote)
@@ -226,9 +257,51 @@ func (s *QuoteService) Update(id int64, quote models.Quote) (*models.Quote, erro
 	return updated, nil
 }
 
+// SetFavorite marca (favorite true) ou desmarca a citação como favorita
+func (s *QuoteService) SetFavorite(id int64, favorite bool) (*models.Quote, error) {
+	return s.change(id, func() error { return s.repo.SetFavorite(id, favorite) })
+}
+
+// SetRating avalia a citação com MinQuoteRating a MaxQuoteRating estrelas
+func (s *QuoteService) SetRating(id int64, rating int) (*models.Quote, error) {
+	if rating < models.MinQuoteRating || rating > models.MaxQuoteRating {
+		var fields apperrors.Fields
+		fields.Add("rating", apperrors.CodeOutOfRange)
+		return nil, fields.Err()
+	}
+
+	return s.change(id, func() error { return s.repo.SetRating(id, &rating) })
+}
+
+// ClearRating remove a avaliação da citação
+func (s *QuoteService) ClearRating(id int64) (*models.Quote, error) {
+	return s.change(id, func() error { return s.repo.SetRating(id, nil) })
+}
+
+// change valida id, aplica fn à citação existente e retorna a citação atualizada
+func (s *QuoteService) change(id int64, fn func() error) (*models.Quote, error) {
+	if id <= 0 {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
+	}
+
+	exists, err := s.repo.Exists(id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apperrors.NotFound(apperrors.CodeQuoteNotFound)
+	}
+
+	if err := fn(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(id)
+}
+
 func (s *QuoteService) Delete(id int64) error {
 	if id <= 0 {
-		return errors.New("ID inválido")
+		return apperrors.Validation(apperrors.CodeInvalidID)
 	}
 
 	exists, err := s.repo.Exists(id)
@@ -236,9 +309,9 @@ func (s *QuoteService) Delete(id int64) error {
 		return err
 	}
 	if !exists {
-		return errors.New("citação não encontrada")
+		return apperrors.NotFound(apperrors.CodeQuoteNotFound)
 	}
-	
+
 	err = s.repo.Delete(id)
 	if err != nil {
 		return err
@@ -248,8 +321,35 @@ func (s *QuoteService) Delete(id int64) error {
 }
 
 func (s *QuoteService) Search(searchTerm string, limit, offset int) ([]models.Quote, int, error) {
-	if strings.TrimSpace(searchTerm) == "" {
-		return s.GetAll(limit, offset)
+	page, total, err := s.List(repository.QuoteQuery{Text: searchTerm, TextScope: repository.SearchScopeText, Limit: limit, Offset: offset})
+	return page.Items, total, err
+}
+
+func (s *QuoteService) SearchInBookAndAuthor(searchTerm string, limit, offset int) ([]models.Quote, int, error) {
+	page, total, err := s.List(repository.QuoteQuery{Text: searchTerm, TextScope: repository.SearchScopeAll, Limit: limit, Offset: offset})
+	return page.Items, total, err
+}
+
+// FullTextSearch faz a busca ranqueada de citações. scope aceita "all"
+// (padrão: texto, título e autores) ou "text"
+func (s *QuoteService) FullTextSearch(term, scope string, limit, offset int) ([]models.QuoteSearchResult, int, error) {
+	var fields apperrors.Fields
+
+	if strings.TrimSpace(term) == "" {
+		fields.Add("q", apperrors.CodeRequired)
+	}
+
+	searchScope := repository.SearchScope(scope)
+	switch searchScope {
+	case "":
+		searchScope = repository.SearchScopeAll
+	case repository.SearchScopeAll, repository.SearchScopeText:
+	default:
+		fields.Add("in", apperrors.CodeInvalid)
+	}
+
+	if err := fields.Err(); err != nil {
+		return nil, 0, err
 	}
 
 	if limit <= 0 || limit > 100 {
@@ -259,57 +359,104 @@ func (s *QuoteService) Search(searchTerm string, limit, offset int) ([]models.Qu
 		offset = 0
 	}
 
-	quotes, err := s.repo.Search(searchTerm, limit, offset)
-	if err != nil {
-		return nil, 0, err
+	return s.repo.SearchFullText(term, searchScope, limit, offset)
+}
+
+func (s *QuoteService) validateQuote(quote models.Quote) error {
+	var fields apperrors.Fields
+
+	if quote.BookID <= 0 {
+		fields.Add("book_id", apperrors.CodeInvalid)
 	}
 
-	total, err := s.repo.Count()
-	if err != nil {
-		return nil, 0, err
+	switch {
+	case strings.TrimSpace(quote.Text) == "":
+		fields.Add("text", apperrors.CodeRequired)
+	case len(quote.Text) < 3:
+		fields.AddLimit("text", apperrors.CodeTooShort, 3)
+	case len(quote.Text) > 5000:
+		fields.AddLimit("text", apperrors.CodeTooLong, 5000)
 	}
 
-	return quotes, total, nil
-}
+	switch quote.Kind {
+	case "", models.QuoteKindHighlight, models.QuoteKindNote, models.QuoteKindBookmark:
+	default:
+		fields.Add("kind", apperrors.CodeInvalid)
+	}
 
-func (s *QuoteService) SearchInBookAndAuthor(searchTerm string, limit, offset int) ([]models.Quote, int, error) {
-	if strings.TrimSpace(searchTerm) == "" {
-		return s.GetAll(limit, offset)
+	if quote.Page != nil && *quote.Page < 0 {
+		fields.Add("page", apperrors.CodeNegative)
 	}
 
-	if limit <= 0 || limit > 100 {
-		limit = 100
+	if quote.LocationStart != nil && *quote.LocationStart < 0 {
+		fields.Add("location_start", apperrors.CodeNegative)
 	}
-	if offset < 0 {
-		offset = 0
+
+	if quote.LocationStart != nil && quote.LocationEnd != nil && *quote.LocationEnd < *quote.LocationStart {
+		fields.Add("location_end", apperrors.CodeOutOfRange)
 	}
 
-	quotes, err := s.repo.SearchInBookAndAuthor(searchTerm, limit, offset)
-	if err != nil {
-		return nil, 0, err
+	return fields.Err()
+}
+
+func validateQuoteQuery(query repository.QuoteQuery) error {
+	if query.Order != "" && !query.Order.Valid() {
+		return apperrors.Validation(apperrors.CodeInvalidOrder)
+	}
+	if query.Cursor != nil && query.Order == repository.QuoteOrderRandom {
+		return repository.ErrInvalidCursor
 	}
 
-	total := len(quotes)
+	var fields apperrors.Fields
 
-	return quotes, total, nil
-}
+	if !positiveIDs(query.BookIDs) {
+		fields.Add("book_id", apperrors.CodeInvalid)
+	}
+	if !positiveIDs(query.AuthorIDs) {
+		fields.Add("author_id", apperrors.CodeInvalid)
+	}
+	if !positiveIDs(query.CategoryIDs) {
+		fields.Add("category_id", apperrors.CodeInvalid)
+	}
+	if !positiveIDs(query.TagIDs) {
+		fields.Add("tag_id", apperrors.CodeInvalid)
+	}
 
-func (s *QuoteService) validateQuote(quote models.Quote) error {
-	if quote.BookID <= 0 {
-		return errors.New("ID de livro inválido")
+	switch query.TextScope {
+	case "", repository.SearchScopeAll, repository.SearchScopeText:
+	default:
+		fields.Add("search_in", apperrors.CodeInvalid)
+	}
+
+	if query.CreatedFrom != nil && query.CreatedTo != nil && !query.CreatedTo.After(*query.CreatedFrom) {
+		fields.Add("created_to", apperrors.CodeOutOfRange)
 	}
 
-	if strings.TrimSpace(quote.Text) == "" {
-		return errors.New("texto da citação é obrigatório")
+	if query.MinLength != nil && *query.MinLength < 0 {
+		fields.Add("min_length", apperrors.CodeNegative)
+	}
+	if query.MaxLength != nil && *query.MaxLength < 0 {
+		fields.Add("max_length", apperrors.CodeNegative)
+	} else if query.MinLength != nil && query.MaxLength != nil && *query.MaxLength < *query.MinLength {
+		fields.Add("max_length", apperrors.CodeOutOfRange)
 	}
 
-	if len(quote.Text) < 3 {
-		return errors.New("texto da citação deve ter pelo menos 3 caracteres")
+	if query.PublishedFrom != nil && query.PublishedTo != nil && *query.PublishedTo < *query.PublishedFrom {
+		fields.Add("published_to", apperrors.CodeOutOfRange)
 	}
 
-	if len(quote.Text) > 5000 {
-		return errors.New("texto da citação deve ter no máximo 5000 caracteres")
+	if query.MinRating != nil && (*query.MinRating < models.MinQuoteRating || *query.MinRating > models.MaxQuoteRating) {
+		fields.Add("min_rating", apperrors.CodeOutOfRange)
 	}
 
-	return nil
+	return fields.Err()
+}
+
+func positiveIDs[T int | int64](ids []T) bool {
+	for _, id := range ids {
+		if id <= 0 {
+			return false
+		}
+	}
+	return true
 }
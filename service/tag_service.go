@@ -0,0 +1,227 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"quote-api/apperrors"
+	"quote-api/models"
+	"quote-api/repository"
+	"strings"
+)
+
+type TagService struct {
+	repo      repository.Tags
+	quoteRepo repository.Quotes
+}
+
+func NewTagService(repo repository.Tags, quoteRepo repository.Quotes) *TagService {
+	return &TagService{repo: repo, quoteRepo: quoteRepo}
+}
+
+func (s *TagService) GetAll(limit, offset int) ([]models.Tag, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	tags, err := s.repo.FindAll(limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tags, total, nil
+}
+
+func (s *TagService) GetByID(id int64) (*models.Tag, error) {
+	if id <= 0 {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
+	}
+
+	tag, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, apperrors.NotFound(apperrors.CodeTagNotFound)
+	}
+
+	return tag, nil
+}
+
+func (s *TagService) Create(tag models.Tag) (*models.Tag, error) {
+	if err := s.validateTag(tag); err != nil {
+		return nil, err
+	}
+
+	tag.Name = strings.TrimSpace(tag.Name)
+
+	existing, err := s.repo.FindByName(tag.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperrors.Conflict(apperrors.CodeTagNameTaken)
+	}
+
+	return s.repo.Create(tag)
+}
+
+func (s *TagService) Update(id int64, tag models.Tag) (*models.Tag, error) {
+	if id <= 0 {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
+	}
+
+	if err := s.validateTag(tag); err != nil {
+		return nil, err
+	}
+
+	tag.Name = strings.TrimSpace(tag.Name)
+
+	existing, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, apperrors.NotFound(apperrors.CodeTagNotFound)
+	}
+
+	duplicate, err := s.repo.FindByName(tag.Name)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate != nil && duplicate.ID != id {
+		return nil, apperrors.Conflict(apperrors.CodeTagNameTaken)
+	}
+
+	return s.repo.Update(id, tag)
+}
+
+// Delete remove a tag e a tira de todas as citações
+func (s *TagService) Delete(id int64) error {
+	if id <= 0 {
+		return apperrors.Validation(apperrors.CodeInvalidID)
+	}
+
+	tag, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if tag == nil {
+		return apperrors.NotFound(apperrors.CodeTagNotFound)
+	}
+
+	return s.repo.Delete(id)
+}
+
+// Search lista as tags com a quantidade de citações de cada uma; com
+// searchTerm, filtra pelo nome
+func (s *TagService) Search(searchTerm string, limit, offset int) ([]models.Tag, int, error) {
+	if strings.TrimSpace(searchTerm) == "" {
+		return s.GetAll(limit, offset)
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	tags, err := s.repo.Search(searchTerm, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountSearch(searchTerm)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tags, total, nil
+}
+
+// AddToQuote aplica à citação a tag com o nome informado, criando-a se ainda
+// não existir. Nomes que diferem só em maiúsculas são a mesma tag
+func (s *TagService) AddToQuote(quoteID int64, name string) (*models.Tag, error) {
+	if quoteID <= 0 {
+		return nil, apperrors.Validation(apperrors.CodeInvalidID)
+	}
+
+	tag := models.Tag{Name: name}
+	if err := s.validateTag(tag); err != nil {
+		return nil, err
+	}
+	tag.Name = strings.TrimSpace(tag.Name)
+
+	exists, err := s.quoteRepo.Exists(quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apperrors.NotFound(apperrors.CodeQuoteNotFound)
+	}
+
+	existing, err := s.repo.FindByName(tag.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		if existing, err = s.repo.Create(tag); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.AddToQuote(quoteID, existing.ID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(existing.ID)
+}
+
+// RemoveFromQuote tira a tag da citação; a tag continua existindo
+func (s *TagService) RemoveFromQuote(quoteID, tagID int64) error {
+	if quoteID <= 0 {
+		return apperrors.Validation(apperrors.CodeInvalidID)
+	}
+	if tagID <= 0 {
+		return apperrors.Validation(apperrors.CodeInvalidTagID)
+	}
+
+	exists, err := s.quoteRepo.Exists(quoteID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apperrors.NotFound(apperrors.CodeQuoteNotFound)
+	}
+
+	err = s.repo.RemoveFromQuote(quoteID, tagID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.NotFound(apperrors.CodeQuoteTagNotFound)
+	}
+	return err
+}
+
+// validateTag recusa vírgulas no nome, que separam as tags no filtro ?tag=
+// das listagens de citações
+func (s *TagService) validateTag(tag models.Tag) error {
+	var fields apperrors.Fields
+
+	switch name := strings.TrimSpace(tag.Name); {
+	case name == "":
+		fields.Add("name", apperrors.CodeRequired)
+	case len(name) > 50:
+		fields.AddLimit("name", apperrors.CodeTooLong, 50)
+	case strings.Contains(name, ","):
+		fields.Add("name", apperrors.CodeInvalid)
+	}
+
+	return fields.Err()
+}